@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"explorer-server/database"
+	"explorer-server/repository"
+	"explorer-server/services"
+
+	"github.com/joho/godotenv"
+)
+
+// runAuditDIDs handles "explorer audit-dids [-repair]" and returns the exit code:
+// 0 when the totals agree (or were repaired), 1 on errors, 3 when mismatches remain
+func runAuditDIDs(args []string) int {
+	fs := flag.NewFlagSet("audit-dids", flag.ContinueOnError)
+	repair := fs.Bool("repair", false, "recompute the DIDs whose totals disagree with the token tables")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	_ = godotenv.Load()
+	database.Connect()
+	defer database.CloseDB()
+	services.SetRepositories(repository.NewPostgres(database.DB))
+
+	report, err := services.AuditDIDTotals(*repair)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+
+	for _, m := range report.Mismatches {
+		stored := "missing"
+		if m.Stored != nil {
+			stored = fmt.Sprintf("rbts=%s fts=%s nfts=%d sc=%d",
+				m.Stored.TotalRBTs, m.Stored.TotalFTs, m.Stored.TotalNFTs, m.Stored.TotalSC)
+		}
+		fmt.Printf("%-60s %-14s stored %s, derived rbts=%s fts=%s nfts=%d sc=%d\n",
+			m.DID, strings.Join(m.Fields, ","), stored,
+			m.Derived.TotalRBTs, m.Derived.TotalFTs, m.Derived.TotalNFTs, m.Derived.TotalSC)
+	}
+	fmt.Printf("Checked %d DID(s), %d mismatch(es)", report.Checked, len(report.Mismatches))
+	if report.Repaired {
+		fmt.Print(", repaired")
+	}
+	fmt.Println()
+
+	if len(report.Mismatches) > 0 && !report.Repaired {
+		return 3
+	}
+	return 0
+}
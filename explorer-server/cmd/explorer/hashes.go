@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"explorer-server/database"
+	"explorer-server/repository"
+	"explorer-server/services"
+	"explorer-server/util"
+
+	"github.com/joho/godotenv"
+)
+
+// runCheckHashes handles "explorer check-hashes [-token ID]" and returns the exit code:
+// 0 when no mismatch is found, 1 on errors, 3 when blocks have a hash mismatch.
+// With -token it recomputes the hashes of that token's chain, otherwise it lists the
+// stored blocks whose recomputed hash did not match. The hash check is kept to this
+// command until the recomputation is confirmed against blocks from a real fullnode.
+func runCheckHashes(args []string) int {
+	fs := flag.NewFlagSet("check-hashes", flag.ContinueOnError)
+	tokenID := fs.String("token", "", "recompute the block hashes of this token's chain")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	_ = godotenv.Load()
+	database.Connect()
+	defer database.CloseDB()
+	services.SetRepositories(repository.NewPostgres(database.DB))
+
+	mismatches := 0
+	if *tokenID != "" {
+		results, err := services.VerifyTokenChainHashes(*tokenID)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		for _, res := range results {
+			fmt.Printf("%4d %-12s %s computed %s %s\n",
+				res.Index, res.Status, res.ExpectedHash, res.ComputedHash, res.Reason)
+			if res.Status == util.HashMismatch {
+				mismatches++
+			}
+		}
+		fmt.Printf("Checked %d block(s) of %s, %d mismatch(es)\n", len(results), *tokenID, mismatches)
+	} else {
+		for page := 1; ; page++ {
+			rows, total, err := services.ListHashMismatches(100, page)
+			if err != nil {
+				log.Printf("❌ %v", err)
+				return 1
+			}
+			for _, v := range rows {
+				fmt.Printf("%s computed %s\n", v.BlockHash, v.ComputedHash)
+			}
+			mismatches += len(rows)
+			if len(rows) == 0 || int64(mismatches) >= total {
+				break
+			}
+		}
+		fmt.Printf("%d stored block(s) with a hash mismatch\n", mismatches)
+	}
+
+	if mismatches > 0 {
+		return 3
+	}
+	return 0
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"explorer-server/database"
+	"explorer-server/repository"
 	"explorer-server/router"
 	"explorer-server/services"
 
@@ -19,6 +20,19 @@ import (
 )
 
 func main() {
+	// Schema migrations: explorer migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	// DID totals audit: explorer audit-dids [-repair]
+	if len(os.Args) > 1 && os.Args[1] == "audit-dids" {
+		os.Exit(runAuditDIDs(os.Args[2:]))
+	}
+	// Block hash check: explorer check-hashes [-token ID]
+	if len(os.Args) > 1 && os.Args[1] == "check-hashes" {
+		os.Exit(runCheckHashes(os.Args[2:]))
+	}
+
 	startTime := time.Now()
 
 	// Detect CPU cores
@@ -47,6 +61,7 @@ func main() {
 	log.Println("Connecting to PostgreSQL...")
 	database.ConnectAndMigrate(false)
 	log.Println("PostgreSQL connected and migrated")
+	services.SetRepositories(repository.NewPostgres(database.DB))
 
 	// --------------------------------------------------
 	// Initialize worker pools (block / token / sync)
@@ -54,6 +69,27 @@ func main() {
 	services.InitWorkerPools(totalCores)
 	log.Println("✅ Worker pools initialized")
 
+	// Process pushed block/token updates from the durable ingest inbox
+	services.StartIngestInbox(2 * time.Second)
+
+	// Probe fullnode upstreams so failing nodes drop out of rotation
+	services.StartNodeHealthChecks(30 * time.Second)
+
+	// Retry token syncs parked in the dead-letter queue with backoff
+	services.StartFailedSyncRetryLoop(time.Minute)
+
+	// Fill QuorumPledges from transfer blocks stored before pledges were normalized
+	go services.BackfillQuorumPledges()
+
+	// Link PART tokens to the RBTs they were split from for blocks stored before lineage was tracked
+	go services.BackfillTokenLineage()
+
+	// Record who held which token for mint, transfer and burn blocks stored before ownership history was tracked
+	go services.BackfillOwnershipHistory()
+
+	// Record the value moved by transfer, mint, burnt and pledge blocks stored before the ledger was kept
+	go services.BackfillLedger()
+
 	// --------------------------------------------------
 	// Start continuous background sync (Option C)
 	// --------------------------------------------------
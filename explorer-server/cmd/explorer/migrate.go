@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"explorer-server/database"
+
+	"github.com/joho/godotenv"
+)
+
+const migrateUsage = `usage: explorer migrate <command>
+
+commands:
+  up           apply every pending migration
+  down [N]     revert the last N applied migrations (default 1, "all" for every one)
+  status       list migrations and when they were applied`
+
+// runMigrate handles "explorer migrate up|down|status" and returns the exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	_ = godotenv.Load()
+	database.Connect()
+	defer database.CloseDB()
+
+	switch args[0] {
+	case "up":
+		n, err := database.MigrateUp()
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		fmt.Printf("Applied %d migration(s)\n", n)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = 0
+			} else if n, err := strconv.Atoi(args[1]); err == nil && n > 0 {
+				steps = n
+			} else {
+				fmt.Fprintf(os.Stderr, "invalid step count %q\n%s\n", args[1], migrateUsage)
+				return 2
+			}
+		}
+		n, err := database.MigrateDown(steps)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		fmt.Printf("Reverted %d migration(s)\n", n)
+
+	case "status":
+		status, err := database.GetMigrationStatus()
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-30s %s\n", s.Version, s.Name, applied)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n%s\n", args[0], migrateUsage)
+		return 2
+	}
+	return 0
+}
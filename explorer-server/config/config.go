@@ -1,6 +1,38 @@
 package config
 
+import (
+	"os"
+	"strings"
+)
+
 const (
 	ExplorerPort = "8082"
 	RubixNodeURL = "http://172.206.26.49"
+
+	// RubixNodeHealthPath is probed on every fullnode upstream; any non-5xx answer counts as healthy
+	RubixNodeHealthPath = "/"
 )
+
+// RubixNodeURLs returns the fullnode upstreams from RUBIX_NODE_URLS (comma separated),
+// falling back to RubixNodeURL when unset.
+func RubixNodeURLs() []string {
+	var urls []string
+	for _, u := range strings.Split(os.Getenv("RUBIX_NODE_URLS"), ",") {
+		u = strings.TrimRight(strings.TrimSpace(u), "/")
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+
+	if len(urls) == 0 {
+		urls = []string{RubixNodeURL}
+	}
+	return urls
+}
+
+// VerifyAcrossNodes reports whether token-chain sync should cross-check every chain
+// against all healthy fullnodes before storing it (SYNC_VERIFY_NODES=true).
+func VerifyAcrossNodes() bool {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv("SYNC_VERIFY_NODES")))
+	return v == "true" || v == "1"
+}
@@ -6,8 +6,6 @@ import (
 	"os"
 	"time"
 
-	"explorer-server/database/models"
-
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -15,8 +13,8 @@ import (
 
 var DB *gorm.DB
 
-// ConnectAndMigrate initializes PostgreSQL with GORM and auto-migrates tables
-func ConnectAndMigrate(drop bool) {
+// Connect opens the PostgreSQL connection pool used by DB
+func Connect() {
 	// Build DSN
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
@@ -47,42 +45,28 @@ func ConnectAndMigrate(drop bool) {
 	sqlDB.SetConnMaxLifetime(5 * time.Minute)
 
 	log.Println("✅ Connected to PostgreSQL successfully")
+}
+
+// ConnectAndMigrate connects to PostgreSQL and applies pending schema migrations.
+// drop reverts every migration first, dropping all tables.
+func ConnectAndMigrate(drop bool) {
+	Connect()
 
 	// Drop tables if requested
 	if drop {
 		log.Println("⚠️ Dropping existing tables...")
-		dropTables()
+		if _, err := MigrateDown(0); err != nil {
+			log.Fatalf("❌ Failed to drop tables: %v", err)
+		}
 		log.Println("✅ Tables dropped successfully")
 	}
 
-	// Auto-migrate tables
-	err = DB.AutoMigrate(
-		&models.RBT{},
-		&models.FT{},
-		&models.NFT{},
-		&models.SmartContract{},
-		&models.DIDs{},
-		&models.TokenType{},
-		&models.AllBlocks{},
-		&models.TransferBlocks{},
-		&models.TxnAnalytics{},
-		&models.BurntBlocks{}, 
-		&models.SC_Block{},
-	)
+	applied, err := MigrateUp()
 	if err != nil {
 		log.Fatalf("❌ Failed to migrate tables: %v", err)
 	}
 
-	log.Println("✅ Tables auto-migrated successfully")
-}
-
-// dropTables drops only the TransferBlocks table
-func dropTables() {
-	if DB.Migrator().HasTable(&models.TransferBlocks{}) {
-		if err := DB.Migrator().DropTable(&models.TransferBlocks{}); err != nil {
-			log.Fatalf("❌ Failed to drop TransferBlocks table: %v", err)
-		}
-	}
+	log.Printf("✅ Schema up to date (%d migrations applied)", applied)
 }
 
 // getEnv fetches environment variable or returns fallback
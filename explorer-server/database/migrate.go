@@ -0,0 +1,234 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles holds the numbered migrations: NNNN_name.up.sql and NNNN_name.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrMigrationMissing is returned when a recorded schema version has no embedded migration
+var ErrMigrationMissing = errors.New("applied migration is not embedded in this binary")
+
+// Migration is one numbered schema change with the SQL to apply and revert it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaVersion records an applied migration
+type SchemaVersion struct {
+	Version   int       `json:"version" gorm:"primaryKey;column:version;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"column:name"`
+	AppliedAt time.Time `json:"applied_at" gorm:"column:applied_at"`
+}
+
+func (SchemaVersion) TableName() string { return "schema_version" }
+
+// MigrationStatus is the state of one embedded migration
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// Migrations loads the embedded migrations in version order
+func Migrations() ([]Migration, error) {
+	files, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, f := range files {
+		m := migrationFileName.FindStringSubmatch(f.Name())
+		if m == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", f.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+
+		sql, err := migrationFiles.ReadFile(path.Join("migrations", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(sql)
+		} else {
+			mig.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// appliedVersions creates schema_version when missing and returns the applied migrations
+func appliedVersions() (map[int]SchemaVersion, error) {
+	if err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []SchemaVersion
+	if err := DB.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]SchemaVersion, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// MigrateUp applies every pending migration in order, each in its own transaction.
+// It returns the number of migrations applied.
+func MigrateUp() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(mig.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %04d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		log.Printf("⬆️ Applied migration %04d_%s", mig.Version, mig.Name)
+		count++
+	}
+	return count, nil
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first.
+// steps <= 0 reverts all of them. It returns the number of migrations reverted.
+func MigrateDown(steps int) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, mig := range migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if steps > 0 && steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	count := 0
+	for _, v := range versions {
+		mig, ok := byVersion[v]
+		if !ok {
+			return count, fmt.Errorf("%w: version %d", ErrMigrationMissing, v)
+		}
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(mig.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersion{}, "version = ?", v).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("reverting migration %04d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		log.Printf("⬇️ Reverted migration %04d_%s", mig.Version, mig.Name)
+		count++
+	}
+	return count, nil
+}
+
+// GetMigrationStatus lists every embedded migration and when it was applied
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, mig := range migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if v, ok := applied[mig.Version]; ok {
+			at := v.AppliedAt
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	for v, row := range applied {
+		if _, ok := findMigration(migrations, v); !ok {
+			at := row.AppliedAt
+			status = append(status, MigrationStatus{Version: v, Name: row.Name + " (not embedded)", AppliedAt: &at})
+		}
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+func findMigration(migrations []Migration, version int) (Migration, bool) {
+	for _, mig := range migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS "BlockVerifications";
+DROP TABLE IF EXISTS "DIDKeys";
+DROP TABLE IF EXISTS "TokenLineage";
+DROP TABLE IF EXISTS "QuorumPledges";
+DROP TABLE IF EXISTS "TokenChainBlocks";
+DROP TABLE IF EXISTS "IngestInbox";
+DROP TABLE IF EXISTS "FailedTokenSyncs";
+DROP TABLE IF EXISTS "ChainDivergences";
+DROP TABLE IF EXISTS "TokenSyncCheckpoints";
+DROP TABLE IF EXISTS "PinBlocks";
+DROP TABLE IF EXISTS "CommitBlocks";
+DROP TABLE IF EXISTS "PledgeBlocks";
+DROP TABLE IF EXISTS "MintBlocks";
+DROP TABLE IF EXISTS "SC_Blocks";
+DROP TABLE IF EXISTS "BurntBlocks";
+DROP TABLE IF EXISTS "TransferBlocks";
+DROP TABLE IF EXISTS "AllBlocks";
+DROP TABLE IF EXISTS "TxnAnalytics";
+DROP TABLE IF EXISTS "DIDs";
+DROP TABLE IF EXISTS "TokenType";
+DROP TABLE IF EXISTS "SmartContract";
+DROP TABLE IF EXISTS "NFT";
+DROP TABLE IF EXISTS "FT";
+DROP TABLE IF EXISTS "RBT";
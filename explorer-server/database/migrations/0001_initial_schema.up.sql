@@ -0,0 +1,322 @@
+-- Initial schema: every table as created by the models before versioned migrations,
+-- plus the primary keys the token tables were always meant to have.
+-- Statements are idempotent so databases created by AutoMigrate or schema.sql are brought into line.
+
+-- =============================================
+-- Tokens
+-- =============================================
+CREATE TABLE IF NOT EXISTS "RBT" (
+    rbt_id TEXT PRIMARY KEY,
+    owner_did TEXT,
+    block_id TEXT,
+    block_height TEXT,
+    token_value DECIMAL,
+    token_status BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS "FT" (
+    ft_id TEXT PRIMARY KEY,
+    token_value DECIMAL,
+    ft_name TEXT,
+    owner_did TEXT,
+    creator_did TEXT,
+    block_height BIGINT,
+    block_id TEXT,
+    txn_id TEXT,
+    token_status BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS "NFT" (
+    nft_id TEXT PRIMARY KEY,
+    token_value TEXT,
+    owner_did TEXT,
+    block_hash TEXT,
+    txn_id TEXT,
+    block_height BIGINT,
+    token_status BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS "SmartContract" (
+    contract_id TEXT PRIMARY KEY,
+    block_hash TEXT,
+    deployer_did TEXT,
+    txn_id TEXT,
+    block_height BIGINT,
+    token_status BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS "TokenType" (
+    token_id TEXT PRIMARY KEY,
+    token_type TEXT,
+    last_updated TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS "DIDs" (
+    did TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    total_rbts DECIMAL,
+    total_fts DECIMAL,
+    total_nfts BIGINT,
+    total_sc BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS "TxnAnalytics" (
+    interval_start TIMESTAMPTZ,
+    interval_end TIMESTAMPTZ,
+    txn_count BIGINT,
+    total_value DECIMAL,
+    token_type TEXT
+);
+
+-- Tables created by AutoMigrate have no primary key on the token tables and may hold
+-- duplicate rows; keep the most recently written row of each token before adding the key.
+DELETE FROM "RBT" WHERE rbt_id IS NULL;
+DELETE FROM "RBT" a USING "RBT" b WHERE a.rbt_id = b.rbt_id AND a.ctid < b.ctid;
+DELETE FROM "FT" WHERE ft_id IS NULL;
+DELETE FROM "FT" a USING "FT" b WHERE a.ft_id = b.ft_id AND a.ctid < b.ctid;
+DELETE FROM "NFT" WHERE nft_id IS NULL;
+DELETE FROM "NFT" a USING "NFT" b WHERE a.nft_id = b.nft_id AND a.ctid < b.ctid;
+DELETE FROM "SmartContract" WHERE contract_id IS NULL;
+DELETE FROM "SmartContract" a USING "SmartContract" b WHERE a.contract_id = b.contract_id AND a.ctid < b.ctid;
+DELETE FROM "TokenType" WHERE token_id IS NULL;
+DELETE FROM "TokenType" a USING "TokenType" b WHERE a.token_id = b.token_id AND a.ctid < b.ctid;
+
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN SELECT * FROM (VALUES
+        ('RBT', 'rbt_id'),
+        ('FT', 'ft_id'),
+        ('NFT', 'nft_id'),
+        ('SmartContract', 'contract_id'),
+        ('TokenType', 'token_id')
+    ) AS keys(tbl, col) LOOP
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = format('%I', t.tbl)::regclass AND contype = 'p'
+        ) THEN
+            EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (%I)', t.tbl, t.col);
+        END IF;
+    END LOOP;
+END $$;
+
+-- =============================================
+-- Blocks
+-- =============================================
+CREATE TABLE IF NOT EXISTS "AllBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    block_type TEXT,
+    epoch TIMESTAMPTZ,
+    txn_id TEXT,
+    source_node TEXT
+);
+
+CREATE TABLE IF NOT EXISTS "TransferBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    prev_block_id TEXT,
+    sender_did TEXT,
+    receiver_did TEXT,
+    txn_type TEXT,
+    amount DECIMAL,
+    epoch BIGINT,
+    tokens JSONB,
+    validator_pledge_map JSONB,
+    txn_id TEXT
+);
+
+CREATE TABLE IF NOT EXISTS "BurntBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    child_tokens JSONB,
+    txn_type TEXT,
+    owner_did TEXT,
+    epoch BIGINT,
+    tokens JSONB
+);
+
+CREATE TABLE IF NOT EXISTS "SC_Blocks" (
+    block_id TEXT PRIMARY KEY,
+    contract_id TEXT,
+    executor_did TEXT,
+    block_height BIGINT,
+    epoch TIMESTAMPTZ,
+    owner_did TEXT
+);
+
+CREATE TABLE IF NOT EXISTS "MintBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    txn_type TEXT,
+    txn_id TEXT,
+    owner_did TEXT,
+    token_value DECIMAL,
+    tokens JSONB,
+    genesis_block JSONB,
+    epoch BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_mint_blocks_txn_type ON "MintBlocks" (txn_type);
+CREATE INDEX IF NOT EXISTS idx_mint_blocks_txn_id ON "MintBlocks" (txn_id);
+CREATE INDEX IF NOT EXISTS idx_mint_blocks_owner_did ON "MintBlocks" (owner_did);
+
+CREATE TABLE IF NOT EXISTS "PledgeBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    txn_type TEXT,
+    txn_id TEXT,
+    owner_did TEXT,
+    pledged_did TEXT,
+    tokens JSONB,
+    epoch BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_pledge_blocks_txn_type ON "PledgeBlocks" (txn_type);
+CREATE INDEX IF NOT EXISTS idx_pledge_blocks_txn_id ON "PledgeBlocks" (txn_id);
+CREATE INDEX IF NOT EXISTS idx_pledge_blocks_owner_did ON "PledgeBlocks" (owner_did);
+
+CREATE TABLE IF NOT EXISTS "CommitBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    txn_type TEXT,
+    txn_id TEXT,
+    owner_did TEXT,
+    committed_did TEXT,
+    tokens JSONB,
+    committed_tokens JSONB,
+    epoch BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_commit_blocks_txn_type ON "CommitBlocks" (txn_type);
+CREATE INDEX IF NOT EXISTS idx_commit_blocks_txn_id ON "CommitBlocks" (txn_id);
+CREATE INDEX IF NOT EXISTS idx_commit_blocks_owner_did ON "CommitBlocks" (owner_did);
+
+CREATE TABLE IF NOT EXISTS "PinBlocks" (
+    block_hash TEXT PRIMARY KEY,
+    txn_id TEXT,
+    owner_did TEXT,
+    comment TEXT,
+    tokens JSONB,
+    epoch BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_pin_blocks_txn_id ON "PinBlocks" (txn_id);
+CREATE INDEX IF NOT EXISTS idx_pin_blocks_owner_did ON "PinBlocks" (owner_did);
+
+-- =============================================
+-- Sync state
+-- =============================================
+CREATE TABLE IF NOT EXISTS "TokenSyncCheckpoints" (
+    token_id TEXT PRIMARY KEY,
+    token_type TEXT,
+    last_block_height BIGINT,
+    last_block_hash TEXT,
+    last_synced_at TIMESTAMPTZ,
+    last_source_node TEXT,
+    status TEXT,
+    last_error TEXT
+);
+
+CREATE TABLE IF NOT EXISTS "ChainDivergences" (
+    id BIGSERIAL PRIMARY KEY,
+    token_id TEXT,
+    token_type TEXT,
+    block_index BIGINT,
+    kind TEXT,
+    node_a TEXT,
+    node_b TEXT,
+    hash_a TEXT,
+    hash_b TEXT,
+    detail TEXT,
+    detected_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS "idx_ChainDivergences_token_id" ON "ChainDivergences" (token_id);
+
+CREATE TABLE IF NOT EXISTS "FailedTokenSyncs" (
+    token_id TEXT PRIMARY KEY,
+    token_type TEXT,
+    node_token_type BIGINT,
+    did TEXT,
+    asset_type BIGINT,
+    source TEXT,
+    status TEXT,
+    attempts BIGINT,
+    last_error TEXT,
+    next_retry_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS "idx_FailedTokenSyncs_status" ON "FailedTokenSyncs" (status);
+CREATE INDEX IF NOT EXISTS "idx_FailedTokenSyncs_next_retry_at" ON "FailedTokenSyncs" (next_retry_at);
+
+CREATE TABLE IF NOT EXISTS "IngestInbox" (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT,
+    payload JSONB,
+    status TEXT,
+    attempts BIGINT,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    locked_at TIMESTAMPTZ,
+    processed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_ingest_inbox_claim ON "IngestInbox" (kind, status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS "TokenChainBlocks" (
+    token_id TEXT,
+    block_hash TEXT,
+    block_height BIGINT,
+    txn_type TEXT,
+    block JSONB,
+    stored_at TIMESTAMPTZ,
+    PRIMARY KEY (token_id, block_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_token_chain_blocks_height ON "TokenChainBlocks" (token_id, block_height);
+
+-- =============================================
+-- Derived data
+-- =============================================
+CREATE TABLE IF NOT EXISTS "QuorumPledges" (
+    id BIGSERIAL PRIMARY KEY,
+    block_hash TEXT,
+    txn_id TEXT,
+    txn_type TEXT,
+    quorum_did TEXT,
+    pledged_token TEXT,
+    pledged_token_type BIGINT,
+    pledged_token_block_id TEXT,
+    pledged_value DECIMAL,
+    epoch BIGINT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_quorum_pledges ON "QuorumPledges" (block_hash, quorum_did, pledged_token);
+CREATE INDEX IF NOT EXISTS idx_quorum_pledges_quorum_did ON "QuorumPledges" (quorum_did);
+CREATE INDEX IF NOT EXISTS idx_quorum_pledges_epoch ON "QuorumPledges" (epoch);
+
+CREATE TABLE IF NOT EXISTS "TokenLineage" (
+    id BIGSERIAL PRIMARY KEY,
+    token_id TEXT,
+    related_token_id TEXT,
+    relation TEXT,
+    block_hash TEXT,
+    token_value DECIMAL,
+    epoch BIGINT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_token_lineage ON "TokenLineage" (token_id, related_token_id, relation);
+CREATE INDEX IF NOT EXISTS idx_token_lineage_related ON "TokenLineage" (related_token_id);
+
+CREATE TABLE IF NOT EXISTS "DIDKeys" (
+    did TEXT PRIMARY KEY,
+    public_key TEXT,
+    source TEXT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS "BlockVerifications" (
+    block_hash TEXT PRIMARY KEY,
+    txn_id TEXT,
+    status TEXT,
+    reason TEXT,
+    sender_status TEXT,
+    quorum_verified BIGINT,
+    quorum_total BIGINT,
+    signatures JSONB,
+    hash_status TEXT,
+    computed_hash TEXT,
+    verified_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_block_verifications_txn_id ON "BlockVerifications" (txn_id);
+CREATE INDEX IF NOT EXISTS idx_block_verifications_status ON "BlockVerifications" (status);
+CREATE INDEX IF NOT EXISTS idx_block_verifications_hash_status ON "BlockVerifications" (hash_status);
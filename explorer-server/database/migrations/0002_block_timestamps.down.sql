@@ -0,0 +1,26 @@
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['TransferBlocks', 'MintBlocks', 'PledgeBlocks', 'CommitBlocks', 'PinBlocks',
+                             'BurntBlocks', 'QuorumPledges', 'TokenLineage'] LOOP
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN epoch TYPE BIGINT USING extract(epoch FROM epoch)::BIGINT', t);
+    END LOOP;
+END $$;
+
+DROP INDEX IF EXISTS "idx_TransferBlocks_epoch";
+ALTER TABLE "TransferBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_MintBlocks_epoch";
+ALTER TABLE "MintBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_PledgeBlocks_epoch";
+ALTER TABLE "PledgeBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_CommitBlocks_epoch";
+ALTER TABLE "CommitBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_PinBlocks_epoch";
+ALTER TABLE "PinBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_SC_Blocks_epoch";
+ALTER TABLE "SC_Blocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_BurntBlocks_epoch";
+ALTER TABLE "BurntBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
+DROP INDEX IF EXISTS "idx_AllBlocks_epoch";
+ALTER TABLE "AllBlocks" DROP COLUMN IF EXISTS epoch_source, DROP COLUMN IF EXISTS epoch_confidence;
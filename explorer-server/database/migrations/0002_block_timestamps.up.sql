@@ -0,0 +1,84 @@
+-- Block timestamps: every epoch column becomes timestamptz (unix seconds and naive
+-- timestamps are converted, zero epochs become NULL) and block tables record where
+-- each timestamp came from and how far it can be trusted.
+
+DO $$
+DECLARE
+    t TEXT;
+    dtype TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['TransferBlocks', 'MintBlocks', 'PledgeBlocks', 'CommitBlocks', 'PinBlocks',
+                             'BurntBlocks', 'AllBlocks', 'SC_Blocks', 'QuorumPledges', 'TokenLineage'] LOOP
+        SELECT data_type INTO dtype FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = t AND column_name = 'epoch';
+
+        IF dtype IN ('bigint', 'integer', 'numeric', 'double precision') THEN
+            EXECUTE format('ALTER TABLE %I ALTER COLUMN epoch TYPE TIMESTAMPTZ
+                USING CASE WHEN epoch IS NULL OR epoch <= 0 THEN NULL ELSE to_timestamp(epoch) END', t);
+        ELSIF dtype = 'timestamp without time zone' THEN
+            EXECUTE format('ALTER TABLE %I ALTER COLUMN epoch TYPE TIMESTAMPTZ USING epoch AT TIME ZONE ''UTC''', t);
+        END IF;
+
+        EXECUTE format('UPDATE %I SET epoch = NULL WHERE epoch < TIMESTAMPTZ ''1970-01-02 00:00:00+00''', t);
+    END LOOP;
+END $$;
+
+-- Rows stored before sources were tracked get the source the old ingestion code used:
+-- burn times were scraped from the block comment assuming IST, and AllBlocks fell back
+-- to the ingestion time when a block had no epoch.
+
+ALTER TABLE "TransferBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "TransferBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'block_epoch' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'high' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_TransferBlocks_epoch" ON "TransferBlocks" (epoch);
+
+ALTER TABLE "MintBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "MintBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'block_epoch' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'high' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_MintBlocks_epoch" ON "MintBlocks" (epoch);
+
+ALTER TABLE "PledgeBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "PledgeBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'block_epoch' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'high' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_PledgeBlocks_epoch" ON "PledgeBlocks" (epoch);
+
+ALTER TABLE "CommitBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "CommitBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'block_epoch' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'high' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_CommitBlocks_epoch" ON "CommitBlocks" (epoch);
+
+ALTER TABLE "PinBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "PinBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'block_epoch' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'high' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_PinBlocks_epoch" ON "PinBlocks" (epoch);
+
+ALTER TABLE "SC_Blocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "SC_Blocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'block_epoch' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'high' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_SC_Blocks_epoch" ON "SC_Blocks" (epoch);
+
+ALTER TABLE "BurntBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "BurntBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'comment' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'medium' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_BurntBlocks_epoch" ON "BurntBlocks" (epoch);
+
+ALTER TABLE "AllBlocks" ADD COLUMN IF NOT EXISTS epoch_source TEXT, ADD COLUMN IF NOT EXISTS epoch_confidence TEXT;
+UPDATE "AllBlocks" SET
+    epoch_source = CASE WHEN epoch IS NULL THEN 'none' ELSE 'legacy' END,
+    epoch_confidence = CASE WHEN epoch IS NULL THEN 'none' ELSE 'low' END
+WHERE epoch_source IS NULL OR epoch_source = '';
+CREATE INDEX IF NOT EXISTS "idx_AllBlocks_epoch" ON "AllBlocks" (epoch);
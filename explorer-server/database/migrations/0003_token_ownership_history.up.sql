@@ -0,0 +1,17 @@
+-- Token ownership history: one row per mint, transfer or burn of a token, derived from
+-- the block tables. Existing blocks are filled in by the ownership backfill at startup.
+
+CREATE TABLE IF NOT EXISTS "TokenOwnershipHistory" (
+    id BIGSERIAL PRIMARY KEY,
+    token_id TEXT NOT NULL,
+    block_hash TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    from_did TEXT,
+    to_did TEXT,
+    txn_id TEXT,
+    epoch TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_token_ownership ON "TokenOwnershipHistory" (token_id, block_hash);
+CREATE INDEX IF NOT EXISTS idx_token_ownership_from_did ON "TokenOwnershipHistory" (from_did);
+CREATE INDEX IF NOT EXISTS idx_token_ownership_to_did ON "TokenOwnershipHistory" (to_did);
+CREATE INDEX IF NOT EXISTS idx_token_ownership_block_hash ON "TokenOwnershipHistory" (block_hash);
@@ -0,0 +1,18 @@
+-- Double-entry ledger: every movement of a token is a debit of the receiving account and a
+-- credit of the giving one. Existing blocks are filled in by the ledger backfill at startup.
+
+CREATE TABLE IF NOT EXISTS "LedgerEntries" (
+    id BIGSERIAL PRIMARY KEY,
+    block_hash TEXT NOT NULL,
+    token_id TEXT NOT NULL,
+    entry_type TEXT NOT NULL,
+    account TEXT NOT NULL,
+    asset TEXT NOT NULL,
+    debit DECIMAL NOT NULL DEFAULT 0,
+    credit DECIMAL NOT NULL DEFAULT 0,
+    txn_id TEXT,
+    epoch TIMESTAMPTZ,
+    CHECK (debit >= 0 AND credit >= 0)
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_block_hash ON "LedgerEntries" (block_hash);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON "LedgerEntries" (account);
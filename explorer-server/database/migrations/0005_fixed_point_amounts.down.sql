@@ -0,0 +1,10 @@
+ALTER TABLE "LedgerEntries" ALTER COLUMN debit TYPE DECIMAL, ALTER COLUMN credit TYPE DECIMAL;
+ALTER TABLE "TokenLineage" ALTER COLUMN token_value TYPE DECIMAL;
+ALTER TABLE "QuorumPledges" ALTER COLUMN pledged_value TYPE DECIMAL;
+ALTER TABLE "MintBlocks" ALTER COLUMN token_value TYPE DECIMAL;
+ALTER TABLE "TransferBlocks" ALTER COLUMN amount TYPE DECIMAL;
+ALTER TABLE "TxnAnalytics" ALTER COLUMN total_value TYPE DECIMAL;
+ALTER TABLE "DIDs" ALTER COLUMN total_rbts TYPE DECIMAL, ALTER COLUMN total_fts TYPE DECIMAL;
+ALTER TABLE "NFT" ALTER COLUMN token_value TYPE TEXT USING to_char(token_value, 'FM999999999990.000000');
+ALTER TABLE "FT" ALTER COLUMN token_value TYPE DECIMAL;
+ALTER TABLE "RBT" ALTER COLUMN token_value TYPE DECIMAL;
@@ -0,0 +1,26 @@
+-- Fixed-point amounts: token values, transfer amounts and totals become NUMERIC(20,8),
+-- whatever float or text type they were created with. NFT token values were stored as
+-- %f-formatted text; values that do not parse become NULL.
+
+ALTER TABLE "RBT" ALTER COLUMN token_value TYPE NUMERIC(20,8) USING ROUND(token_value::numeric, 8);
+ALTER TABLE "FT" ALTER COLUMN token_value TYPE NUMERIC(20,8) USING ROUND(token_value::numeric, 8);
+ALTER TABLE "NFT" ALTER COLUMN token_value TYPE NUMERIC(20,8) USING
+    CASE WHEN TRIM(token_value::text) ~ '^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)$'
+         THEN ROUND(TRIM(token_value::text)::numeric, 8) END;
+ALTER TABLE "DIDs"
+    ALTER COLUMN total_rbts TYPE NUMERIC(20,8) USING ROUND(total_rbts::numeric, 8),
+    ALTER COLUMN total_fts TYPE NUMERIC(20,8) USING ROUND(total_fts::numeric, 8);
+ALTER TABLE "TxnAnalytics" ALTER COLUMN total_value TYPE NUMERIC(20,8) USING ROUND(total_value::numeric, 8);
+ALTER TABLE "TransferBlocks" ALTER COLUMN amount TYPE NUMERIC(20,8) USING ROUND(amount::numeric, 8);
+ALTER TABLE "MintBlocks" ALTER COLUMN token_value TYPE NUMERIC(20,8) USING ROUND(token_value::numeric, 8);
+ALTER TABLE "QuorumPledges" ALTER COLUMN pledged_value TYPE NUMERIC(20,8) USING ROUND(pledged_value::numeric, 8);
+ALTER TABLE "TokenLineage" ALTER COLUMN token_value TYPE NUMERIC(20,8) USING ROUND(token_value::numeric, 8);
+ALTER TABLE "LedgerEntries"
+    ALTER COLUMN debit TYPE NUMERIC(20,8) USING ROUND(debit::numeric, 8),
+    ALTER COLUMN credit TYPE NUMERIC(20,8) USING ROUND(credit::numeric, 8);
+
+-- Running RBT totals accumulated float error; recompute them from the free tokens each DID owns.
+UPDATE "DIDs" d SET total_rbts = COALESCE((
+    SELECT SUM(r.token_value) FROM "RBT" r
+    WHERE r.owner_did = d.did AND r.token_status = 0
+), 0);
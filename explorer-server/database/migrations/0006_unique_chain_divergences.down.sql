@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uniq_chain_divergences;
@@ -0,0 +1,15 @@
+-- A divergence is recorded once per token, block, kind and pair of nodes; repeated
+-- verification runs refresh detected_at instead of adding rows. Existing duplicates
+-- keep only their latest detection.
+
+DELETE FROM "ChainDivergences" d
+USING "ChainDivergences" newer
+WHERE d.token_id = newer.token_id
+  AND d.block_index = newer.block_index
+  AND d.kind = newer.kind
+  AND d.node_a = newer.node_a
+  AND d.node_b = newer.node_b
+  AND (d.detected_at, d.id) < (newer.detected_at, newer.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_chain_divergences
+    ON "ChainDivergences" (token_id, block_index, kind, node_a, node_b);
@@ -0,0 +1,9 @@
+-- Tokens a block moved whose RBT value was unknown when its ledger entries were derived.
+-- Their blocks are derived again by the ledger backfill until every token can be valued.
+
+CREATE TABLE IF NOT EXISTS "UnvaluedLedgerTokens" (
+    block_hash TEXT NOT NULL,
+    token_id TEXT NOT NULL,
+    flagged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (block_hash, token_id)
+);
@@ -1,19 +1,45 @@
 package models
 
 import (
-	"time"
+	"explorer-server/decimal"
 	"gorm.io/datatypes"
+	"time"
+)
+
+// ========================= BlockTime =========================
+// BlockTime is the timestamp of a block, embedded in every block table.
+// EpochSource records where the time was taken from and EpochConfidence how far it can be trusted.
+type BlockTime struct {
+	Epoch           *time.Time `json:"epoch" gorm:"column:epoch;type:timestamptz;index"`
+	EpochSource     string     `json:"epoch_source" gorm:"column:epoch_source"`
+	EpochConfidence string     `json:"epoch_confidence" gorm:"column:epoch_confidence"`
+}
+
+// Sources of a block timestamp
+const (
+	EpochSourceBlock   = "block_epoch" // the epoch carried by the block itself
+	EpochSourceComment = "comment"     // a time written in the block comment (burn blocks)
+	EpochSourceLegacy  = "legacy"      // migrated from a row stored before sources were tracked
+	EpochSourceNone    = "none"        // the block carries no usable time
+)
+
+// Confidence levels of a block timestamp
+const (
+	EpochConfidenceHigh   = "high"
+	EpochConfidenceMedium = "medium"
+	EpochConfidenceLow    = "low"
+	EpochConfidenceNone   = "none"
 )
 
 // ========================= TransferBlocks =========================
 type TransferBlocks struct {
-	BlockHash          string         `json:"block_hash" gorm:"primaryKey;column:block_hash"`
-	PrevBlockID        *string        `json:"prev_block_id" gorm:"column:prev_block_id"`
-	SenderDID          *string        `json:"sender_did" gorm:"column:sender_did"`
-	ReceiverDID        *string        `json:"receiver_did" gorm:"column:receiver_did"`
-	TxnType            *string        `json:"txn_type" gorm:"column:txn_type"`
-	Amount             *float64       `json:"amount" gorm:"column:amount"`
-	Epoch              *int64         `json:"epoch" gorm:"column:epoch"`
+	BlockHash   string           `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	PrevBlockID *string          `json:"prev_block_id" gorm:"column:prev_block_id"`
+	SenderDID   *string          `json:"sender_did" gorm:"column:sender_did"`
+	ReceiverDID *string          `json:"receiver_did" gorm:"column:receiver_did"`
+	TxnType     *string          `json:"txn_type" gorm:"column:txn_type"`
+	Amount      *decimal.Decimal `json:"amount" gorm:"column:amount;type:numeric(20,8)"`
+	BlockTime
 	Tokens             datatypes.JSON `json:"tokens" gorm:"column:tokens;type:jsonb"`
 	ValidatorPledgeMap datatypes.JSON `json:"validator_pledge_map" gorm:"column:validator_pledge_map;type:jsonb"`
 	TxnID              *string        `json:"txn_id" gorm:"column:txn_id"`
@@ -23,7 +49,7 @@ func (TransferBlocks) TableName() string { return "TransferBlocks" }
 
 // ========================= TokenType =========================
 type TokenType struct {
-	TokenID     string    `json:"token_id" gorm:"column:token_id"`
+	TokenID     string    `json:"token_id" gorm:"primaryKey;column:token_id"`
 	TokenType   string    `json:"token_type" gorm:"column:token_type"`
 	LastUpdated time.Time `json:"last_updated" gorm:"column:last_updated"`
 }
@@ -32,17 +58,18 @@ func (TokenType) TableName() string { return "TokenType" }
 
 // ========================= AllBlocks =========================
 type AllBlocks struct {
-	BlockHash string    `json:"block_hash" gorm:"column:block_hash;primaryKey"`
-	BlockType string    `json:"block_type" gorm:"column:block_type"`
-	Epoch     time.Time `json:"epoch" gorm:"column:epoch"`
-	TxnID     string    `json:"txn_id" gorm:"column:txn_id"`
+	BlockHash string `json:"block_hash" gorm:"column:block_hash;primaryKey"`
+	BlockType string `json:"block_type" gorm:"column:block_type"`
+	BlockTime
+	TxnID      string `json:"txn_id" gorm:"column:txn_id"`
+	SourceNode string `json:"source_node" gorm:"column:source_node"`
 }
 
 func (AllBlocks) TableName() string { return "AllBlocks" }
 
 // ========================= SmartContract =========================
 type SmartContract struct {
-	ContractID  string `json:"contract_id" gorm:"column:contract_id"`
+	ContractID  string `json:"contract_id" gorm:"primaryKey;column:contract_id"`
 	BlockHash   string `json:"block_hash" gorm:"column:block_hash"`
 	DeployerDID string `json:"deployer_did" gorm:"column:deployer_did"`
 	TxnId       string `json:"txn_id" gorm:"column:txn_id"`
@@ -54,63 +81,63 @@ func (SmartContract) TableName() string { return "SmartContract" }
 
 // ========================= RBT =========================
 type RBT struct {
-	TokenID     string  `json:"rbt_id" gorm:"column:rbt_id"`
-	OwnerDID    string  `json:"owner_did" gorm:"column:owner_did"`
-	BlockID     string  `json:"block_id" gorm:"column:block_id"`
-	BlockHeight string  `json:"block_height" gorm:"column:block_height"`
-	TokenValue  float64 `json:"token_value" gorm:"column:token_value"`
-	TokenStatus int     `json:"token_status" gorm:"column:token_status"`
+	TokenID     string          `json:"rbt_id" gorm:"primaryKey;column:rbt_id"`
+	OwnerDID    string          `json:"owner_did" gorm:"column:owner_did"`
+	BlockID     string          `json:"block_id" gorm:"column:block_id"`
+	BlockHeight string          `json:"block_height" gorm:"column:block_height"`
+	TokenValue  decimal.Decimal `json:"token_value" gorm:"column:token_value;type:numeric(20,8)"`
+	TokenStatus int             `json:"token_status" gorm:"column:token_status"`
 }
 
 func (RBT) TableName() string { return "RBT" }
 
 // ========================= FT =========================
 type FT struct {
-	FtID        string  `json:"ft_id" gorm:"column:ft_id"`
-	TokenValue  float64 `json:"token_value" gorm:"column:token_value"`
-	FTName      string  `json:"ft_name" gorm:"column:ft_name"`
-	OwnerDID    string  `json:"owner_did" gorm:"column:owner_did"`
-	CreatorDID  string  `json:"creator_did" gorm:"column:creator_did"`
-	BlockHeight uint64  `json:"block_height" gorm:"column:block_height"`
-	BlockID     string  `json:"block_id" gorm:"column:block_id"`
-	Txn_ID      string  `json:"txn_id" gorm:"column:txn_id"`
-	TokenStatus int     `json:"token_status" gorm:"column:token_status"`
+	FtID        string          `json:"ft_id" gorm:"primaryKey;column:ft_id"`
+	TokenValue  decimal.Decimal `json:"token_value" gorm:"column:token_value;type:numeric(20,8)"`
+	FTName      string          `json:"ft_name" gorm:"column:ft_name"`
+	OwnerDID    string          `json:"owner_did" gorm:"column:owner_did"`
+	CreatorDID  string          `json:"creator_did" gorm:"column:creator_did"`
+	BlockHeight uint64          `json:"block_height" gorm:"column:block_height"`
+	BlockID     string          `json:"block_id" gorm:"column:block_id"`
+	Txn_ID      string          `json:"txn_id" gorm:"column:txn_id"`
+	TokenStatus int             `json:"token_status" gorm:"column:token_status"`
 }
 
 func (FT) TableName() string { return "FT" }
 
 // ========================= NFT =========================
 type NFT struct {
-	TokenID     string `json:"nft_id" gorm:"column:nft_id"`
-	TokenValue  string `json:"token_value" gorm:"column:token_value"`
-	OwnerDID    string `json:"owner_did" gorm:"column:owner_did"`
-	BlockHash   string `json:"block_hash" gorm:"column:block_hash"`
-	Txn_ID      string `json:"txn_id" gorm:"column:txn_id"`
-	BlockHeight uint64 `json:"block_height" gorm:"column:block_height"`
-	TokenStatus int    `json:"token_status" gorm:"column:token_status"`
+	TokenID     string          `json:"nft_id" gorm:"primaryKey;column:nft_id"`
+	TokenValue  decimal.Decimal `json:"token_value" gorm:"column:token_value;type:numeric(20,8)"`
+	OwnerDID    string          `json:"owner_did" gorm:"column:owner_did"`
+	BlockHash   string          `json:"block_hash" gorm:"column:block_hash"`
+	Txn_ID      string          `json:"txn_id" gorm:"column:txn_id"`
+	BlockHeight uint64          `json:"block_height" gorm:"column:block_height"`
+	TokenStatus int             `json:"token_status" gorm:"column:token_status"`
 }
 
 func (NFT) TableName() string { return "NFT" }
 
 // ========================= DIDs =========================
 type DIDs struct {
-	DID       string    `json:"did" gorm:"primaryKey;column:did"`
-	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
-	TotalRBTs float64   `json:"total_rbts" gorm:"column:total_rbts"`
-	TotalFTs  float64   `json:"total_fts" gorm:"column:total_fts"`
-	TotalNFTs int64     `json:"total_nfts" gorm:"column:total_nfts"`
-	TotalSC   int64     `json:"total_sc" gorm:"column:total_sc"`
+	DID       string          `json:"did" gorm:"primaryKey;column:did"`
+	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at"`
+	TotalRBTs decimal.Decimal `json:"total_rbts" gorm:"column:total_rbts;type:numeric(20,8)"`
+	TotalFTs  decimal.Decimal `json:"total_fts" gorm:"column:total_fts;type:numeric(20,8)"`
+	TotalNFTs int64           `json:"total_nfts" gorm:"column:total_nfts"`
+	TotalSC   int64           `json:"total_sc" gorm:"column:total_sc"`
 }
 
 func (DIDs) TableName() string { return "DIDs" }
 
 // ========================= TxnAnalytics =========================
 type TxnAnalytics struct {
-	IntervalStart time.Time `json:"interval_start" gorm:"column:interval_start"`
-	IntervalEnd   time.Time `json:"interval_end" gorm:"column:interval_end"`
-	TxnCount      int64     `json:"txn_count" gorm:"column:txn_count"`
-	TotalValue    float64   `json:"total_value" gorm:"column:total_value"`
-	TokenType     string    `json:"token_type" gorm:"column:token_type"`
+	IntervalStart time.Time       `json:"interval_start" gorm:"column:interval_start"`
+	IntervalEnd   time.Time       `json:"interval_end" gorm:"column:interval_end"`
+	TxnCount      int64           `json:"txn_count" gorm:"column:txn_count"`
+	TotalValue    decimal.Decimal `json:"total_value" gorm:"column:total_value;type:numeric(20,8)"`
+	TokenType     string          `json:"token_type" gorm:"column:token_type"`
 }
 
 func (TxnAnalytics) TableName() string { return "TxnAnalytics" }
@@ -126,12 +153,12 @@ func (DatabaseHealth) TableName() string { return "DatabaseHealth" }
 
 // ========================= SC_Block =========================
 type SC_Block struct {
-	Block_ID     string    `json:"block_id" gorm:"primaryKey;column:block_id"`
-	Contract_ID  string    `json:"contract_id" gorm:"column:contract_id"`
-	Executor_DID *string   `json:"executor_did" gorm:"column:executor_did"`
-	Block_Height int64     `json:"block_height" gorm:"column:block_height"`
-	Epoch        time.Time `json:"epoch" gorm:"column:epoch"`
-	Owner_DID    string    `json:"owner_did" gorm:"column:owner_did"`
+	Block_ID     string  `json:"block_id" gorm:"primaryKey;column:block_id"`
+	Contract_ID  string  `json:"contract_id" gorm:"column:contract_id"`
+	Executor_DID *string `json:"executor_did" gorm:"column:executor_did"`
+	Block_Height int64   `json:"block_height" gorm:"column:block_height"`
+	BlockTime
+	Owner_DID string `json:"owner_did" gorm:"column:owner_did"`
 }
 
 func (SC_Block) TableName() string { return "SC_Blocks" }
@@ -142,8 +169,251 @@ type BurntBlocks struct {
 	ChildTokens datatypes.JSON `json:"child_tokens" gorm:"column:child_tokens;type:jsonb"`
 	TxnType     *string        `json:"txn_type" gorm:"column:txn_type"`
 	OwnerDID    string         `json:"owner_did" gorm:"column:owner_did"`
-	Epoch       *int64         `json:"epoch" gorm:"column:epoch"`
-	Tokens      datatypes.JSON `json:"tokens" gorm:"column:tokens;type:jsonb"`
+	BlockTime
+	Tokens datatypes.JSON `json:"tokens" gorm:"column:tokens;type:jsonb"`
 }
 
 func (BurntBlocks) TableName() string { return "BurntBlocks" }
+
+// ========================= TokenSyncCheckpoints =========================
+type TokenSyncCheckpoint struct {
+	TokenID         string    `json:"token_id" gorm:"primaryKey;column:token_id"`
+	TokenType       string    `json:"token_type" gorm:"column:token_type"`
+	LastBlockHeight int64     `json:"last_block_height" gorm:"column:last_block_height"`
+	LastBlockHash   string    `json:"last_block_hash" gorm:"column:last_block_hash"`
+	LastSyncedAt    time.Time `json:"last_synced_at" gorm:"column:last_synced_at"`
+	LastSourceNode  string    `json:"last_source_node" gorm:"column:last_source_node"`
+	Status          string    `json:"status" gorm:"column:status"`
+	LastError       string    `json:"last_error" gorm:"column:last_error"`
+}
+
+func (TokenSyncCheckpoint) TableName() string { return "TokenSyncCheckpoints" }
+
+// ========================= ChainDivergences =========================
+type ChainDivergence struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	TokenID    string    `json:"token_id" gorm:"column:token_id;index;uniqueIndex:uniq_chain_divergences,priority:1"`
+	TokenType  string    `json:"token_type" gorm:"column:token_type"`
+	BlockIndex int       `json:"block_index" gorm:"column:block_index;uniqueIndex:uniq_chain_divergences,priority:2"`
+	Kind       string    `json:"kind" gorm:"column:kind;uniqueIndex:uniq_chain_divergences,priority:3"`
+	NodeA      string    `json:"node_a" gorm:"column:node_a;uniqueIndex:uniq_chain_divergences,priority:4"`
+	NodeB      string    `json:"node_b" gorm:"column:node_b;uniqueIndex:uniq_chain_divergences,priority:5"`
+	HashA      string    `json:"hash_a" gorm:"column:hash_a"`
+	HashB      string    `json:"hash_b" gorm:"column:hash_b"`
+	Detail     string    `json:"detail" gorm:"column:detail"`
+	DetectedAt time.Time `json:"detected_at" gorm:"column:detected_at"`
+}
+
+func (ChainDivergence) TableName() string { return "ChainDivergences" }
+
+// ========================= FailedTokenSyncs =========================
+type FailedTokenSync struct {
+	TokenID       string    `json:"token_id" gorm:"primaryKey;column:token_id"`
+	TokenType     string    `json:"token_type" gorm:"column:token_type"`
+	NodeTokenType int       `json:"node_token_type" gorm:"column:node_token_type"`
+	DID           string    `json:"did" gorm:"column:did"`
+	AssetType     int       `json:"asset_type" gorm:"column:asset_type"`
+	Source        string    `json:"source" gorm:"column:source"`
+	Status        string    `json:"status" gorm:"column:status;index"`
+	Attempts      int       `json:"attempts" gorm:"column:attempts"`
+	LastError     string    `json:"last_error" gorm:"column:last_error"`
+	NextRetryAt   time.Time `json:"next_retry_at" gorm:"column:next_retry_at;index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (FailedTokenSync) TableName() string { return "FailedTokenSyncs" }
+
+// ========================= IngestInbox =========================
+type IngestInbox struct {
+	ID            uint64         `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	Kind          string         `json:"kind" gorm:"column:kind;index:idx_ingest_inbox_claim,priority:1"`
+	Payload       datatypes.JSON `json:"payload" gorm:"column:payload;type:jsonb"`
+	Status        string         `json:"status" gorm:"column:status;index:idx_ingest_inbox_claim,priority:2"`
+	Attempts      int            `json:"attempts" gorm:"column:attempts"`
+	LastError     string         `json:"last_error" gorm:"column:last_error"`
+	NextAttemptAt time.Time      `json:"next_attempt_at" gorm:"column:next_attempt_at;index:idx_ingest_inbox_claim,priority:3"`
+	LockedAt      *time.Time     `json:"locked_at" gorm:"column:locked_at"`
+	ProcessedAt   *time.Time     `json:"processed_at" gorm:"column:processed_at"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"column:created_at"`
+}
+
+func (IngestInbox) TableName() string { return "IngestInbox" }
+
+// ========================= MintBlocks =========================
+// Minted (01), Migrated (03) and Generation (05) blocks
+type MintBlocks struct {
+	BlockHash    string           `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	TxnType      string           `json:"txn_type" gorm:"column:txn_type;index:idx_mint_blocks_txn_type"`
+	TxnID        *string          `json:"txn_id" gorm:"column:txn_id;index:idx_mint_blocks_txn_id"`
+	OwnerDID     string           `json:"owner_did" gorm:"column:owner_did;index:idx_mint_blocks_owner_did"`
+	TokenValue   *decimal.Decimal `json:"token_value" gorm:"column:token_value;type:numeric(20,8)"`
+	Tokens       datatypes.JSON   `json:"tokens" gorm:"column:tokens;type:jsonb"`
+	GenesisBlock datatypes.JSON   `json:"genesis_block" gorm:"column:genesis_block;type:jsonb"`
+	BlockTime
+}
+
+func (MintBlocks) TableName() string { return "MintBlocks" }
+
+// ========================= PledgeBlocks =========================
+// Pledged (04) and Unpledged (06) blocks
+type PledgeBlocks struct {
+	BlockHash  string         `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	TxnType    string         `json:"txn_type" gorm:"column:txn_type;index:idx_pledge_blocks_txn_type"`
+	TxnID      *string        `json:"txn_id" gorm:"column:txn_id;index:idx_pledge_blocks_txn_id"`
+	OwnerDID   string         `json:"owner_did" gorm:"column:owner_did;index:idx_pledge_blocks_owner_did"`
+	PledgedDID *string        `json:"pledged_did" gorm:"column:pledged_did"`
+	Tokens     datatypes.JSON `json:"tokens" gorm:"column:tokens;type:jsonb"`
+	BlockTime
+}
+
+func (PledgeBlocks) TableName() string { return "PledgeBlocks" }
+
+// ========================= CommitBlocks =========================
+// Committed (07) and ContractCommitted (11) blocks
+type CommitBlocks struct {
+	BlockHash       string         `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	TxnType         string         `json:"txn_type" gorm:"column:txn_type;index:idx_commit_blocks_txn_type"`
+	TxnID           *string        `json:"txn_id" gorm:"column:txn_id;index:idx_commit_blocks_txn_id"`
+	OwnerDID        string         `json:"owner_did" gorm:"column:owner_did;index:idx_commit_blocks_owner_did"`
+	CommittedDID    *string        `json:"committed_did" gorm:"column:committed_did"`
+	Tokens          datatypes.JSON `json:"tokens" gorm:"column:tokens;type:jsonb"`
+	CommittedTokens datatypes.JSON `json:"committed_tokens" gorm:"column:committed_tokens;type:jsonb"`
+	BlockTime
+}
+
+func (CommitBlocks) TableName() string { return "CommitBlocks" }
+
+// ========================= PinBlocks =========================
+// PinnedAsService (12) blocks
+type PinBlocks struct {
+	BlockHash string         `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	TxnID     *string        `json:"txn_id" gorm:"column:txn_id;index:idx_pin_blocks_txn_id"`
+	OwnerDID  string         `json:"owner_did" gorm:"column:owner_did;index:idx_pin_blocks_owner_did"`
+	Comment   *string        `json:"comment" gorm:"column:comment"`
+	Tokens    datatypes.JSON `json:"tokens" gorm:"column:tokens;type:jsonb"`
+	BlockTime
+}
+
+func (PinBlocks) TableName() string { return "PinBlocks" }
+
+// ========================= QuorumPledges =========================
+// One row per token a quorum pledged to validate a block
+type QuorumPledge struct {
+	ID                  uint             `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	BlockHash           string           `json:"block_hash" gorm:"column:block_hash;uniqueIndex:uniq_quorum_pledges,priority:1"`
+	TxnID               *string          `json:"txn_id" gorm:"column:txn_id"`
+	TxnType             string           `json:"txn_type" gorm:"column:txn_type"`
+	QuorumDID           string           `json:"quorum_did" gorm:"column:quorum_did;uniqueIndex:uniq_quorum_pledges,priority:2;index:idx_quorum_pledges_quorum_did"`
+	PledgedToken        string           `json:"pledged_token" gorm:"column:pledged_token;uniqueIndex:uniq_quorum_pledges,priority:3"`
+	PledgedTokenType    int              `json:"pledged_token_type" gorm:"column:pledged_token_type"`
+	PledgedTokenBlockID string           `json:"pledged_token_block_id" gorm:"column:pledged_token_block_id"`
+	PledgedValue        *decimal.Decimal `json:"pledged_value" gorm:"column:pledged_value;type:numeric(20,8)"`
+	Epoch               *time.Time       `json:"epoch" gorm:"column:epoch;type:timestamptz;index:idx_quorum_pledges_epoch"`
+}
+
+func (QuorumPledge) TableName() string { return "QuorumPledges" }
+
+// ========================= TokenLineage =========================
+// One row per relation between two tokens: RelatedTokenID is the Relation of TokenID
+// (e.g. the whole RBT a PART token was split from is its "parent")
+type TokenLineage struct {
+	ID             uint             `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	TokenID        string           `json:"token_id" gorm:"column:token_id;uniqueIndex:uniq_token_lineage,priority:1"`
+	RelatedTokenID string           `json:"related_token_id" gorm:"column:related_token_id;uniqueIndex:uniq_token_lineage,priority:2;index:idx_token_lineage_related"`
+	Relation       string           `json:"relation" gorm:"column:relation;uniqueIndex:uniq_token_lineage,priority:3"`
+	BlockHash      string           `json:"block_hash" gorm:"column:block_hash"`
+	TokenValue     *decimal.Decimal `json:"token_value" gorm:"column:token_value;type:numeric(20,8)"`
+	Epoch          *time.Time       `json:"epoch" gorm:"column:epoch;type:timestamptz"`
+}
+
+func (TokenLineage) TableName() string { return "TokenLineage" }
+
+// ========================= TokenOwnershipHistory =========================
+// One row per change of a token's owner taken from mint, transfer and burn blocks:
+// mints have no FromDID and burns have no ToDID
+type TokenOwnership struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	TokenID   string     `json:"token_id" gorm:"column:token_id;uniqueIndex:uniq_token_ownership,priority:1"`
+	BlockHash string     `json:"block_hash" gorm:"column:block_hash;uniqueIndex:uniq_token_ownership,priority:2;index:idx_token_ownership_block_hash"`
+	EventType string     `json:"event_type" gorm:"column:event_type"`
+	FromDID   *string    `json:"from_did" gorm:"column:from_did;index:idx_token_ownership_from_did"`
+	ToDID     *string    `json:"to_did" gorm:"column:to_did;index:idx_token_ownership_to_did"`
+	TxnID     *string    `json:"txn_id" gorm:"column:txn_id"`
+	Epoch     *time.Time `json:"epoch" gorm:"column:epoch;type:timestamptz"`
+}
+
+func (TokenOwnership) TableName() string { return "TokenOwnershipHistory" }
+
+// ========================= LedgerEntries =========================
+// Double-entry record of value movements: each movement of a token debits the account
+// receiving it and credits the account giving it, so a block's entries net to zero per asset
+type LedgerEntry struct {
+	ID        uint            `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	BlockHash string          `json:"block_hash" gorm:"column:block_hash;index:idx_ledger_entries_block_hash"`
+	TokenID   string          `json:"token_id" gorm:"column:token_id"`
+	EntryType string          `json:"entry_type" gorm:"column:entry_type"`
+	Account   string          `json:"account" gorm:"column:account;index:idx_ledger_entries_account"`
+	Asset     string          `json:"asset" gorm:"column:asset"`
+	Debit     decimal.Decimal `json:"debit" gorm:"column:debit;type:numeric(20,8)"`
+	Credit    decimal.Decimal `json:"credit" gorm:"column:credit;type:numeric(20,8)"`
+	TxnID     *string         `json:"txn_id" gorm:"column:txn_id"`
+	Epoch     *time.Time      `json:"epoch" gorm:"column:epoch;type:timestamptz"`
+}
+
+func (LedgerEntry) TableName() string { return "LedgerEntries" }
+
+// ========================= UnvaluedLedgerTokens =========================
+// A token whose RBT value was unknown when the ledger entries of a block were derived;
+// the block's entries leave it out until the backfill can value it
+type UnvaluedLedgerToken struct {
+	BlockHash string    `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	TokenID   string    `json:"token_id" gorm:"primaryKey;column:token_id"`
+	FlaggedAt time.Time `json:"flagged_at" gorm:"column:flagged_at"`
+}
+
+func (UnvaluedLedgerToken) TableName() string { return "UnvaluedLedgerTokens" }
+
+// ========================= DIDKeys =========================
+// secp256k1 public keys used to verify block signatures
+type DIDKey struct {
+	DID       string    `json:"did" gorm:"primaryKey;column:did"`
+	PublicKey string    `json:"public_key" gorm:"column:public_key;type:text"`
+	Source    string    `json:"source" gorm:"column:source"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (DIDKey) TableName() string { return "DIDKeys" }
+
+// ========================= BlockVerifications =========================
+// Outcome of checking a block's sender, quorum and owner signatures and recomputing its hash.
+// The hash check stays out of API responses until the recomputation is confirmed against
+// blocks captured from a real fullnode.
+type BlockVerification struct {
+	BlockHash      string         `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	TxnID          *string        `json:"txn_id" gorm:"column:txn_id;index:idx_block_verifications_txn_id"`
+	Status         string         `json:"status" gorm:"column:status;index:idx_block_verifications_status"`
+	Reason         string         `json:"reason" gorm:"column:reason;type:text"`
+	SenderStatus   string         `json:"sender_status" gorm:"column:sender_status"`
+	QuorumVerified int            `json:"quorum_verified" gorm:"column:quorum_verified"`
+	QuorumTotal    int            `json:"quorum_total" gorm:"column:quorum_total"`
+	Signatures     datatypes.JSON `json:"signatures" gorm:"column:signatures;type:jsonb"`
+	HashStatus     string         `json:"-" gorm:"column:hash_status;index:idx_block_verifications_hash_status"`
+	ComputedHash   string         `json:"-" gorm:"column:computed_hash"`
+	VerifiedAt     time.Time      `json:"verified_at" gorm:"column:verified_at"`
+}
+
+func (BlockVerification) TableName() string { return "BlockVerifications" }
+
+// ========================= TokenChainBlocks =========================
+// Local copy of each token chain: the raw block as received, ordered by height
+type TokenChainBlock struct {
+	TokenID     string         `json:"token_id" gorm:"primaryKey;column:token_id;index:idx_token_chain_blocks_height,priority:1"`
+	BlockHash   string         `json:"block_hash" gorm:"primaryKey;column:block_hash"`
+	BlockHeight int64          `json:"block_height" gorm:"column:block_height;index:idx_token_chain_blocks_height,priority:2"`
+	TxnType     string         `json:"txn_type" gorm:"column:txn_type"`
+	Block       datatypes.JSON `json:"block" gorm:"column:block;type:jsonb"`
+	StoredAt    time.Time      `json:"stored_at" gorm:"column:stored_at"`
+}
+
+func (TokenChainBlock) TableName() string { return "TokenChainBlocks" }
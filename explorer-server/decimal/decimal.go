@@ -0,0 +1,299 @@
+// Package decimal is the fixed-point amount type of the explorer. Token values, transfer
+// amounts and totals are held as a whole number of 10^-8 units, so sums are exact; they
+// are stored as NUMERIC and written to JSON as plain numbers.
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places an amount keeps
+const Scale = 8
+
+// one is the number of units in 1
+const one = 100000000
+
+// ErrOutOfRange is returned for amounts that do not fit the fixed-point range
+var ErrOutOfRange = errors.New("decimal: amount out of range")
+
+// Decimal is a fixed-point amount with Scale decimal places; the zero value is 0
+type Decimal struct {
+	units int64
+}
+
+// Zero is the amount 0
+var Zero = Decimal{}
+
+// FromInt returns the whole amount n
+func FromInt(n int64) Decimal {
+	return Decimal{units: n * one}
+}
+
+// FromFloat converts a float through its shortest decimal representation, so 0.1 becomes
+// exactly 0.1 and digits beyond Scale are rounded half away from zero
+func FromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero
+	}
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return d
+}
+
+// Parse reads a decimal string such as "12", "-0.5" or "1.23e-3"; digits beyond Scale are
+// rounded half away from zero
+func Parse(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, fmt.Errorf("decimal: empty amount")
+	}
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Zero, fmt.Errorf("decimal: invalid amount %q", s)
+		}
+		exp, s = e, s[:i]
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return Zero, fmt.Errorf("decimal: invalid amount %q", s)
+	}
+	digits := intPart + fracPart
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Zero, fmt.Errorf("decimal: invalid amount %q", s)
+		}
+	}
+
+	// reject whole parts longer than 19 digits before padding, so a huge exponent cannot
+	// build a huge string; the first test keeps len(intPart)+exp from overflowing
+	lead := len(digits) - len(strings.TrimLeft(digits, "0"))
+	if lead == len(digits) {
+		return Zero, nil
+	}
+	if exp > 19+Scale+len(digits) || len(intPart)+exp-lead > 19 {
+		return Zero, ErrOutOfRange
+	}
+
+	// shift the decimal point so that digits holds the amount in units plus extra digits
+	point := len(intPart) + exp + Scale
+	if point < 0 {
+		return Zero, nil
+	}
+	for len(digits) < point {
+		digits += "0"
+	}
+	whole, rest := digits[:point], digits[point:]
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole) > 19 {
+		return Zero, ErrOutOfRange
+	}
+
+	var units int64
+	if whole != "" {
+		u, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return Zero, ErrOutOfRange
+		}
+		units = u
+	}
+	if rest != "" && rest[0] >= '5' {
+		if units == math.MaxInt64 {
+			return Zero, ErrOutOfRange
+		}
+		units++
+	}
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, nil
+}
+
+// MustParse is Parse for constants; it panics on an invalid amount
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{units: d.units + o.units}
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{units: d.units - o.units}
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units}
+}
+
+// Mul returns d multiplied by a whole number
+func (d Decimal) Mul(n int64) Decimal {
+	return Decimal{units: d.units * n}
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than o
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.units < o.units:
+		return -1
+	case d.units > o.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Sign returns -1, 0 or 1 as d is negative, zero or positive
+func (d Decimal) Sign() int {
+	return d.Cmp(Zero)
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// IntPart returns the whole part of d, truncated toward zero
+func (d Decimal) IntPart() int64 {
+	return d.units / one
+}
+
+// Float64 returns the nearest float, for display and ratios only
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d without trailing zeros, e.g. "0.3", "-12" or "0.00000001"
+func (d Decimal) String() string {
+	units := d.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+	abs := uint64(units)
+	if units < 0 {
+		abs = uint64(-units)
+	}
+
+	whole := abs / one
+	frac := abs % one
+	if frac == 0 {
+		return sign + strconv.FormatUint(whole, 10)
+	}
+	fracDigits := strings.TrimRight(fmt.Sprintf("%0*d", Scale, frac), "0")
+	return sign + strconv.FormatUint(whole, 10) + "." + fracDigits
+}
+
+// MarshalJSON writes d as a JSON number
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number, a numeric string or null
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = Zero
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		if strings.TrimSpace(unquoted) == "" {
+			*d = Zero
+			return nil
+		}
+		s = unquoted
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores d as a NUMERIC literal
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a NUMERIC, integer, float or text column
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case int64:
+		*d = FromInt(v)
+		return nil
+	case float64:
+		*d = FromFloat(v)
+		return nil
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	default:
+		return fmt.Errorf("decimal: cannot scan %T", src)
+	}
+}
+
+func (d *Decimal) scanString(s string) error {
+	if strings.TrimSpace(s) == "" {
+		*d = Zero
+		return nil
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// GormDataType declares the column type used when GORM builds a schema
+func (Decimal) GormDataType() string {
+	return "numeric"
+}
+
+// Sum adds up amounts
+func Sum(amounts ...Decimal) Decimal {
+	var total Decimal
+	for _, a := range amounts {
+		total = total.Add(a)
+	}
+	return total
+}
+
+// Ptr returns a pointer to a copy of d, for nullable columns
+func Ptr(d Decimal) *Decimal {
+	return &d
+}
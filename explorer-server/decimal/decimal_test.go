@@ -0,0 +1,88 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAndString(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"0", "0"},
+		{"12", "12"},
+		{"-0.5", "-0.5"},
+		{"0.30000000000000004", "0.3"},
+		{"1.234567895", "1.2345679"},
+		{"1.5e-3", "0.0015"},
+		{"2E2", "200"},
+		{".25", "0.25"},
+		{"0.000000001", "0"},
+		{"0e999999999", "0"},
+		{"0.005e3", "5"},
+		{"1e-999999999", "0"},
+	}
+	for _, c := range cases {
+		d, err := Parse(c.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.in, err)
+		}
+		if got := d.String(); got != c.want {
+			t.Errorf("Parse(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"", "abc", "1.2.3", "1e", "99999999999999999999"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+
+	// huge exponents are rejected before the digits are padded out
+	for _, big := range []string{"1e999999999", "-1e999999999", "1e9223372036854775807", "0.001e25"} {
+		if _, err := Parse(big); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Parse(%q) = %v, want ErrOutOfRange", big, err)
+		}
+	}
+}
+
+func TestSumsAreExact(t *testing.T) {
+	if got := FromFloat(0.1).Add(FromFloat(0.2)); got != MustParse("0.3") {
+		t.Fatalf("0.1 + 0.2 = %s", got)
+	}
+
+	var total Decimal
+	for i := 0; i < 1000; i++ {
+		total = total.Add(MustParse("0.001"))
+	}
+	if total != FromInt(1) {
+		t.Fatalf("1000 * 0.001 = %s", total)
+	}
+}
+
+func TestJSONAndSQL(t *testing.T) {
+	var v struct {
+		Number  Decimal  `json:"number"`
+		Text    Decimal  `json:"text"`
+		Missing *Decimal `json:"missing"`
+	}
+	if err := json.Unmarshal([]byte(`{"number": 0.30000000000000004, "text": "1.50", "missing": null}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != `{"number":0.3,"text":1.5,"missing":null}` {
+		t.Fatalf("Marshal = %s", out)
+	}
+
+	var d Decimal
+	for _, src := range []interface{}{"2.50000000", []byte("2.5"), 2.5} {
+		if err := d.Scan(src); err != nil || d != MustParse("2.5") {
+			t.Fatalf("Scan(%v) = %s, %v", src, d, err)
+		}
+	}
+	if val, _ := d.Value(); val != "2.5" {
+		t.Fatalf("Value = %v", val)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"explorer-server/services"
+	"net/http"
+)
+
+// GetDIDBalanceHistoryHandler returns what a DID held at the end of a range and, with an
+// interval, over the range (?did=&from=&to=&interval=hour|day|week|month)
+func GetDIDBalanceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	did := q.Get("did")
+	if did == "" {
+		writeMissingParam(w, "did")
+		return
+	}
+
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := services.GetDIDBalanceHistory(did, q.Get("interval"), timeRange)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, history)
+}
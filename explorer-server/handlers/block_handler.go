@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"explorer-server/services"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -16,7 +17,7 @@ import (
 func GetTxnsCountHandler(w http.ResponseWriter, r *http.Request) {
 	count, err := services.GetTxnsCount()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -36,15 +37,19 @@ func GetTransferBlockListHandler(w http.ResponseWriter, r *http.Request) {
 	if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
 		page = p
 	}
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	response, err := services.GetTransferBlocksList(limit, page)
+	response, err := services.GetTransferBlocksList(limit, page, timeRange)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeTimedJSON(w, timeFormat, response)
 }
 
 func GetBlockInfoFromTxnHash(w http.ResponseWriter, r *http.Request) {
@@ -52,7 +57,7 @@ func GetBlockInfoFromTxnHash(w http.ResponseWriter, r *http.Request) {
 
 	data, err := services.GetTransferBlockInfoFromTxnID(txnHash)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -65,7 +70,7 @@ func GetBlockInfoFromBlockHash(w http.ResponseWriter, r *http.Request) {
 
 	data, err := services.GetTransferBlockInfoFromBlockHash(blockHash)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -78,7 +83,7 @@ func GetBurntTxnInfoFromTxnHash(w http.ResponseWriter, r *http.Request) {
 
 	data, err := services.GetBurntBlockInfo(txnHash)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -99,46 +104,55 @@ func GetBurntBlockList(w http.ResponseWriter, r *http.Request) {
 	if page <= 0 {
 		page = 1
 	}
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	data, err := services.GetBurntBlockList(limit, page)
+	data, err := services.GetBurntBlockList(limit, page, timeRange)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	writeTimedJSON(w, timeFormat, data)
 }
 
 // ============================================================================
-//  BLOCK UPDATE (High Priority) → Worker Pool
+//  BLOCK UPDATE (High Priority) → Ingest Inbox → Worker Pool
 // ============================================================================
 
 func UpdateBlocksHandler(w http.ResponseWriter, r *http.Request) {
-	var block map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&block); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, "Failed to read body", http.StatusBadRequest)
 		return
 	}
 
-	log.Println("📥 Received block update — queueing to high-priority worker")
-
-	// Use the ORIGINAL, already-correct update path, just executed in the pool.
-	ok := services.EnqueueBlockUpdateTask(func() {
-		// This function already knows how to:
-		//   - decode the fullnode block map
-		//   - store into AllBlocks with correct hash / type / txn_id
-		//   - fan out into RBT/FT/NFT/SC etc.
-		services.UpdateBlocks(block)
-	})
+	var block map[string]interface{}
+	if err := json.Unmarshal(body, &block); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
 
-	if !ok {
-		log.Println("⚠️ Worker queue full — processing block inline (fallback)")
-		services.UpdateBlocks(block)
+	// Persist first so the push survives restarts; the inbox poller hands it
+	// to the block worker pool, which runs the original UpdateBlocks path.
+	id, err := services.EnqueueInboxPayload(services.InboxKindBlock, body)
+	if err != nil {
+		log.Printf("❌ Failed to store block update in inbox: %v", err)
+		writeError(w, "Failed to queue block update", http.StatusServiceUnavailable)
+		return
 	}
 
+	log.Printf("📥 Received block update — stored as inbox #%d", id)
+
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"queued","message":"Block update accepted"}`))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "queued",
+		"message":  "Block update accepted",
+		"inbox_id": id,
+	})
 }
 
 // ============================================================================
@@ -148,6 +162,11 @@ func UpdateBlocksHandler(w http.ResponseWriter, r *http.Request) {
 func QueueStatusHandler(w http.ResponseWriter, r *http.Request) {
 	status := services.GetWorkerPoolStatus()
 
+	inbox, err := services.GetIngestInboxStatus()
+	if err != nil {
+		log.Printf("⚠️ Failed to read ingest inbox status: %v", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"timestamp":    time.Now().Format(time.RFC3339),
@@ -155,5 +174,6 @@ func QueueStatusHandler(w http.ResponseWriter, r *http.Request) {
 		"queue_length": status.QueueLen,
 		"queue_cap":    status.QueueCap,
 		"load_factor":  status.LoadFactor,
+		"inbox":        inbox,
 	})
 }
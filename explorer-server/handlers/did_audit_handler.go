@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+)
+
+// AuditDIDTotalsHandler reports every DID whose stored totals disagree with the token tables
+func AuditDIDTotalsHandler(w http.ResponseWriter, r *http.Request) {
+	writeDIDAudit(w, false)
+}
+
+// RepairDIDTotalsHandler audits the DID totals and recomputes the mismatching DIDs
+func RepairDIDTotalsHandler(w http.ResponseWriter, r *http.Request) {
+	writeDIDAudit(w, true)
+}
+
+func writeDIDAudit(w http.ResponseWriter, repair bool) {
+	report, err := services.AuditDIDTotals(repair)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
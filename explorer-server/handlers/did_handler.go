@@ -8,9 +8,9 @@ import (
 )
 
 func GetDIDCountHandler(w http.ResponseWriter, r *http.Request) {
-	count, err := services.GetRBTCount()
+	count, err := services.GetDIDCount()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -18,7 +18,7 @@ func GetDIDCountHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -66,7 +66,7 @@ func GetDIDHoldersListHandler(w http.ResponseWriter, r *http.Request) {
 
 	holders, err := services.GetDIDHoldersList(limit, page)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -76,7 +76,7 @@ func GetDIDHoldersListHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -111,14 +111,14 @@ func GetDIDInfoHandler(w http.ResponseWriter, r *http.Request) {
 	// Get DID info
 	didInfo, err := services.GetDIDInfoFromDID(did)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	// Get paginated RBT list
 	rbts, totalCount, err := services.GetRBTListFromDID(did, limit, page)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -131,7 +131,7 @@ func GetDIDInfoHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Encode response
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+	"strconv"
+)
+
+// GetChainDivergencesHandler lists recorded cross-node chain divergences (?token_id=&limit=&page=)
+func GetChainDivergencesHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	divergences, count, err := services.GetChainDivergences(tokenID, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"divergences": divergences,
+		"count":       count,
+	}); err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// VerifyTokenChainHandler compares one token chain across all healthy fullnodes on demand
+func VerifyTokenChainHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+	if tokenID == "" {
+		writeMissingParam(w, "token_id")
+		return
+	}
+
+	divergences, err := services.VerifyTokenChainByID(tokenID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"token_id":    tokenID,
+		"consistent":  len(divergences) == 0,
+		"divergences": divergences,
+	}); err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
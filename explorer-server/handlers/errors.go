@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"explorer-server/repository"
+	"explorer-server/services"
+	"log"
+	"net"
+	"net/http"
+)
+
+// RequestIDHeader carries the ID of a request; clients may set it, the explorer echoes it
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the client-supplied request IDs the explorer accepts
+const maxRequestIDLength = 64
+
+// Codes of the error envelope, one per status the API answers with
+const (
+	codeBadRequest       = "bad_request"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeConflict         = "conflict"
+	codeInternal         = "internal"
+	codeUnavailable      = "unavailable"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          codeBadRequest,
+	http.StatusNotFound:            codeNotFound,
+	http.StatusMethodNotAllowed:    codeMethodNotAllowed,
+	http.StatusConflict:            codeConflict,
+	http.StatusInternalServerError: codeInternal,
+	http.StatusServiceUnavailable:  codeUnavailable,
+}
+
+// serviceErrors maps the errors services return to the status they answer with
+var serviceErrors = []struct {
+	err    error
+	status int
+}{
+	{repository.ErrNotFound, http.StatusNotFound},
+	{services.ErrBlockNotFound, http.StatusNotFound},
+	{services.ErrFailedSyncNotFound, http.StatusNotFound},
+	{services.ErrInvalidInterval, http.StatusBadRequest},
+	{services.ErrTooManyBalancePoints, http.StatusBadRequest},
+	{services.ErrInvalidPublicKey, http.StatusBadRequest},
+	{services.ErrSyncRunning, http.StatusConflict},
+	{services.ErrFailedSyncRetrying, http.StatusConflict},
+	{services.ErrNotEnoughNodes, http.StatusServiceUnavailable},
+	{services.ErrNodeUnavailable, http.StatusServiceUnavailable},
+}
+
+// apiError is the body of every error answer: {"error": {...}}
+type apiError struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"request_id,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// writeError answers with the error envelope; it takes the arguments of http.Error
+func writeError(w http.ResponseWriter, message string, status int) {
+	writeErrorDetails(w, message, status, nil)
+}
+
+// writeErrorDetails answers with the error envelope and machine-readable details
+func writeErrorDetails(w http.ResponseWriter, message string, status int, details interface{}) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = codeInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]apiError{"error": {
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(RequestIDHeader),
+		Details:   details,
+	}})
+}
+
+// writeMissingParam answers 400 for a required query parameter that is absent
+func writeMissingParam(w http.ResponseWriter, name string) {
+	writeErrorDetails(w, "Missing '"+name+"' parameter", http.StatusBadRequest, map[string]string{"parameter": name})
+}
+
+// writeServiceError answers with the status a service error maps to. Errors without a
+// mapping are logged and answered with a plain 500, so SQL and driver text stays out of
+// responses.
+func writeServiceError(w http.ResponseWriter, err error) {
+	for _, m := range serviceErrors {
+		if errors.Is(err, m.err) {
+			writeError(w, err.Error(), m.status)
+			return
+		}
+	}
+
+	if backendUnavailable(err) {
+		log.Printf("⚠️ [%s] Backend unavailable: %v", w.Header().Get(RequestIDHeader), err)
+		writeError(w, "A backend service is unavailable, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	log.Printf("❌ [%s] %v", w.Header().Get(RequestIDHeader), err)
+	writeError(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// backendUnavailable reports whether err comes from an unreachable database or fullnode
+func backendUnavailable(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr)
+}
+
+// RequestID gives every request an ID, taken from the X-Request-ID header when the client
+// sent a usable one, and echoes it in the response headers
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NotFoundHandler answers requests for unknown routes
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	writeError(w, "No route for "+r.URL.Path, http.StatusNotFound)
+}
+
+// MethodNotAllowedHandler answers requests with a method the route does not serve
+func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	writeError(w, "Method "+r.Method+" is not allowed on "+r.URL.Path, http.StatusMethodNotAllowed)
+}
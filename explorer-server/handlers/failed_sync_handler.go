@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+	"strconv"
+)
+
+// GetFailedSyncsHandler lists dead-letter token syncs (?status=&limit=&page=)
+func GetFailedSyncsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	entries, count, err := services.ListFailedTokenSyncs(status, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"failed_syncs": entries,
+		"count":        count,
+	}); err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// RetryFailedSyncHandler schedules an immediate retry of one dead-letter entry (?token_id=)
+func RetryFailedSyncHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+	if tokenID == "" {
+		writeMissingParam(w, "token_id")
+		return
+	}
+
+	if err := services.RetryFailedTokenSync(tokenID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"token_id": tokenID, "status": "retry scheduled"})
+}
+
+// DiscardFailedSyncHandler stops retrying one dead-letter entry (?token_id=)
+func DiscardFailedSyncHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+	if tokenID == "" {
+		writeMissingParam(w, "token_id")
+		return
+	}
+
+	if err := services.DiscardFailedTokenSync(tokenID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"token_id": tokenID, "status": services.FailedSyncDiscarded})
+}
@@ -9,7 +9,7 @@ import (
 func GetFTCountHandler(w http.ResponseWriter, r *http.Request) {
 	count, err := services.GetFTCount()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -17,7 +17,7 @@ func GetFTCountHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -26,7 +26,7 @@ func GetFTInfoFromFTID(w http.ResponseWriter, r *http.Request) {
 	println("FT ID:", ftId)
 	ftInfo, err := services.GetFTInfoFromFTID(ftId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -34,7 +34,7 @@ func GetFTInfoFromFTID(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -44,7 +44,7 @@ func GetFtHoldingList(w http.ResponseWriter, r *http.Request) {
 
    ftInfo, err := services.GetFTListFromDID(did)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -52,6 +52,6 @@ func GetFtHoldingList(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
\ No newline at end of file
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"explorer-server/services"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,7 +15,7 @@ import (
 func GetInfo(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "Missing 'id' parameter", http.StatusBadRequest)
+		writeMissingParam(w, "id")
 		return
 	}
 
@@ -27,7 +30,7 @@ func GetInfo(w http.ResponseWriter, r *http.Request) {
 		// Fetch asset type from DB
 		assetType, err = services.GetAssetType(id)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to fetch asset type: %v", err), http.StatusInternalServerError)
+			writeServiceError(w, err)
 			return
 		}
 
@@ -45,7 +48,7 @@ func GetInfo(w http.ResponseWriter, r *http.Request) {
 		case "TransferBlock":
 			data, err = services.GetTransferBlockInfoFromTxnID(id)
 		default:
-			http.Error(w, fmt.Sprintf("Unknown asset type for ID: %s", id), http.StatusBadRequest)
+			writeError(w, fmt.Sprintf("Unknown asset type for ID: %s", id), http.StatusBadRequest)
 			return
 		}
 
@@ -55,17 +58,24 @@ func GetInfo(w http.ResponseWriter, r *http.Request) {
 	} else {
 		assetType = "TransferBlock"
 		data, err = services.GetTransferBlockInfoFromTxnID(id)
+
+		// Not a transfer: look the ID up among every stored block kind
+		if err != nil {
+			if blockType, blockData, blockErr := services.GetBlockByHashOrTxnID(id); blockErr == nil {
+				assetType, data, err = blockType, blockData, nil
+			}
+		}
 	}
 
 	// Handle any service error
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch info: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	// Handle empty data
 	if data == nil {
-		http.Error(w, fmt.Sprintf("No data found for ID: %s", id), http.StatusNotFound)
+		writeError(w, fmt.Sprintf("No data found for ID: %s", id), http.StatusNotFound)
 		return
 	}
 
@@ -78,41 +88,111 @@ func GetInfo(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 func GetTokenChainFromTokenID(w http.ResponseWriter, r *http.Request) {
-	var chainData map[string]interface{}
-
 	tokenID := r.URL.Query().Get("token_id")
 	if tokenID == "" {
-		http.Error(w, "Missing 'token_id' parameter", http.StatusBadRequest)
+		writeMissingParam(w, "token_id")
 		return
 	}
 
-	chainData, err := services.GetTokenChainFromTokenID(tokenID)
+	format := r.URL.Query().Get("format")
+	if !services.ValidBlockFormat(format) {
+		writeError(w, "Invalid 'format' parameter (raw or named)", http.StatusBadRequest)
+		return
+	}
+
+	// Blocks are relayed to the client as they are decoded, so long chains never sit in memory
+	cw := &chainJSONWriter{w: w, format: format}
+	stream, err := services.StreamTokenChainFromTokenID(tokenID, cw.writeBlock)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch token chain: %v", err), http.StatusInternalServerError)
+		if !cw.started {
+			writeServiceError(w, err)
+			return
+		}
+		// headers are already out; the truncated body is all we can do
+		log.Printf("❌ Token chain stream for %s aborted: %v", tokenID, err)
 		return
 	}
 
-	if chainData == nil {
-		http.Error(w, fmt.Sprintf("No chain data found for Token ID: %s", tokenID), http.StatusNotFound)
+	if !cw.started && stream.BlocksKey == "" && len(stream.Fields) == 0 {
+		writeError(w, fmt.Sprintf("No chain data found for Token ID: %s", tokenID), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(chainData); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	cw.finish(stream.Fields)
+}
+
+// chainJSONWriter writes {"TokenChainData":[...], <other fields>} incrementally,
+// converting blocks to the requested format (raw or named) when one is set
+type chainJSONWriter struct {
+	w       http.ResponseWriter
+	format  string
+	started bool
+}
+
+func (c *chainJSONWriter) start() {
+	c.w.Header().Set("Content-Type", "application/json")
+	io.WriteString(c.w, `{"TokenChainData":[`)
+}
+
+func (c *chainJSONWriter) writeBlock(block map[string]interface{}) error {
+	block, err := services.ConvertBlockFormat(block, c.format)
+	if err != nil {
+		return err
 	}
+	encoded, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	if !c.started {
+		c.start()
+		c.started = true
+	} else {
+		io.WriteString(c.w, ",")
+	}
+	_, err = c.w.Write(encoded)
+	return err
+}
+
+func (c *chainJSONWriter) finish(fields map[string]interface{}) {
+	if !c.started {
+		c.start()
+	}
+	io.WriteString(c.w, "]")
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key, _ := json.Marshal(k)
+		value, err := json.Marshal(fields[k])
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(c.w, ",%s:%s", key, value)
+	}
+	io.WriteString(c.w, "}")
 }
 
 func GetTokenBlocksFromTokenID(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	tokenID := r.URL.Query().Get("tokenID")
 	if tokenID == "" {
-		http.Error(w, "Missing 'tokenID' parameter", http.StatusBadRequest)
+		writeMissingParam(w, "tokenID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if !services.ValidBlockFormat(format) {
+		writeError(w, "Invalid 'format' parameter (raw or named)", http.StatusBadRequest)
 		return
 	}
 
@@ -130,9 +210,9 @@ func GetTokenBlocksFromTokenID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch token chain data with pagination
-	chainData, totalBlocks, err := services.GetTokenBlocksFromTokenID(tokenID, page, limit)
+	chainData, totalBlocks, err := services.GetTokenBlocksFromTokenID(tokenID, page, limit, format)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch token chain: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -160,7 +240,7 @@ func GetTokenBlocksFromTokenID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -168,7 +248,7 @@ func GetSCBlockInfoFromTxnHash(w http.ResponseWriter, r *http.Request) {
 
 	hash := r.URL.Query().Get("hash")
 	if hash == "" {
-		http.Error(w, "Missing transaction hash", http.StatusBadRequest)
+		writeMissingParam(w, "hash")
 		return
 	}
 
@@ -176,7 +256,7 @@ func GetSCBlockInfoFromTxnHash(w http.ResponseWriter, r *http.Request) {
 
 	scBlockInfo, err := services.GetSCBlockInfoFromTxnId(hash)
 	if err != nil {
-		http.Error(w, "Failed to fetch SC block info", http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 	response = scBlockInfo
@@ -185,7 +265,7 @@ func GetSCBlockInfoFromTxnHash(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 
 }
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+)
+
+// GetDIDLedgerHandler returns the ledger balances and entries of a DID (?did=&limit=&page=)
+func GetDIDLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	did := r.URL.Query().Get("did")
+	if did == "" {
+		writeMissingParam(w, "did")
+		return
+	}
+	_, limit, page := blockListParams(r)
+	timeFormat, err := parseTimeFormat(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ledger, err := services.GetDIDLedger(did, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, ledger)
+}
+
+// CheckLedgerHandler runs the ledger invariant checker and reconciles the DID totals
+func CheckLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := services.CheckLedgerInvariants()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"explorer-server/services"
+	"net/http"
+	"strconv"
+)
+
+// blockListParams parses ?limit=&page=&txn_type= for the block list endpoints;
+// time range and output format come from parseTimeParams
+func blockListParams(r *http.Request) (string, int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return r.URL.Query().Get("txn_type"), limit, page
+}
+
+// GetMintBlockList lists minted (01), migrated (03) and generation (05) blocks
+func GetMintBlockList(w http.ResponseWriter, r *http.Request) {
+	txnType, limit, page := blockListParams(r)
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := services.GetMintBlockList(txnType, timeRange, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, data)
+}
+
+// GetPledgeBlockList lists pledged (04) and unpledged (06) blocks
+func GetPledgeBlockList(w http.ResponseWriter, r *http.Request) {
+	txnType, limit, page := blockListParams(r)
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := services.GetPledgeBlockList(txnType, timeRange, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, data)
+}
+
+// GetCommitBlockList lists committed (07) and contract-committed (11) blocks
+func GetCommitBlockList(w http.ResponseWriter, r *http.Request) {
+	txnType, limit, page := blockListParams(r)
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := services.GetCommitBlockList(txnType, timeRange, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, data)
+}
+
+// GetPinBlockList lists pinned-as-service (12) blocks
+func GetPinBlockList(w http.ResponseWriter, r *http.Request) {
+	_, limit, page := blockListParams(r)
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := services.GetPinBlockList(timeRange, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, data)
+}
+
+// GetBlockInfo returns a stored block of any type by block hash or transaction ID (?id=)
+func GetBlockInfo(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeMissingParam(w, "id")
+		return
+	}
+	timeFormat, err := parseTimeFormat(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	blockType, data, err := services.GetBlockByHashOrTxnID(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, map[string]interface{}{
+		"id":           id,
+		"block_type":   blockType,
+		"data":         data,
+		"verification": services.GetBlockVerification(id),
+	})
+}
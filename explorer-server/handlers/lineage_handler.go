@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+	"strconv"
+)
+
+// GetTokenLineageHandler returns a token's ancestors and descendants as a tree (?tokenID=&depth=)
+func GetTokenLineageHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("tokenID")
+	if tokenID == "" {
+		writeMissingParam(w, "tokenID")
+		return
+	}
+
+	depth := 0
+	if d := r.URL.Query().Get("depth"); d != "" {
+		var err error
+		if depth, err = strconv.Atoi(d); err != nil || depth < 0 {
+			writeError(w, "Invalid 'depth' parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tree, err := services.GetTokenLineage(tokenID, depth)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
@@ -10,7 +10,7 @@ import (
 func GetNFTsCountHandler(w http.ResponseWriter, r *http.Request) {
 	count, err := services.GetNFTCount()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -18,7 +18,7 @@ func GetNFTsCountHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 
 }
@@ -28,7 +28,7 @@ func GetNFTInfoFromNFTID(w http.ResponseWriter, r *http.Request) {
 	println("NFT ID:", nftId)
 	nftInfo, err := services.GetNFTInfoFromNFTID(nftId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -36,7 +36,7 @@ func GetNFTInfoFromNFTID(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"explorer-server/services"
+	"net/http"
+)
+
+// GetTokenOwnershipHandler lists who held a token over time (?tokenID=&from=&to=&limit=&page=)
+func GetTokenOwnershipHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("tokenID")
+	if tokenID == "" {
+		writeMissingParam(w, "tokenID")
+		return
+	}
+	_, limit, page := blockListParams(r)
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, count, err := services.GetTokenOwnershipHistory(tokenID, timeRange, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, map[string]interface{}{
+		"token_id": tokenID,
+		"history":  history,
+		"count":    count,
+	})
+}
+
+// GetDIDHeldTokensHandler lists every token a DID ever held (?did=&limit=&page=)
+func GetDIDHeldTokensHandler(w http.ResponseWriter, r *http.Request) {
+	did := r.URL.Query().Get("did")
+	if did == "" {
+		writeMissingParam(w, "did")
+		return
+	}
+	_, limit, page := blockListParams(r)
+	timeFormat, err := parseTimeFormat(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tokens, count, err := services.GetTokensHeldByDID(did, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, map[string]interface{}{
+		"did":    did,
+		"tokens": tokens,
+		"count":  count,
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"explorer-server/services"
+	"net/http"
+)
+
+// GetQuorumStatsHandler lists quorum DIDs with their validation counts and pledged totals
+func GetQuorumStatsHandler(w http.ResponseWriter, r *http.Request) {
+	_, limit, page := blockListParams(r)
+	timeFormat, err := parseTimeFormat(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, count, err := services.GetQuorumStats(limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, map[string]interface{}{
+		"quorums": stats,
+		"count":   count,
+	})
+}
+
+// GetQuorumVolumeHandler returns pledged volume over time (?did=&interval=hour|day|week|month&from=&to=)
+func GetQuorumVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	points, err := services.GetQuorumPledgeVolume(q.Get("did"), q.Get("interval"), timeRange)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, map[string]interface{}{
+		"did":    q.Get("did"),
+		"volume": points,
+	})
+}
+
+// GetQuorumTransactionsHandler lists the transactions a quorum validated (?did=&limit=&page=)
+func GetQuorumTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	did := r.URL.Query().Get("did")
+	if did == "" {
+		writeMissingParam(w, "did")
+		return
+	}
+	_, limit, page := blockListParams(r)
+	timeFormat, err := parseTimeFormat(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	txns, count, err := services.GetQuorumTransactions(did, limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeTimedJSON(w, timeFormat, map[string]interface{}{
+		"did":          did,
+		"transactions": txns,
+		"count":        count,
+	})
+}
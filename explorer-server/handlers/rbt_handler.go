@@ -10,7 +10,7 @@ import (
 func GetRBTCountHandler(w http.ResponseWriter, r *http.Request) {
 	count, err := services.GetRBTCount()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -18,7 +18,7 @@ func GetRBTCountHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -27,7 +27,7 @@ func GetRBTInfoFromRBTID(w http.ResponseWriter, r *http.Request) {
 	println("RBT ID:", rbtId)
 	rbtInfo, err := services.GetRBTInfoFromRBTID(rbtId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -35,7 +35,7 @@ func GetRBTInfoFromRBTID(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -57,14 +57,14 @@ func GetRBTListHandler(w http.ResponseWriter, r *http.Request) {
 	// Fetch data using service
 	data, err := services.GetRBTList(limit, page)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	// Send JSON response
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -12,7 +12,7 @@ import (
 func GetSCsCountHandler(w http.ResponseWriter, r *http.Request) {
 	count, err := services.GetSCCount()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -20,7 +20,7 @@ func GetSCsCountHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -29,7 +29,7 @@ func GetSmartContractInfoFromSCID(w http.ResponseWriter, r *http.Request) {
 	println("SCID:", scid)
 	scInfo, err := services.GetSCInfoFromSCID(scid)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -37,7 +37,7 @@ func GetSmartContractInfoFromSCID(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -56,18 +56,21 @@ func GetSCBlockList(w http.ResponseWriter, r *http.Request) {
 		page = 1 // default page
 	}
 
+	timeRange, timeFormat, err := parseTimeParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Fetch data using service
-	data, err := services.GetSCBlockList(limit, page)
+	data, err := services.GetSCBlockList(limit, page, timeRange)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	// Send JSON response
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-	}
+	writeTimedJSON(w, timeFormat, data)
 }
 
 
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+	"time"
+)
+
+// SyncStatusHandler reports progress of the current (or last) token-chain sync run
+func SyncStatusHandler(w http.ResponseWriter, r *http.Request) {
+	progress := services.GetTokenChainSyncProgress()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"timestamp":   time.Now().Format(time.RFC3339),
+		"token_chain": progress,
+	})
+}
+
+// NodeStatusHandler reports health of every configured fullnode upstream
+func NodeStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"upstreams": services.GetNodeUpstreamStatus(),
+	})
+}
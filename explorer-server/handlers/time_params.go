@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"explorer-server/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Output formats of timestamps, chosen with ?time_format=
+const (
+	timeFormatRFC3339 = "rfc3339"
+	timeFormatUnix    = "unix"
+)
+
+var errInvalidTimeFormat = errors.New("invalid 'time_format' parameter: use rfc3339 or unix")
+
+// parseTime accepts unix seconds or an RFC3339 timestamp
+func parseTime(v string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// parseTimeRange parses ?from=&to= (unix seconds or RFC3339); missing bounds are open
+func parseTimeRange(r *http.Request) (services.TimeRange, error) {
+	var tr services.TimeRange
+	for _, p := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &tr.From}, {"to", &tr.To}} {
+		v := r.URL.Query().Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := parseTime(v)
+		if err != nil {
+			return tr, fmt.Errorf("invalid '%s' parameter: use unix seconds or RFC3339", p.name)
+		}
+		*p.dest = &t
+	}
+	return tr, nil
+}
+
+// parseTimeFormat reads ?time_format=rfc3339|unix, defaulting to rfc3339
+func parseTimeFormat(r *http.Request) (string, error) {
+	switch f := r.URL.Query().Get("time_format"); f {
+	case "", timeFormatRFC3339:
+		return timeFormatRFC3339, nil
+	case timeFormatUnix:
+		return timeFormatUnix, nil
+	default:
+		return "", errInvalidTimeFormat
+	}
+}
+
+// parseTimeParams parses the time range and output format shared by the block list endpoints
+func parseTimeParams(r *http.Request) (services.TimeRange, string, error) {
+	tr, err := parseTimeRange(r)
+	if err != nil {
+		return tr, "", err
+	}
+	format, err := parseTimeFormat(r)
+	return tr, format, err
+}
+
+// writeTimedJSON encodes data with its timestamps as RFC3339 strings or unix seconds
+func writeTimedJSON(w http.ResponseWriter, format string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if format != timeFormatUnix {
+		json.NewEncoder(w).Encode(data)
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(unixTimestamps(generic))
+}
+
+// timestampFields are the JSON keys of the time.Time fields in timed responses; only these
+// are converted, so strings that merely look like timestamps are left alone
+var timestampFields = map[string]bool{
+	"at": true, "created_at": true, "updated_at": true, "detected_at": true,
+	"epoch": true, "first_epoch": true, "last_epoch": true, "txn_time": true,
+	"interval_start": true, "interval_end": true, "first_acquired": true, "last_released": true,
+	"locked_at": true, "verified_at": true, "stored_at": true, "processed_at": true,
+	"started_at": true, "finished_at": true, "eta": true, "last_updated": true,
+	"last_synced_at": true, "last_checked_at": true, "next_attempt_at": true, "next_retry_at": true,
+}
+
+// unixTimestamps replaces the RFC3339 values of timestamp fields in decoded JSON with unix seconds
+func unixTimestamps(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if str, ok := item.(string); ok && timestampFields[k] {
+				if t, err := time.Parse(time.RFC3339Nano, str); err == nil {
+					val[k] = t.Unix()
+				}
+				continue
+			}
+			val[k] = unixTimestamps(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = unixTimestamps(item)
+		}
+	}
+	return v
+}
@@ -3,31 +3,38 @@ package handlers
 import (
 	"encoding/json"
 	"explorer-server/services"
+	"io"
 	"log"
 	"net/http"
 )
 
-// TOKEN UPDATE (High Priority) → Worker Pool
+// TOKEN UPDATE (High Priority) → Ingest Inbox → Worker Pool
 func UpdateTokensHandler(w http.ResponseWriter, r *http.Request) {
-	var payload struct {
-		Table     string      `json:"table"`
-		Data      interface{} `json:"data"`
-		Operation string      `json:"operation"`
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, "Failed to read body", http.StatusBadRequest)
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	var payload services.TokenUpdatePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("📥 Received token %s for table %s — queueing", payload.Operation, payload.Table)
-
-	ok := services.EnqueueTokenUpdateTask(payload.Table, payload.Data, payload.Operation)
-	if !ok {
-		log.Println("⚠️ Token worker queue full — executing token update inline")
-		services.UpdateTokens(payload.Table, payload.Data, payload.Operation)
+	id, err := services.EnqueueInboxPayload(services.InboxKindToken, body)
+	if err != nil {
+		log.Printf("❌ Failed to store token update in inbox: %v", err)
+		writeError(w, "Failed to queue token update", http.StatusServiceUnavailable)
+		return
 	}
 
+	log.Printf("📥 Received token %s for table %s — stored as inbox #%d", payload.Operation, payload.Table, id)
+
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"queued","message":"Token update accepted"}`))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "queued",
+		"message":  "Token update accepted",
+		"inbox_id": id,
+	})
 }
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"explorer-server/services"
+	"net/http"
+)
+
+// GetBlockVerificationHandler returns the signature verification of a block (?id=block hash or txn ID)
+func GetBlockVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeMissingParam(w, "id")
+		return
+	}
+
+	v := services.GetBlockVerification(id)
+	if v == nil {
+		writeError(w, "No verification found for: "+id, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// RegisterDIDKeyHandler stores a DID's secp256k1 public key and re-checks the blocks it signed.
+// Body: {"did": "...", "public_key": "<hex or PEM>"}
+func RegisterDIDKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DID       string `json:"did"`
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.DID == "" || req.PublicKey == "" {
+		writeError(w, "'did' and 'public_key' are required", http.StatusBadRequest)
+		return
+	}
+
+	reverified, err := services.RegisterDIDKey(req.DID, req.PublicKey, services.DIDKeySourceAdmin)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"did":               req.DID,
+		"status":            "stored",
+		"blocks_reverified": reverified,
+	})
+}
+
+// GetUnverifiedBlocksHandler lists stored blocks whose signatures could not be checked (?limit=&page=)
+func GetUnverifiedBlocksHandler(w http.ResponseWriter, r *http.Request) {
+	_, limit, page := blockListParams(r)
+
+	rows, count, err := services.ListUnverifiedBlocks(limit, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"unverified": rows,
+		"count":      count,
+	})
+}
@@ -2,16 +2,17 @@ package model
 
 import (
 	"explorer-server/database/models"
+	"explorer-server/decimal"
 	"time"
 )
 
 // TxnAnalytics represents transaction analytics
 type TxnAnalytics struct {
-	IntervalStart time.Time `json:"interval_start" db:"interval_start"`
-	IntervalEnd   time.Time `json:"interval_end" db:"interval_end"`
-	TxnCount      int64     `json:"txn_count" db:"txn_count"`
-	TotalValue    float64   `json:"total_value" db:"total_value"`
-	TokenType     string    `json:"token_type" db:"token_type"`
+	IntervalStart time.Time       `json:"interval_start" db:"interval_start"`
+	IntervalEnd   time.Time       `json:"interval_end" db:"interval_end"`
+	TxnCount      int64           `json:"txn_count" db:"txn_count"`
+	TotalValue    decimal.Decimal `json:"total_value" db:"total_value"`
+	TokenType     string          `json:"token_type" db:"token_type"`
 }
 
 // DatabaseHealth represents the health status of the database
@@ -22,9 +23,9 @@ type DatabaseHealth struct {
 }
 
 type Token struct {
-	TokenId    string  `json:"token_id"`
-	OwnerDID   string  `json:"owner_did"`
-	TokenValue float64 `json:"token_value"`
+	TokenId    string          `json:"token_id"`
+	OwnerDID   string          `json:"owner_did"`
+	TokenValue decimal.Decimal `json:"token_value"`
 }
 
 type TokenResponse struct {
@@ -32,8 +33,8 @@ type TokenResponse struct {
 }
 
 type HolderResponse struct {
-	OwnerDID   string  `json:"owner_did"`
-	TokenCount float64 `json:"token_count"`
+	OwnerDID   string          `json:"owner_did"`
+	TokenCount decimal.Decimal `json:"token_count"`
 	// TotalTransactions int64 `json:"total_transactions"`
 }
 
@@ -43,12 +44,18 @@ type HoldersResponse struct {
 }
 
 type TransactionResponse struct {
-	TxnHash     string  `json:"txn_hash"`
-	TxnType     string  `json:"txn_type"`
-	Amount      float64 `json:"amount"`
-	Epoch       *int64  `json:"txn_time"`
-	SenderDID   string  `json:"sender_did"`
-	ReceiverDID string  `json:"receiver_did"`
+	TxnHash     string          `json:"txn_hash"`
+	TxnType     string          `json:"txn_type"`
+	Amount      decimal.Decimal `json:"amount"`
+	Epoch       *time.Time      `json:"txn_time"`
+	SenderDID   string          `json:"sender_did"`
+	ReceiverDID string          `json:"receiver_did"`
+}
+
+// TransferBlockResponse is a transfer block with the result of checking its signatures
+type TransferBlockResponse struct {
+	models.TransferBlocks
+	Verification *models.BlockVerification `json:"verification"`
 }
 
 type TransactionsResponse struct {
@@ -66,6 +73,26 @@ type BurntBlocksListResponse struct {
 	Count       int64                `json:"count"`
 }
 
+type MintBlocksListResponse struct {
+	MintBlocks []models.MintBlocks `json:"mintblocks"`
+	Count      int64               `json:"count"`
+}
+
+type PledgeBlocksListResponse struct {
+	PledgeBlocks []models.PledgeBlocks `json:"pledgeblocks"`
+	Count        int64                 `json:"count"`
+}
+
+type CommitBlocksListResponse struct {
+	CommitBlocks []models.CommitBlocks `json:"commitblocks"`
+	Count        int64                 `json:"count"`
+}
+
+type PinBlocksListResponse struct {
+	PinBlocks []models.PinBlocks `json:"pinblocks"`
+	Count     int64              `json:"count"`
+}
+
 type RBTListResponse struct {
 	Tokens []Token `json:"tokens"`
 	Count  int64   `json:"count"`
@@ -0,0 +1,541 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+// Memory keeps every aggregate in maps. It follows the Postgres semantics of each
+// repository method so services can be exercised in tests without a database.
+type Memory struct {
+	mu sync.RWMutex
+
+	rbts       map[string]models.RBT
+	fts        map[string]models.FT
+	nfts       map[string]models.NFT
+	tokenTypes map[string]models.TokenType
+
+	dids    map[string]models.DIDs
+	didKeys map[string]models.DIDKey
+
+	contracts map[string]models.SmartContract
+	scBlocks  map[string]models.SC_Block
+
+	allBlocks     map[string]models.AllBlocks
+	transfers     map[string]models.TransferBlocks
+	burnts        map[string]models.BurntBlocks
+	mints         map[string]models.MintBlocks
+	pledges       map[string]models.PledgeBlocks
+	commits       map[string]models.CommitBlocks
+	pins          map[string]models.PinBlocks
+	verifications map[string]models.BlockVerification
+	chainBlocks   map[[2]string]models.TokenChainBlock
+
+	quorumPledges map[[3]string]models.QuorumPledge
+	lineage       map[[3]string]models.TokenLineage
+	ownership     map[[2]string]models.TokenOwnership
+	ledger        map[string][]models.LedgerEntry
+	unvalued      map[string][]string
+	nextID        uint
+
+	checkpoints map[string]models.TokenSyncCheckpoint
+	inbox       map[uint64]models.IngestInbox
+	failedSyncs map[string]models.FailedTokenSync
+	divergences []models.ChainDivergence
+	nextInboxID uint64
+}
+
+// NewMemory returns empty repositories that share one in-memory store
+func NewMemory() Repositories {
+	m := &Memory{
+		rbts:          map[string]models.RBT{},
+		fts:           map[string]models.FT{},
+		nfts:          map[string]models.NFT{},
+		tokenTypes:    map[string]models.TokenType{},
+		dids:          map[string]models.DIDs{},
+		didKeys:       map[string]models.DIDKey{},
+		contracts:     map[string]models.SmartContract{},
+		scBlocks:      map[string]models.SC_Block{},
+		allBlocks:     map[string]models.AllBlocks{},
+		transfers:     map[string]models.TransferBlocks{},
+		burnts:        map[string]models.BurntBlocks{},
+		mints:         map[string]models.MintBlocks{},
+		pledges:       map[string]models.PledgeBlocks{},
+		commits:       map[string]models.CommitBlocks{},
+		pins:          map[string]models.PinBlocks{},
+		verifications: map[string]models.BlockVerification{},
+		chainBlocks:   map[[2]string]models.TokenChainBlock{},
+		quorumPledges: map[[3]string]models.QuorumPledge{},
+		lineage:       map[[3]string]models.TokenLineage{},
+		ownership:     map[[2]string]models.TokenOwnership{},
+		ledger:        map[string][]models.LedgerEntry{},
+		unvalued:      map[string][]string{},
+		checkpoints:   map[string]models.TokenSyncCheckpoint{},
+		inbox:         map[uint64]models.IngestInbox{},
+		failedSyncs:   map[string]models.FailedTokenSync{},
+	}
+	return Repositories{Tokens: m, Blocks: m, DIDs: m, SmartContracts: m, Analytics: m, Ledger: m, Sync: m}
+}
+
+// get returns a copy of the row stored under key
+func get[T any](rows map[string]T, key string) (*T, error) {
+	row, ok := rows[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &row, nil
+}
+
+// create stores a new row, failing like a primary key violation when the key is taken
+func create[T any](rows map[string]T, key string, row T) error {
+	if _, ok := rows[key]; ok {
+		return fmt.Errorf("duplicate key value %q", key)
+	}
+	rows[key] = row
+	return nil
+}
+
+// sortedKeys returns the keys of a map in ascending order
+func sortedKeys[T any](rows map[string]T) []string {
+	keys := make([]string, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pageOf cuts one page out of rows
+func pageOf[T any](rows []T, page Page) []T {
+	if page.Offset >= len(rows) {
+		return nil
+	}
+	rows = rows[page.Offset:]
+	if page.Limit > 0 && page.Limit < len(rows) {
+		rows = rows[:page.Limit]
+	}
+	return rows
+}
+
+// newestFirst orders rows by epoch descending with missing epochs last, then by key
+func newestFirst(ai, aj *time.Time, ki, kj string) bool {
+	switch {
+	case ai == nil && aj == nil:
+		return ki < kj
+	case ai == nil:
+		return false
+	case aj == nil:
+		return true
+	case !ai.Equal(*aj):
+		return ai.After(*aj)
+	default:
+		return ki < kj
+	}
+}
+
+// ========================= Tokens =========================
+
+func (m *Memory) CountRBTs() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.rbts)), nil
+}
+
+func (m *Memory) GetRBT(tokenID string) (*models.RBT, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.rbts, tokenID)
+}
+
+func (m *Memory) ListRBTs(page Page) ([]models.RBT, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rbts []models.RBT
+	for _, id := range sortedKeys(m.rbts) {
+		rbts = append(rbts, m.rbts[id])
+	}
+	return pageOf(rbts, page), nil
+}
+
+func (m *Memory) ListFreeRBTs(ownerDID string, page Page) ([]models.RBT, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rbts []models.RBT
+	for _, id := range sortedKeys(m.rbts) {
+		if r := m.rbts[id]; r.OwnerDID == ownerDID && r.TokenStatus == 0 {
+			rbts = append(rbts, r)
+		}
+	}
+	return pageOf(rbts, page), int64(len(rbts)), nil
+}
+
+func (m *Memory) RBTValues(tokenIDs []string) (map[string]decimal.Decimal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+	values := map[string]decimal.Decimal{}
+	for _, id := range tokenIDs {
+		if r, ok := m.rbts[id]; ok {
+			values[id] = r.TokenValue
+		}
+	}
+	return values, nil
+}
+
+func (m *Memory) CreateRBT(rbt *models.RBT) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return create(m.rbts, rbt.TokenID, *rbt)
+}
+
+func (m *Memory) SaveRBT(rbt *models.RBT) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rbts[rbt.TokenID] = *rbt
+	return nil
+}
+
+func (m *Memory) DeleteRBT(tokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rbts, tokenID)
+	return nil
+}
+
+func (m *Memory) CountFTs() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.fts)), nil
+}
+
+func (m *Memory) GetFT(ftID string) (*models.FT, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.fts, ftID)
+}
+
+func (m *Memory) ListFTsByOwner(ownerDID string) ([]models.FT, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var fts []models.FT
+	for _, id := range sortedKeys(m.fts) {
+		if ft := m.fts[id]; ft.OwnerDID == ownerDID {
+			fts = append(fts, ft)
+		}
+	}
+	return fts, nil
+}
+
+func (m *Memory) CreateFT(ft *models.FT) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return create(m.fts, ft.FtID, *ft)
+}
+
+func (m *Memory) SaveFT(ft *models.FT) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fts[ft.FtID] = *ft
+	return nil
+}
+
+func (m *Memory) DeleteFT(ftID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.fts, ftID)
+	return nil
+}
+
+func (m *Memory) CountNFTs() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.nfts)), nil
+}
+
+func (m *Memory) GetNFT(nftID string) (*models.NFT, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.nfts, nftID)
+}
+
+func (m *Memory) CreateNFT(nft *models.NFT) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return create(m.nfts, nft.TokenID, *nft)
+}
+
+func (m *Memory) SaveNFT(nft *models.NFT) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nfts[nft.TokenID] = *nft
+	return nil
+}
+
+func (m *Memory) DeleteNFT(nftID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nfts, nftID)
+	return nil
+}
+
+func (m *Memory) GetTokenType(tokenID string) (*models.TokenType, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.tokenTypes, tokenID)
+}
+
+func (m *Memory) ListTokenTypes() ([]models.TokenType, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tokens []models.TokenType
+	for _, id := range sortedKeys(m.tokenTypes) {
+		tokens = append(tokens, m.tokenTypes[id])
+	}
+	return tokens, nil
+}
+
+func (m *Memory) TokenTypes(tokenIDs []string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+	types := map[string]string{}
+	for _, id := range tokenIDs {
+		if t, ok := m.tokenTypes[id]; ok {
+			types[id] = t.TokenType
+		}
+	}
+	return types, nil
+}
+
+func (m *Memory) EnsureTokenType(tokenType *models.TokenType) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.tokenTypes[tokenType.TokenID]; ok {
+		*tokenType = existing
+		return nil
+	}
+	m.tokenTypes[tokenType.TokenID] = *tokenType
+	return nil
+}
+
+func (m *Memory) DeleteTokenType(tokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokenTypes, tokenID)
+	return nil
+}
+
+// ========================= DIDs =========================
+
+func (m *Memory) CountDIDs() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.dids)), nil
+}
+
+func (m *Memory) GetDID(did string) (*models.DIDs, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.dids, did)
+}
+
+func (m *Memory) ListRBTHolders(page Page) ([]models.DIDs, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var dids []models.DIDs
+	for _, d := range m.dids {
+		if d.DID != "" && d.DID != "0" {
+			dids = append(dids, d)
+		}
+	}
+	sort.Slice(dids, func(i, j int) bool {
+		if c := dids[i].TotalRBTs.Cmp(dids[j].TotalRBTs); c != 0 {
+			return c > 0
+		}
+		return dids[i].DID < dids[j].DID
+	})
+	return pageOf(dids, page), nil
+}
+
+func (m *Memory) CreateDID(did *models.DIDs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return create(m.dids, did.DID, *did)
+}
+
+func (m *Memory) SaveDID(did *models.DIDs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dids[did.DID] = *did
+	return nil
+}
+
+func (m *Memory) ListDIDs(page Page) ([]models.DIDs, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var dids []models.DIDs
+	for _, did := range sortedKeys(m.dids) {
+		dids = append(dids, m.dids[did])
+	}
+	return pageOf(dids, page), nil
+}
+
+func (m *Memory) DerivedDIDTotals(dids []string) (map[string]models.DIDs, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.derivedDIDTotals(dids), nil
+}
+
+func (m *Memory) RecomputeDIDs(dids []string) ([]models.DIDs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	derived := m.derivedDIDTotals(dids)
+	var written []models.DIDs
+	for _, did := range sortedKeys(derived) {
+		row := derived[did]
+		if existing, ok := m.dids[did]; ok {
+			row.CreatedAt = existing.CreatedAt
+		} else if isEmptyDID(row) {
+			continue
+		} else {
+			row.CreatedAt = time.Now()
+		}
+		m.dids[did] = row
+		written = append(written, row)
+	}
+	return written, nil
+}
+
+// derivedDIDTotals sums the token tables per DID; the caller holds the lock
+func (m *Memory) derivedDIDTotals(dids []string) map[string]models.DIDs {
+	totals := map[string]models.DIDs{}
+	wanted := map[string]bool{}
+	for _, did := range dids {
+		wanted[did] = true
+		totals[did] = models.DIDs{DID: did}
+	}
+	add := func(did string, fn func(*models.DIDs)) {
+		if dids != nil && !wanted[did] {
+			return
+		}
+		row := totals[did]
+		row.DID = did
+		fn(&row)
+		totals[did] = row
+	}
+
+	for _, rbt := range m.rbts {
+		if rbt.TokenStatus == 0 {
+			add(rbt.OwnerDID, func(d *models.DIDs) { d.TotalRBTs = d.TotalRBTs.Add(rbt.TokenValue) })
+		}
+	}
+	for _, ft := range m.fts {
+		if ft.TokenStatus == 0 {
+			add(ft.OwnerDID, func(d *models.DIDs) { d.TotalFTs = d.TotalFTs.Add(decimal.FromInt(1)) })
+		}
+	}
+	for _, nft := range m.nfts {
+		add(nft.OwnerDID, func(d *models.DIDs) { d.TotalNFTs++ })
+	}
+	for _, sc := range m.contracts {
+		add(sc.DeployerDID, func(d *models.DIDs) { d.TotalSC++ })
+	}
+	return totals
+}
+
+func (m *Memory) SaveDIDKey(key *models.DIDKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.didKeys[key.DID] = *key
+	return nil
+}
+
+func (m *Memory) PublicKeys(dids []string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(dids) == 0 {
+		return nil, nil
+	}
+	keys := map[string]string{}
+	for _, did := range dids {
+		if k, ok := m.didKeys[did]; ok {
+			keys[did] = k.PublicKey
+		}
+	}
+	return keys, nil
+}
+
+// ========================= Smart contracts =========================
+
+func (m *Memory) CountSmartContracts() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.contracts)), nil
+}
+
+func (m *Memory) GetSmartContract(contractID string) (*models.SmartContract, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.contracts, contractID)
+}
+
+func (m *Memory) CreateSmartContract(sc *models.SmartContract) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return create(m.contracts, sc.ContractID, *sc)
+}
+
+func (m *Memory) SaveSmartContract(sc *models.SmartContract) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contracts[sc.ContractID] = *sc
+	return nil
+}
+
+func (m *Memory) DeleteSmartContract(contractID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.contracts, contractID)
+	return nil
+}
+
+func (m *Memory) SaveSCBlock(block *models.SC_Block) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scBlocks[block.Block_ID] = *block
+	return nil
+}
+
+func (m *Memory) GetSCBlock(blockID string) (*models.SC_Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.scBlocks, blockID)
+}
+
+func (m *Memory) ListSCBlocks(timeRange TimeRange, page Page) ([]models.SC_Block, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.scBlocks, func(b models.SC_Block) (*time.Time, bool) {
+		return b.Epoch, true
+	}, timeRange, page)
+}
@@ -0,0 +1,472 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+// TruncateInterval mirrors Postgres date_trunc in UTC for the volume intervals
+func TruncateInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.UTC)
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// pledgeTotals accumulates the aggregates shared by the quorum queries
+type pledgeTotals struct {
+	blocks map[string]bool
+	tokens int64
+	value  decimal.Decimal
+	first  *time.Time
+	last   *time.Time
+}
+
+func (t *pledgeTotals) add(p models.QuorumPledge) {
+	if t.blocks == nil {
+		t.blocks = map[string]bool{}
+	}
+	t.blocks[p.BlockHash] = true
+	t.tokens++
+	if p.PledgedValue != nil {
+		t.value = t.value.Add(*p.PledgedValue)
+	}
+	if p.Epoch != nil {
+		if t.first == nil || p.Epoch.Before(*t.first) {
+			t.first = p.Epoch
+		}
+		if t.last == nil || p.Epoch.After(*t.last) {
+			t.last = p.Epoch
+		}
+	}
+}
+
+func (m *Memory) SaveQuorumPledges(rows []models.QuorumPledge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, row := range rows {
+		key := [3]string{row.BlockHash, row.QuorumDID, row.PledgedToken}
+		if existing, ok := m.quorumPledges[key]; ok {
+			existing.TxnID, existing.TxnType = row.TxnID, row.TxnType
+			existing.PledgedValue, existing.Epoch = row.PledgedValue, row.Epoch
+			m.quorumPledges[key] = existing
+			continue
+		}
+		m.nextID++
+		row.ID = m.nextID
+		m.quorumPledges[key] = row
+	}
+	return nil
+}
+
+func (m *Memory) QuorumStats(page Page) ([]QuorumStats, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byQuorum := map[string]*pledgeTotals{}
+	for _, p := range m.quorumPledges {
+		if byQuorum[p.QuorumDID] == nil {
+			byQuorum[p.QuorumDID] = &pledgeTotals{}
+		}
+		byQuorum[p.QuorumDID].add(p)
+	}
+
+	stats := make([]QuorumStats, 0, len(byQuorum))
+	for did, t := range byQuorum {
+		stats = append(stats, QuorumStats{
+			QuorumDID:     did,
+			Validations:   int64(len(t.blocks)),
+			PledgedTokens: t.tokens,
+			PledgedValue:  t.value,
+			FirstEpoch:    t.first,
+			LastEpoch:     t.last,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Validations != stats[j].Validations {
+			return stats[i].Validations > stats[j].Validations
+		}
+		return stats[i].QuorumDID < stats[j].QuorumDID
+	})
+	return pageOf(stats, page), int64(len(stats)), nil
+}
+
+func (m *Memory) QuorumVolume(quorumDID, interval string, timeRange TimeRange) ([]QuorumVolumePoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byBucket := map[time.Time]*pledgeTotals{}
+	for _, p := range m.quorumPledges {
+		if p.Epoch == nil || !timeRange.Contains(p.Epoch) {
+			continue
+		}
+		if quorumDID != "" && p.QuorumDID != quorumDID {
+			continue
+		}
+		bucket := TruncateInterval(*p.Epoch, interval)
+		if byBucket[bucket] == nil {
+			byBucket[bucket] = &pledgeTotals{}
+		}
+		byBucket[bucket].add(p)
+	}
+
+	points := make([]QuorumVolumePoint, 0, len(byBucket))
+	for start, t := range byBucket {
+		points = append(points, QuorumVolumePoint{
+			IntervalStart: start,
+			Validations:   int64(len(t.blocks)),
+			PledgedTokens: t.tokens,
+			PledgedValue:  t.value,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].IntervalStart.Before(points[j].IntervalStart) })
+	return points, nil
+}
+
+func (m *Memory) QuorumTransactions(quorumDID string, page Page) ([]QuorumTransaction, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byBlock := map[string]*QuorumTransaction{}
+	for _, p := range m.quorumPledges {
+		if p.QuorumDID != quorumDID {
+			continue
+		}
+		txn := byBlock[p.BlockHash]
+		if txn == nil {
+			txn = &QuorumTransaction{BlockHash: p.BlockHash, TxnID: p.TxnID, TxnType: p.TxnType, Epoch: p.Epoch}
+			if tb, ok := m.transfers[p.BlockHash]; ok {
+				txn.SenderDID, txn.ReceiverDID, txn.Amount = tb.SenderDID, tb.ReceiverDID, tb.Amount
+			}
+			byBlock[p.BlockHash] = txn
+		}
+		txn.PledgedTokens++
+		if p.PledgedValue != nil {
+			txn.PledgedValue = txn.PledgedValue.Add(*p.PledgedValue)
+		}
+	}
+
+	txns := make([]QuorumTransaction, 0, len(byBlock))
+	for _, txn := range byBlock {
+		txns = append(txns, *txn)
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		return newestFirst(txns[i].Epoch, txns[j].Epoch, txns[i].BlockHash, txns[j].BlockHash)
+	})
+	return pageOf(txns, page), int64(len(txns)), nil
+}
+
+func (m *Memory) SaveTokenLineage(rows []models.TokenLineage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, row := range rows {
+		key := [3]string{row.TokenID, row.RelatedTokenID, row.Relation}
+		if existing, ok := m.lineage[key]; ok {
+			if row.TokenValue != nil {
+				existing.TokenValue = row.TokenValue
+				m.lineage[key] = existing
+			}
+			continue
+		}
+		m.nextID++
+		row.ID = m.nextID
+		m.lineage[key] = row
+	}
+	return nil
+}
+
+// lineageWhere returns the lineage rows keep selects, ordered by the given key
+func (m *Memory) lineageWhere(keep func(models.TokenLineage) bool, orderKey func(models.TokenLineage) string) []models.TokenLineage {
+	var rows []models.TokenLineage
+	for _, row := range m.lineage {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if ki, kj := orderKey(rows[i]), orderKey(rows[j]); ki != kj {
+			return ki < kj
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return rows
+}
+
+func (m *Memory) LineageOf(tokenIDs []string) ([]models.TokenLineage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := stringSet(tokenIDs)
+	return m.lineageWhere(
+		func(r models.TokenLineage) bool { return ids[r.TokenID] },
+		func(r models.TokenLineage) string { return r.RelatedTokenID },
+	), nil
+}
+
+func (m *Memory) LineageChildren(parentIDs []string) ([]models.TokenLineage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := stringSet(parentIDs)
+	return m.lineageWhere(
+		func(r models.TokenLineage) bool { return ids[r.RelatedTokenID] && r.Relation == LineageParent },
+		func(r models.TokenLineage) string { return r.TokenID },
+	), nil
+}
+
+func (m *Memory) SaveOwnershipEvents(rows []models.TokenOwnership) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, row := range rows {
+		key := [2]string{row.TokenID, row.BlockHash}
+		if existing, ok := m.ownership[key]; ok {
+			row.ID = existing.ID
+		} else {
+			m.nextID++
+			row.ID = m.nextID
+		}
+		m.ownership[key] = row
+	}
+	return nil
+}
+
+// earlierEpoch orders by epoch ascending with missing epochs last; ties report ok false
+func earlierEpoch(a, b *time.Time) (less, ok bool) {
+	switch {
+	case a == nil && b == nil:
+		return false, false
+	case a == nil:
+		return false, true
+	case b == nil:
+		return true, true
+	case a.Equal(*b):
+		return false, false
+	default:
+		return a.Before(*b), true
+	}
+}
+
+func (m *Memory) TokenOwnershipHistory(tokenID string, timeRange TimeRange, page Page) ([]models.TokenOwnership, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rows []models.TokenOwnership
+	for _, row := range m.ownership {
+		if row.TokenID != tokenID {
+			continue
+		}
+		if !timeRange.Contains(row.Epoch) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if less, ok := earlierEpoch(rows[i].Epoch, rows[j].Epoch); ok {
+			return less
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return pageOf(rows, page), int64(len(rows)), nil
+}
+
+func (m *Memory) OwnershipEventsUntil(did string, until time.Time) ([]models.TokenOwnership, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rows []models.TokenOwnership
+	for _, row := range m.ownership {
+		received := row.ToDID != nil && *row.ToDID == did
+		gave := row.FromDID != nil && *row.FromDID == did
+		if row.Epoch == nil || !(received || gave) || row.Epoch.After(until) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Epoch.Equal(*rows[j].Epoch) {
+			return rows[i].Epoch.Before(*rows[j].Epoch)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return rows, nil
+}
+
+func (m *Memory) TokensHeldBy(did string, page Page) ([]HeldToken, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byToken := map[string]*HeldToken{}
+	for _, row := range m.ownership {
+		received := row.ToDID != nil && *row.ToDID == did
+		gave := row.FromDID != nil && *row.FromDID == did
+		if !received && !gave {
+			continue
+		}
+		held := byToken[row.TokenID]
+		if held == nil {
+			held = &HeldToken{TokenID: row.TokenID}
+			byToken[row.TokenID] = held
+		}
+		if received {
+			held.Acquisitions++
+			if row.Epoch != nil && (held.FirstAcquired == nil || row.Epoch.Before(*held.FirstAcquired)) {
+				held.FirstAcquired = row.Epoch
+			}
+		}
+		if gave {
+			held.Releases++
+			if row.Epoch != nil && (held.LastReleased == nil || row.Epoch.After(*held.LastReleased)) {
+				held.LastReleased = row.Epoch
+			}
+		}
+	}
+
+	tokens := make([]HeldToken, 0, len(byToken))
+	for _, held := range byToken {
+		held.Holding = held.Acquisitions > held.Releases
+		tokens = append(tokens, *held)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if less, ok := earlierEpoch(tokens[i].FirstAcquired, tokens[j].FirstAcquired); ok {
+			return less
+		}
+		return tokens[i].TokenID < tokens[j].TokenID
+	})
+	return pageOf(tokens, page), int64(len(tokens)), nil
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// hasJSON reports whether a JSON column holds something other than null or an empty value
+func hasJSON(raw []byte, empty string) bool {
+	s := string(raw)
+	return s != "" && s != "null" && s != empty
+}
+
+// inBatches hands rows to fn batchSize at a time; it runs without the lock so fn can write back
+func inBatches[T any](rows []T, batchSize int, fn func([]T) error) error {
+	for len(rows) > 0 {
+		n := batchSize
+		if n < 1 || n > len(rows) {
+			n = len(rows)
+		}
+		if err := fn(rows[:n]); err != nil {
+			return err
+		}
+		rows = rows[n:]
+	}
+	return nil
+}
+
+// blockHashesWith collects the block hashes a set of derived rows was built from
+func blockHashesWith[K comparable, T any](rows map[K]T, hash func(T) string) map[string]bool {
+	hashes := map[string]bool{}
+	for _, row := range rows {
+		hashes[hash(row)] = true
+	}
+	return hashes
+}
+
+func (m *Memory) EachTransferWithoutPledges(batchSize int, fn func([]models.TransferBlocks) error) error {
+	m.mu.RLock()
+	done := blockHashesWith(m.quorumPledges, func(p models.QuorumPledge) string { return p.BlockHash })
+	var pending []models.TransferBlocks
+	for _, hash := range sortedKeys(m.transfers) {
+		if tb := m.transfers[hash]; hasJSON(tb.ValidatorPledgeMap, "{}") && !done[hash] {
+			pending = append(pending, tb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachMintWithoutLineage(batchSize int, fn func([]models.MintBlocks) error) error {
+	m.mu.RLock()
+	done := blockHashesWith(m.lineage, func(l models.TokenLineage) string { return l.BlockHash })
+	var pending []models.MintBlocks
+	for _, hash := range sortedKeys(m.mints) {
+		if mb := m.mints[hash]; hasJSON(mb.GenesisBlock, "null") && !done[hash] {
+			pending = append(pending, mb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachBurntWithoutLineage(batchSize int, fn func([]models.BurntBlocks) error) error {
+	m.mu.RLock()
+	done := blockHashesWith(m.lineage, func(l models.TokenLineage) string { return l.BlockHash })
+	var pending []models.BurntBlocks
+	for _, hash := range sortedKeys(m.burnts) {
+		if bb := m.burnts[hash]; hasJSON(bb.ChildTokens, "[]") && !done[hash] {
+			pending = append(pending, bb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachTransferWithoutOwnership(batchSize int, fn func([]models.TransferBlocks) error) error {
+	m.mu.RLock()
+	done := blockHashesWith(m.ownership, func(o models.TokenOwnership) string { return o.BlockHash })
+	var pending []models.TransferBlocks
+	for _, hash := range sortedKeys(m.transfers) {
+		if tb := m.transfers[hash]; hasJSON(tb.Tokens, "{}") && !done[hash] {
+			pending = append(pending, tb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachMintWithoutOwnership(batchSize int, fn func([]models.MintBlocks) error) error {
+	m.mu.RLock()
+	done := blockHashesWith(m.ownership, func(o models.TokenOwnership) string { return o.BlockHash })
+	var pending []models.MintBlocks
+	for _, hash := range sortedKeys(m.mints) {
+		if mb := m.mints[hash]; hasJSON(mb.Tokens, "{}") && !done[hash] {
+			pending = append(pending, mb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachBurntWithoutOwnership(batchSize int, fn func([]models.BurntBlocks) error) error {
+	m.mu.RLock()
+	done := blockHashesWith(m.ownership, func(o models.TokenOwnership) string { return o.BlockHash })
+	var pending []models.BurntBlocks
+	for _, hash := range sortedKeys(m.burnts) {
+		if bb := m.burnts[hash]; hasJSON(bb.Tokens, "{}") && !done[hash] {
+			pending = append(pending, bb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
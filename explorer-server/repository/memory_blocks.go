@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+// memListBlocks pages the rows that inspect keeps and whose epoch is in the range, newest first
+func memListBlocks[T any](rows map[string]T, inspect func(T) (*time.Time, bool), timeRange TimeRange, page Page) ([]T, int64, error) {
+	type entry struct {
+		key   string
+		epoch *time.Time
+		row   T
+	}
+
+	var matched []entry
+	for key, row := range rows {
+		epoch, keep := inspect(row)
+		if keep && timeRange.Contains(epoch) {
+			matched = append(matched, entry{key, epoch, row})
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return newestFirst(matched[i].epoch, matched[j].epoch, matched[i].key, matched[j].key)
+	})
+
+	out := make([]T, 0, len(matched))
+	for _, e := range pageOf(matched, page) {
+		out = append(out, e.row)
+	}
+	return out, int64(len(matched)), nil
+}
+
+// withTxnType keeps rows of the given txn type, or every row when txnType is empty
+func withTxnType(txnType, rowType string) bool {
+	return txnType == "" || txnType == rowType
+}
+
+func (m *Memory) IndexBlock(entry *models.AllBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.allBlocks[entry.BlockHash]; !ok {
+		m.allBlocks[entry.BlockHash] = *entry
+	}
+	return nil
+}
+
+func (m *Memory) FindIndexedBlock(id string) (*models.AllBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if entry, ok := m.allBlocks[id]; ok {
+		return &entry, nil
+	}
+	for _, hash := range sortedKeys(m.allBlocks) {
+		if entry := m.allBlocks[hash]; entry.TxnID == id {
+			return &entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *Memory) BlockTypeOfTxn(txnID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, hash := range sortedKeys(m.allBlocks) {
+		if entry := m.allBlocks[hash]; entry.TxnID == txnID {
+			return entry.BlockType, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *Memory) CountTransferBlocks() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.transfers)), nil
+}
+
+func (m *Memory) SaveTransferBlock(block *models.TransferBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transfers[block.BlockHash] = *block
+	return nil
+}
+
+func (m *Memory) GetTransferBlock(blockHash string) (*models.TransferBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.transfers, blockHash)
+}
+
+func (m *Memory) GetTransferBlockByTxnID(txnID string) (*models.TransferBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, hash := range sortedKeys(m.transfers) {
+		if b := m.transfers[hash]; b.TxnID != nil && *b.TxnID == txnID {
+			return &b, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *Memory) ListTransferBlocks(timeRange TimeRange, page Page) ([]models.TransferBlocks, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.transfers, func(b models.TransferBlocks) (*time.Time, bool) {
+		return b.Epoch, b.Epoch != nil
+	}, timeRange, page)
+}
+
+func (m *Memory) ListTransferBlocksWithoutAmount() ([]models.TransferBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var blocks []models.TransferBlocks
+	for _, hash := range sortedKeys(m.transfers) {
+		if b := m.transfers[hash]; b.Amount == nil || b.Amount.IsZero() {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks, nil
+}
+
+func (m *Memory) UpdateTransferAmount(blockHash string, amount decimal.Decimal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b, ok := m.transfers[blockHash]; ok {
+		b.Amount = &amount
+		m.transfers[blockHash] = b
+	}
+	return nil
+}
+
+func (m *Memory) SaveBurntBlock(block *models.BurntBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.burnts[block.BlockHash] = *block
+	return nil
+}
+
+func (m *Memory) GetBurntBlock(blockHash string) (*models.BurntBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.burnts, blockHash)
+}
+
+func (m *Memory) ListBurntBlocks(timeRange TimeRange, page Page) ([]models.BurntBlocks, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.burnts, func(b models.BurntBlocks) (*time.Time, bool) {
+		return b.Epoch, true
+	}, timeRange, page)
+}
+
+func (m *Memory) SaveMintBlock(block *models.MintBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mints[block.BlockHash] = *block
+	return nil
+}
+
+func (m *Memory) GetMintBlock(blockHash string) (*models.MintBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.mints, blockHash)
+}
+
+func (m *Memory) ListMintBlocks(txnType string, timeRange TimeRange, page Page) ([]models.MintBlocks, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.mints, func(b models.MintBlocks) (*time.Time, bool) {
+		return b.Epoch, withTxnType(txnType, b.TxnType)
+	}, timeRange, page)
+}
+
+func (m *Memory) SavePledgeBlock(block *models.PledgeBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pledges[block.BlockHash] = *block
+	return nil
+}
+
+func (m *Memory) GetPledgeBlock(blockHash string) (*models.PledgeBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.pledges, blockHash)
+}
+
+func (m *Memory) ListPledgeBlocks(txnType string, timeRange TimeRange, page Page) ([]models.PledgeBlocks, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.pledges, func(b models.PledgeBlocks) (*time.Time, bool) {
+		return b.Epoch, withTxnType(txnType, b.TxnType)
+	}, timeRange, page)
+}
+
+func (m *Memory) SaveCommitBlock(block *models.CommitBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commits[block.BlockHash] = *block
+	return nil
+}
+
+func (m *Memory) GetCommitBlock(blockHash string) (*models.CommitBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.commits, blockHash)
+}
+
+func (m *Memory) ListCommitBlocks(txnType string, timeRange TimeRange, page Page) ([]models.CommitBlocks, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.commits, func(b models.CommitBlocks) (*time.Time, bool) {
+		return b.Epoch, withTxnType(txnType, b.TxnType)
+	}, timeRange, page)
+}
+
+func (m *Memory) SavePinBlock(block *models.PinBlocks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pins[block.BlockHash] = *block
+	return nil
+}
+
+func (m *Memory) GetPinBlock(blockHash string) (*models.PinBlocks, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.pins, blockHash)
+}
+
+func (m *Memory) ListPinBlocks(timeRange TimeRange, page Page) ([]models.PinBlocks, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return memListBlocks(m.pins, func(b models.PinBlocks) (*time.Time, bool) {
+		return b.Epoch, true
+	}, timeRange, page)
+}
+
+func (m *Memory) SaveVerification(v *models.BlockVerification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifications[v.BlockHash] = *v
+	return nil
+}
+
+func (m *Memory) GetVerification(id string) (*models.BlockVerification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if v, ok := m.verifications[id]; ok {
+		return &v, nil
+	}
+	for _, hash := range sortedKeys(m.verifications) {
+		if v := m.verifications[hash]; v.TxnID != nil && *v.TxnID == id {
+			return &v, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *Memory) ListVerificationsSignedBy(status, did string) ([]models.BlockVerification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rows []models.BlockVerification
+	for _, hash := range sortedKeys(m.verifications) {
+		v := m.verifications[hash]
+		if v.Status != status {
+			continue
+		}
+		var sigs []map[string]interface{}
+		if json.Unmarshal(v.Signatures, &sigs) != nil {
+			continue
+		}
+		for _, sig := range sigs {
+			if sig["did"] == did {
+				rows = append(rows, v)
+				break
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (m *Memory) UpdateHashCheck(blockHash, hashStatus, computedHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.verifications[blockHash]; ok {
+		v.HashStatus, v.ComputedHash = hashStatus, computedHash
+		m.verifications[blockHash] = v
+	}
+	return nil
+}
+
+func (m *Memory) ListVerificationsByStatus(status string, page Page) ([]models.BlockVerification, int64, error) {
+	return m.listVerifications(func(v models.BlockVerification) bool { return v.Status == status }, page)
+}
+
+func (m *Memory) ListVerificationsByHashStatus(hashStatus string, page Page) ([]models.BlockVerification, int64, error) {
+	return m.listVerifications(func(v models.BlockVerification) bool { return v.HashStatus == hashStatus }, page)
+}
+
+func (m *Memory) listVerifications(keep func(models.BlockVerification) bool, page Page) ([]models.BlockVerification, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rows []models.BlockVerification
+	for _, v := range m.verifications {
+		if keep(v) {
+			rows = append(rows, v)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].VerifiedAt.Equal(rows[j].VerifiedAt) {
+			return rows[i].VerifiedAt.After(rows[j].VerifiedAt)
+		}
+		return rows[i].BlockHash < rows[j].BlockHash
+	})
+	return pageOf(rows, page), int64(len(rows)), nil
+}
+
+func (m *Memory) SaveChainBlock(block *models.TokenChainBlock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chainBlocks[[2]string{block.TokenID, block.BlockHash}] = *block
+	return nil
+}
+
+func (m *Memory) ChainStats(tokenID string) (ChainStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats ChainStats
+	for key, b := range m.chainBlocks {
+		if key[0] != tokenID {
+			continue
+		}
+		height := b.BlockHeight
+		stats.Count++
+		if stats.MinHeight == nil || height < *stats.MinHeight {
+			stats.MinHeight = &height
+		}
+		if stats.MaxHeight == nil || height > *stats.MaxHeight {
+			stats.MaxHeight = &height
+		}
+	}
+	return stats, nil
+}
+
+func (m *Memory) ListChainBlocks(tokenID string, page Page) ([]models.TokenChainBlock, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rows []models.TokenChainBlock
+	for key, b := range m.chainBlocks {
+		if key[0] == tokenID {
+			rows = append(rows, b)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].BlockHeight != rows[j].BlockHeight {
+			return rows[i].BlockHeight < rows[j].BlockHeight
+		}
+		return rows[i].BlockHash < rows[j].BlockHash
+	})
+	return pageOf(rows, page), nil
+}
+
+func (m *Memory) DeleteChain(tokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.chainBlocks {
+		if key[0] == tokenID {
+			delete(m.chainBlocks, key)
+		}
+	}
+	return nil
+}
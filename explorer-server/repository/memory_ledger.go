@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"sort"
+
+	"explorer-server/database/models"
+)
+
+func (m *Memory) SaveLedgerEntries(blockHash string, rows []models.LedgerEntry, unvalued []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(unvalued) > 0 {
+		m.unvalued[blockHash] = append([]string(nil), unvalued...)
+	} else {
+		delete(m.unvalued, blockHash)
+	}
+	if len(rows) == 0 {
+		delete(m.ledger, blockHash)
+		return nil
+	}
+	stored := make([]models.LedgerEntry, len(rows))
+	for i, row := range rows {
+		m.nextID++
+		row.ID = m.nextID
+		stored[i] = row
+	}
+	m.ledger[blockHash] = stored
+	return nil
+}
+
+func (m *Memory) ListLedgerEntries(accounts []string, page Page) ([]models.LedgerEntry, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := stringSet(accounts)
+	var rows []models.LedgerEntry
+	for _, entries := range m.ledger {
+		for _, e := range entries {
+			if wanted[e.Account] {
+				rows = append(rows, e)
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if less, ok := earlierEpoch(rows[i].Epoch, rows[j].Epoch); ok {
+			return less
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return pageOf(rows, page), int64(len(rows)), nil
+}
+
+func (m *Memory) LedgerBalances(accounts []string) ([]LedgerBalance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := stringSet(accounts)
+	sums := map[[2]string]*LedgerBalance{}
+	for _, entries := range m.ledger {
+		for _, e := range entries {
+			if accounts != nil && !wanted[e.Account] {
+				continue
+			}
+			key := [2]string{e.Account, e.Asset}
+			b, ok := sums[key]
+			if !ok {
+				b = &LedgerBalance{Account: e.Account, Asset: e.Asset}
+				sums[key] = b
+			}
+			b.Debits = b.Debits.Add(e.Debit)
+			b.Credits = b.Credits.Add(e.Credit)
+		}
+	}
+
+	balances := make([]LedgerBalance, 0, len(sums))
+	for _, b := range sums {
+		b.Balance = b.Debits.Sub(b.Credits)
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Account != balances[j].Account {
+			return balances[i].Account < balances[j].Account
+		}
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances, nil
+}
+
+func (m *Memory) UnbalancedLedgerBlocks() ([]LedgerImbalance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var unbalanced []LedgerImbalance
+	for _, hash := range sortedKeys(m.ledger) {
+		byAsset := map[string]*LedgerImbalance{}
+		for _, e := range m.ledger[hash] {
+			sum, ok := byAsset[e.Asset]
+			if !ok {
+				sum = &LedgerImbalance{BlockHash: hash, Asset: e.Asset}
+				byAsset[e.Asset] = sum
+			}
+			sum.Debits = sum.Debits.Add(e.Debit)
+			sum.Credits = sum.Credits.Add(e.Credit)
+		}
+		for _, asset := range sortedKeys(byAsset) {
+			if sum := byAsset[asset]; sum.Debits != sum.Credits {
+				unbalanced = append(unbalanced, *sum)
+			}
+		}
+	}
+	return unbalanced, nil
+}
+
+func (m *Memory) EachTransferWithoutLedger(batchSize int, fn func([]models.TransferBlocks) error) error {
+	m.mu.RLock()
+	var pending []models.TransferBlocks
+	for _, hash := range sortedKeys(m.transfers) {
+		if tb := m.transfers[hash]; hasJSON(tb.Tokens, "{}") && m.needsLedger(hash) {
+			pending = append(pending, tb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachMintWithoutLedger(batchSize int, fn func([]models.MintBlocks) error) error {
+	m.mu.RLock()
+	var pending []models.MintBlocks
+	for _, hash := range sortedKeys(m.mints) {
+		if mb := m.mints[hash]; hasJSON(mb.Tokens, "{}") && m.needsLedger(hash) {
+			pending = append(pending, mb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachBurntWithoutLedger(batchSize int, fn func([]models.BurntBlocks) error) error {
+	m.mu.RLock()
+	var pending []models.BurntBlocks
+	for _, hash := range sortedKeys(m.burnts) {
+		if bb := m.burnts[hash]; hasJSON(bb.Tokens, "{}") && m.needsLedger(hash) {
+			pending = append(pending, bb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+func (m *Memory) EachPledgeWithoutLedger(batchSize int, fn func([]models.PledgeBlocks) error) error {
+	m.mu.RLock()
+	var pending []models.PledgeBlocks
+	for _, hash := range sortedKeys(m.pledges) {
+		if pb := m.pledges[hash]; hasJSON(pb.Tokens, "{}") && m.needsLedger(hash) {
+			pending = append(pending, pb)
+		}
+	}
+	m.mu.RUnlock()
+
+	return inBatches(pending, batchSize, fn)
+}
+
+// needsLedger reports whether a block has no ledger entries or left tokens out as unvalued
+func (m *Memory) needsLedger(blockHash string) bool {
+	return m.ledger[blockHash] == nil || len(m.unvalued[blockHash]) > 0
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"explorer-server/database/models"
+)
+
+func (m *Memory) ListCheckpoints() ([]models.TokenSyncCheckpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var checkpoints []models.TokenSyncCheckpoint
+	for _, id := range sortedKeys(m.checkpoints) {
+		checkpoints = append(checkpoints, m.checkpoints[id])
+	}
+	return checkpoints, nil
+}
+
+func (m *Memory) GetCheckpoint(tokenID string) (*models.TokenSyncCheckpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.checkpoints, tokenID)
+}
+
+func (m *Memory) SaveCheckpoint(cp *models.TokenSyncCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[cp.TokenID] = *cp
+	return nil
+}
+
+// ========================= Ingest inbox =========================
+
+func (m *Memory) AddInboxRow(row *models.IngestInbox) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextInboxID++
+	row.ID = m.nextInboxID
+	m.inbox[row.ID] = *row
+	return nil
+}
+
+func (m *Memory) ClaimInboxRows(kind string, now, staleBefore time.Time, limit int) ([]models.IngestInbox, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]uint64, 0, len(m.inbox))
+	for id := range m.inbox {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var rows []models.IngestInbox
+	for _, id := range ids {
+		if limit > 0 && len(rows) == limit {
+			break
+		}
+		row := m.inbox[id]
+		due := row.Status == InboxPending && !row.NextAttemptAt.After(now)
+		stale := row.Status == InboxProcessing && row.LockedAt != nil && row.LockedAt.Before(staleBefore)
+		if row.Kind != kind || !(due || stale) {
+			continue
+		}
+
+		lockedAt := now
+		row.Status, row.LockedAt = InboxProcessing, &lockedAt
+		row.Attempts++
+		m.inbox[id] = row
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (m *Memory) SaveInboxRow(row *models.IngestInbox) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inbox[row.ID] = *row
+	return nil
+}
+
+func (m *Memory) PruneInbox(processedBefore time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pruned int64
+	for id, row := range m.inbox {
+		if row.Status == InboxProcessed && row.ProcessedAt != nil && row.ProcessedAt.Before(processedBefore) {
+			delete(m.inbox, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func (m *Memory) InboxStatus() ([]IngestInboxStatus, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := map[[2]string]int64{}
+	for _, row := range m.inbox {
+		counts[[2]string{row.Kind, row.Status}]++
+	}
+
+	status := make([]IngestInboxStatus, 0, len(counts))
+	for key, n := range counts {
+		status = append(status, IngestInboxStatus{Kind: key[0], Status: key[1], Count: n})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Kind != status[j].Kind {
+			return status[i].Kind < status[j].Kind
+		}
+		return status[i].Status < status[j].Status
+	})
+	return status, nil
+}
+
+// ========================= Failed syncs =========================
+
+// recordFailedSync inserts an entry or lets refresh update the existing one
+func (m *Memory) recordFailedSync(entry *models.FailedTokenSync, refresh func(existing *models.FailedTokenSync)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.failedSyncs[entry.TokenID]
+	if !ok {
+		m.failedSyncs[entry.TokenID] = *entry
+		return nil
+	}
+	refresh(&existing)
+	existing.UpdatedAt = entry.UpdatedAt
+	m.failedSyncs[entry.TokenID] = existing
+	return nil
+}
+
+func (m *Memory) RecordFailedSync(entry *models.FailedTokenSync) error {
+	return m.recordFailedSync(entry, func(existing *models.FailedTokenSync) {
+		existing.LastError = entry.LastError
+	})
+}
+
+func (m *Memory) RecordNodeFailure(entry *models.FailedTokenSync) error {
+	return m.recordFailedSync(entry, func(existing *models.FailedTokenSync) {
+		existing.NodeTokenType, existing.DID, existing.AssetType = entry.NodeTokenType, entry.DID, entry.AssetType
+	})
+}
+
+func (m *Memory) GetFailedSync(tokenID string) (*models.FailedTokenSync, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return get(m.failedSyncs, tokenID)
+}
+
+func (m *Memory) SaveFailedSync(entry *models.FailedTokenSync) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failedSyncs[entry.TokenID] = *entry
+	return nil
+}
+
+func (m *Memory) SetFailedSyncStatus(tokenID, from, to string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.failedSyncs[tokenID]
+	if !ok || (from != "" && entry.Status != from) {
+		return false, nil
+	}
+	entry.Status, entry.UpdatedAt = to, time.Now()
+	m.failedSyncs[tokenID] = entry
+	return true, nil
+}
+
+func (m *Memory) ReleaseFailedSyncClaims() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, entry := range m.failedSyncs {
+		if entry.Status == FailedSyncRetrying {
+			entry.Status = FailedSyncPending
+			m.failedSyncs[id] = entry
+		}
+	}
+	return nil
+}
+
+func (m *Memory) ResolveFailedSync(tokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.failedSyncs[tokenID]; ok && entry.Status != FailedSyncDiscarded {
+		delete(m.failedSyncs, tokenID)
+	}
+	return nil
+}
+
+func (m *Memory) DeleteFailedSync(tokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failedSyncs, tokenID)
+	return nil
+}
+
+func (m *Memory) ListDueFailedSyncs(now time.Time, limit int) ([]models.FailedTokenSync, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var due []models.FailedTokenSync
+	for _, entry := range m.failedSyncs {
+		if entry.Status == FailedSyncPending && !entry.NextRetryAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextRetryAt.Equal(due[j].NextRetryAt) {
+			return due[i].NextRetryAt.Before(due[j].NextRetryAt)
+		}
+		return due[i].TokenID < due[j].TokenID
+	})
+	return pageOf(due, Page{Limit: limit}), nil
+}
+
+func (m *Memory) ListFailedSyncs(status string, page Page) ([]models.FailedTokenSync, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []models.FailedTokenSync
+	for _, entry := range m.failedSyncs {
+		if status == "" || entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].UpdatedAt.Equal(entries[j].UpdatedAt) {
+			return entries[i].UpdatedAt.After(entries[j].UpdatedAt)
+		}
+		return entries[i].TokenID < entries[j].TokenID
+	})
+	return pageOf(entries, page), int64(len(entries)), nil
+}
+
+// ========================= Divergences =========================
+
+func (m *Memory) SaveDivergences(rows []models.ChainDivergence) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+rows:
+	for _, row := range rows {
+		for i, d := range m.divergences {
+			if d.TokenID == row.TokenID && d.BlockIndex == row.BlockIndex && d.Kind == row.Kind &&
+				d.NodeA == row.NodeA && d.NodeB == row.NodeB {
+				row.ID = d.ID
+				m.divergences[i] = row
+				continue rows
+			}
+		}
+		m.nextID++
+		row.ID = m.nextID
+		m.divergences = append(m.divergences, row)
+	}
+	return nil
+}
+
+func (m *Memory) ListDivergences(tokenID string, page Page) ([]models.ChainDivergence, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rows []models.ChainDivergence
+	for _, d := range m.divergences {
+		if tokenID == "" || d.TokenID == tokenID {
+			rows = append(rows, d)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].DetectedAt.After(rows[j].DetectedAt) })
+	return pageOf(rows, page), int64(len(rows)), nil
+}
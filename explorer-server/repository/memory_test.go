@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+func at(unix int64) *time.Time {
+	t := time.Unix(unix, 0).UTC()
+	return &t
+}
+
+func TestMemoryCreateAndGet(t *testing.T) {
+	repos := NewMemory()
+
+	if _, err := repos.Tokens.GetRBT("t1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetRBT on empty store: got %v, want ErrNotFound", err)
+	}
+
+	rbt := models.RBT{TokenID: "t1", OwnerDID: "did1", TokenValue: decimal.FromInt(1)}
+	if err := repos.Tokens.CreateRBT(&rbt); err != nil {
+		t.Fatalf("CreateRBT: %v", err)
+	}
+	if err := repos.Tokens.CreateRBT(&rbt); err == nil {
+		t.Fatal("second CreateRBT with the same ID succeeded")
+	}
+
+	rbt.TokenValue = decimal.FromInt(2)
+	if err := repos.Tokens.SaveRBT(&rbt); err != nil {
+		t.Fatalf("SaveRBT: %v", err)
+	}
+	got, err := repos.Tokens.GetRBT("t1")
+	if err != nil || got.TokenValue != decimal.FromInt(2) {
+		t.Fatalf("GetRBT after save = %+v, %v", got, err)
+	}
+
+	tokenType := models.TokenType{TokenID: "t1", TokenType: "RBT"}
+	repos.Tokens.EnsureTokenType(&tokenType)
+	other := models.TokenType{TokenID: "t1", TokenType: "FT"}
+	repos.Tokens.EnsureTokenType(&other)
+	if other.TokenType != "RBT" {
+		t.Fatalf("EnsureTokenType replaced the existing type: %+v", other)
+	}
+}
+
+func TestMemoryListBlocksNewestFirstWithinRange(t *testing.T) {
+	repos := NewMemory()
+	for _, b := range []models.MintBlocks{
+		{BlockHash: "a", TxnType: "01", BlockTime: models.BlockTime{Epoch: at(100)}},
+		{BlockHash: "b", TxnType: "05", BlockTime: models.BlockTime{Epoch: at(300)}},
+		{BlockHash: "c", TxnType: "01", BlockTime: models.BlockTime{Epoch: at(200)}},
+		{BlockHash: "d", TxnType: "01"},
+	} {
+		b := b
+		repos.Blocks.SaveMintBlock(&b)
+	}
+
+	blocks, total, _ := repos.Blocks.ListMintBlocks("", TimeRange{}, Page{Limit: 10})
+	if total != 4 || hashes(blocks) != "b,c,a,d" {
+		t.Fatalf("unfiltered list = %s (total %d), want b,c,a,d (4)", hashes(blocks), total)
+	}
+
+	blocks, total, _ = repos.Blocks.ListMintBlocks("01", TimeRange{From: at(150)}, Page{Limit: 10})
+	if total != 1 || hashes(blocks) != "c" {
+		t.Fatalf("filtered list = %s (total %d), want c (1)", hashes(blocks), total)
+	}
+
+	blocks, total, _ = repos.Blocks.ListMintBlocks("", TimeRange{}, Page{Limit: 2, Offset: 2})
+	if total != 4 || hashes(blocks) != "a,d" {
+		t.Fatalf("second page = %s (total %d), want a,d (4)", hashes(blocks), total)
+	}
+}
+
+func hashes(blocks []models.MintBlocks) string {
+	s := ""
+	for i, b := range blocks {
+		if i > 0 {
+			s += ","
+		}
+		s += b.BlockHash
+	}
+	return s
+}
+
+func TestMemoryInboxLease(t *testing.T) {
+	store := NewMemory().Sync
+	now := time.Now()
+
+	row := models.IngestInbox{Kind: "block", Status: InboxPending, NextAttemptAt: now}
+	store.AddInboxRow(&row)
+	later := models.IngestInbox{Kind: "block", Status: InboxPending, NextAttemptAt: now.Add(time.Hour)}
+	store.AddInboxRow(&later)
+
+	claimed, _ := store.ClaimInboxRows("block", now, now.Add(-time.Minute), 10)
+	if len(claimed) != 1 || claimed[0].ID != row.ID || claimed[0].Attempts != 1 {
+		t.Fatalf("first claim = %+v, want row %d with one attempt", claimed, row.ID)
+	}
+	if again, _ := store.ClaimInboxRows("block", now, now.Add(-time.Minute), 10); len(again) != 0 {
+		t.Fatalf("leased row claimed twice: %+v", again)
+	}
+
+	// the lease runs out once the lock is older than staleBefore
+	stale, _ := store.ClaimInboxRows("block", now, now.Add(time.Minute), 10)
+	if len(stale) != 1 || stale[0].Attempts != 2 {
+		t.Fatalf("stale claim = %+v, want the leased row with two attempts", stale)
+	}
+}
+
+func TestMemoryFailedSyncRecordKeepsSchedule(t *testing.T) {
+	store := NewMemory().Sync
+	retryAt := time.Now().Add(time.Hour)
+
+	store.RecordFailedSync(&models.FailedTokenSync{TokenID: "t1", Status: FailedSyncPending, LastError: "first", NextRetryAt: retryAt})
+	store.RecordFailedSync(&models.FailedTokenSync{TokenID: "t1", Status: FailedSyncPending, LastError: "second", NextRetryAt: time.Now()})
+
+	entry, err := store.GetFailedSync("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.LastError != "second" || !entry.NextRetryAt.Equal(retryAt) {
+		t.Fatalf("entry = %+v, want refreshed error and the original retry time", entry)
+	}
+
+	if moved, _ := store.SetFailedSyncStatus("t1", FailedSyncRetrying, FailedSyncDiscarded); moved {
+		t.Fatal("status moved from a status the entry does not have")
+	}
+	store.SetFailedSyncStatus("t1", "", FailedSyncDiscarded)
+	store.ResolveFailedSync("t1")
+	if _, err := store.GetFailedSync("t1"); err != nil {
+		t.Fatal("discarded entry was resolved away")
+	}
+}
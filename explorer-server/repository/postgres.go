@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewPostgres returns repositories backed by a GORM Postgres connection
+func NewPostgres(db *gorm.DB) Repositories {
+	return Repositories{
+		Tokens:         &pgTokens{db: db},
+		Blocks:         &pgBlocks{db: db},
+		DIDs:           &pgDIDs{db: db},
+		SmartContracts: &pgSmartContracts{db: db},
+		Analytics:      &pgAnalytics{db: db},
+		Ledger:         &pgLedger{db: db},
+		Sync:           &pgSync{db: db},
+		inTx: func(fn func(Repositories) error) error {
+			return db.Transaction(func(tx *gorm.DB) error { return fn(NewPostgres(tx)) })
+		},
+	}
+}
+
+// notFound maps GORM's missing-row error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// first loads the first row matching the condition
+func first[T any](db *gorm.DB, query string, args ...interface{}) (*T, error) {
+	var row T
+	if err := db.Where(query, args...).First(&row).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &row, nil
+}
+
+// count counts every row of a table
+func count[T any](db *gorm.DB) (int64, error) {
+	var n int64
+	err := db.Model(new(T)).Count(&n).Error
+	return n, err
+}
+
+// upsert inserts a row, overwriting the row with the same primary key
+func upsert(db *gorm.DB, value interface{}) error {
+	err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(value).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil
+	}
+	return err
+}
+
+// applyRange restricts a query to the time range on the given timestamp column
+func applyRange(query *gorm.DB, column string, r TimeRange) *gorm.DB {
+	if r.From != nil {
+		query = query.Where(column+" >= ?", *r.From)
+	}
+	if r.To != nil {
+		query = query.Where(column+" < ?", *r.To)
+	}
+	return query
+}
+
+// ========================= Tokens =========================
+
+type pgTokens struct{ db *gorm.DB }
+
+func (p *pgTokens) CountRBTs() (int64, error) { return count[models.RBT](p.db) }
+
+func (p *pgTokens) GetRBT(tokenID string) (*models.RBT, error) {
+	return first[models.RBT](p.db, "rbt_id = ?", tokenID)
+}
+
+func (p *pgTokens) ListRBTs(page Page) ([]models.RBT, error) {
+	var rbts []models.RBT
+	err := p.db.Limit(page.Limit).Offset(page.Offset).Find(&rbts).Error
+	return rbts, err
+}
+
+func (p *pgTokens) ListFreeRBTs(ownerDID string, page Page) ([]models.RBT, int64, error) {
+	query := p.db.Model(&models.RBT{}).Where("owner_did = ? AND token_status = ?", ownerDID, 0)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rbts []models.RBT
+	if err := query.Limit(page.Limit).Offset(page.Offset).Find(&rbts).Error; err != nil {
+		return nil, 0, err
+	}
+	return rbts, total, nil
+}
+
+func (p *pgTokens) RBTValues(tokenIDs []string) (map[string]decimal.Decimal, error) {
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []models.RBT
+	if err := p.db.Select("rbt_id, token_value").Where("rbt_id IN ?", tokenIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]decimal.Decimal, len(rows))
+	for _, r := range rows {
+		values[r.TokenID] = r.TokenValue
+	}
+	return values, nil
+}
+
+func (p *pgTokens) CreateRBT(rbt *models.RBT) error { return p.db.Create(rbt).Error }
+func (p *pgTokens) SaveRBT(rbt *models.RBT) error   { return p.db.Save(rbt).Error }
+
+func (p *pgTokens) DeleteRBT(tokenID string) error {
+	return p.db.Where("rbt_id = ?", tokenID).Delete(&models.RBT{}).Error
+}
+
+func (p *pgTokens) CountFTs() (int64, error) { return count[models.FT](p.db) }
+
+func (p *pgTokens) GetFT(ftID string) (*models.FT, error) {
+	return first[models.FT](p.db, "ft_id = ?", ftID)
+}
+
+func (p *pgTokens) ListFTsByOwner(ownerDID string) ([]models.FT, error) {
+	var fts []models.FT
+	err := p.db.Where("owner_did = ?", ownerDID).Find(&fts).Error
+	return fts, err
+}
+
+func (p *pgTokens) CreateFT(ft *models.FT) error { return p.db.Create(ft).Error }
+func (p *pgTokens) SaveFT(ft *models.FT) error   { return p.db.Save(ft).Error }
+
+func (p *pgTokens) DeleteFT(ftID string) error {
+	return p.db.Where("ft_id = ?", ftID).Delete(&models.FT{}).Error
+}
+
+func (p *pgTokens) CountNFTs() (int64, error) { return count[models.NFT](p.db) }
+
+func (p *pgTokens) GetNFT(nftID string) (*models.NFT, error) {
+	return first[models.NFT](p.db, "nft_id = ?", nftID)
+}
+
+func (p *pgTokens) CreateNFT(nft *models.NFT) error { return p.db.Create(nft).Error }
+func (p *pgTokens) SaveNFT(nft *models.NFT) error   { return p.db.Save(nft).Error }
+
+func (p *pgTokens) DeleteNFT(nftID string) error {
+	return p.db.Where("nft_id = ?", nftID).Delete(&models.NFT{}).Error
+}
+
+func (p *pgTokens) GetTokenType(tokenID string) (*models.TokenType, error) {
+	return first[models.TokenType](p.db, "token_id = ?", tokenID)
+}
+
+func (p *pgTokens) ListTokenTypes() ([]models.TokenType, error) {
+	var tokens []models.TokenType
+	err := p.db.Order("token_id").Find(&tokens).Error
+	return tokens, err
+}
+
+func (p *pgTokens) TokenTypes(tokenIDs []string) (map[string]string, error) {
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []models.TokenType
+	if err := p.db.Select("token_id, token_type").Where("token_id IN ?", tokenIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]string, len(rows))
+	for _, r := range rows {
+		types[r.TokenID] = r.TokenType
+	}
+	return types, nil
+}
+
+func (p *pgTokens) EnsureTokenType(tokenType *models.TokenType) error {
+	return p.db.FirstOrCreate(tokenType, models.TokenType{TokenID: tokenType.TokenID}).Error
+}
+
+func (p *pgTokens) DeleteTokenType(tokenID string) error {
+	return p.db.Where("token_id = ?", tokenID).Delete(&models.TokenType{}).Error
+}
+
+// ========================= DIDs =========================
+
+type pgDIDs struct{ db *gorm.DB }
+
+func (p *pgDIDs) CountDIDs() (int64, error) { return count[models.DIDs](p.db) }
+
+func (p *pgDIDs) GetDID(did string) (*models.DIDs, error) {
+	return first[models.DIDs](p.db, "did = ?", did)
+}
+
+func (p *pgDIDs) ListRBTHolders(page Page) ([]models.DIDs, error) {
+	var dids []models.DIDs
+	err := p.db.Where("did IS NOT NULL AND did != '0'").
+		Order("total_rbts desc").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&dids).Error
+	return dids, err
+}
+
+func (p *pgDIDs) CreateDID(did *models.DIDs) error { return p.db.Create(did).Error }
+func (p *pgDIDs) SaveDID(did *models.DIDs) error   { return p.db.Save(did).Error }
+
+func (p *pgDIDs) ListDIDs(page Page) ([]models.DIDs, error) {
+	var dids []models.DIDs
+	err := p.db.Order("did").Limit(page.Limit).Offset(page.Offset).Find(&dids).Error
+	return dids, err
+}
+
+func (p *pgDIDs) DerivedDIDTotals(dids []string) (map[string]models.DIDs, error) {
+	return derivedDIDTotals(p.db, dids)
+}
+
+func (p *pgDIDs) RecomputeDIDs(dids []string) ([]models.DIDs, error) {
+	if len(dids) == 0 {
+		return nil, nil
+	}
+
+	var written []models.DIDs
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		// lock the stored rows so concurrent recomputes of a DID apply one after the other
+		var existing []models.DIDs
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("did IN ?", dids).Find(&existing).Error; err != nil {
+			return err
+		}
+		createdAt := make(map[string]time.Time, len(existing))
+		for _, d := range existing {
+			createdAt[d.DID] = d.CreatedAt
+		}
+
+		derived, err := derivedDIDTotals(tx, dids)
+		if err != nil {
+			return err
+		}
+		for _, row := range derived {
+			if t, ok := createdAt[row.DID]; ok {
+				row.CreatedAt = t
+			} else if isEmptyDID(row) {
+				continue
+			} else {
+				row.CreatedAt = time.Now()
+			}
+			written = append(written, row)
+		}
+		if len(written) == 0 {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "did"}},
+			DoUpdates: clause.AssignmentColumns([]string{"total_rbts", "total_fts", "total_nfts", "total_sc"}),
+		}).Create(&written).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(written, func(i, j int) bool { return written[i].DID < written[j].DID })
+	return written, nil
+}
+
+// didTotal is one per-DID aggregate of a token table
+type didTotal struct {
+	DID   string          `gorm:"column:did"`
+	Total decimal.Decimal `gorm:"column:total"`
+}
+
+// derivedDIDTotals aggregates each token table per owning DID, restricted to dids unless nil
+func derivedDIDTotals(db *gorm.DB, dids []string) (map[string]models.DIDs, error) {
+	totals := map[string]models.DIDs{}
+	for _, did := range dids {
+		totals[did] = models.DIDs{DID: did}
+	}
+
+	for _, agg := range []struct {
+		model  interface{}
+		column string
+		expr   string
+		free   bool
+		apply  func(d *models.DIDs, v decimal.Decimal)
+	}{
+		{&models.RBT{}, "owner_did", "SUM(token_value)", true, func(d *models.DIDs, v decimal.Decimal) { d.TotalRBTs = v }},
+		{&models.FT{}, "owner_did", "COUNT(*)", true, func(d *models.DIDs, v decimal.Decimal) { d.TotalFTs = v }},
+		{&models.NFT{}, "owner_did", "COUNT(*)", false, func(d *models.DIDs, v decimal.Decimal) { d.TotalNFTs = v.IntPart() }},
+		{&models.SmartContract{}, "deployer_did", "COUNT(*)", false, func(d *models.DIDs, v decimal.Decimal) { d.TotalSC = v.IntPart() }},
+	} {
+		query := db.Model(agg.model).
+			Select(agg.column + " AS did, " + agg.expr + " AS total").
+			Group(agg.column)
+		if agg.free {
+			query = query.Where("token_status = ?", 0)
+		}
+		if dids != nil {
+			query = query.Where(agg.column+" IN ?", dids)
+		}
+
+		var rows []didTotal
+		if err := query.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			row := totals[r.DID]
+			row.DID = r.DID
+			agg.apply(&row, r.Total)
+			totals[r.DID] = row
+		}
+	}
+	return totals, nil
+}
+
+func (p *pgDIDs) SaveDIDKey(key *models.DIDKey) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "did"}},
+		UpdateAll: true,
+	}).Create(key).Error
+}
+
+func (p *pgDIDs) PublicKeys(dids []string) (map[string]string, error) {
+	if len(dids) == 0 {
+		return nil, nil
+	}
+
+	var rows []models.DIDKey
+	if err := p.db.Where("did IN ?", dids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]string, len(rows))
+	for _, k := range rows {
+		keys[k.DID] = k.PublicKey
+	}
+	return keys, nil
+}
+
+// ========================= Smart contracts =========================
+
+type pgSmartContracts struct{ db *gorm.DB }
+
+func (p *pgSmartContracts) CountSmartContracts() (int64, error) {
+	return count[models.SmartContract](p.db)
+}
+
+func (p *pgSmartContracts) GetSmartContract(contractID string) (*models.SmartContract, error) {
+	return first[models.SmartContract](p.db, "contract_id = ?", contractID)
+}
+
+func (p *pgSmartContracts) CreateSmartContract(sc *models.SmartContract) error {
+	return p.db.Create(sc).Error
+}
+
+func (p *pgSmartContracts) SaveSmartContract(sc *models.SmartContract) error {
+	return p.db.Save(sc).Error
+}
+
+func (p *pgSmartContracts) DeleteSmartContract(contractID string) error {
+	return p.db.Where("contract_id = ?", contractID).Delete(&models.SmartContract{}).Error
+}
+
+func (p *pgSmartContracts) SaveSCBlock(block *models.SC_Block) error {
+	return upsert(p.db, block)
+}
+
+func (p *pgSmartContracts) GetSCBlock(blockID string) (*models.SC_Block, error) {
+	return first[models.SC_Block](p.db, "block_id = ?", blockID)
+}
+
+func (p *pgSmartContracts) ListSCBlocks(timeRange TimeRange, page Page) ([]models.SC_Block, int64, error) {
+	return listBlocks[models.SC_Block](p.db, "", timeRange, page)
+}
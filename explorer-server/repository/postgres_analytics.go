@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"explorer-server/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pgAnalytics struct{ db *gorm.DB }
+
+func (p *pgAnalytics) SaveQuorumPledges(rows []models.QuorumPledge) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "block_hash"}, {Name: "quorum_did"}, {Name: "pledged_token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"txn_id", "txn_type", "pledged_value", "epoch"}),
+	}).Create(&rows).Error
+}
+
+func (p *pgAnalytics) QuorumStats(page Page) ([]QuorumStats, int64, error) {
+	var total int64
+	if err := p.db.Model(&models.QuorumPledge{}).
+		Distinct("quorum_did").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var stats []QuorumStats
+	if err := p.db.Model(&models.QuorumPledge{}).
+		Select(`quorum_did,
+			COUNT(DISTINCT block_hash) AS validations,
+			COUNT(*) AS pledged_tokens,
+			COALESCE(SUM(pledged_value), 0) AS pledged_value,
+			MIN(epoch) AS first_epoch,
+			MAX(epoch) AS last_epoch`).
+		Group("quorum_did").
+		Order("validations DESC, quorum_did").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Scan(&stats).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return stats, total, nil
+}
+
+func (p *pgAnalytics) QuorumVolume(quorumDID, interval string, timeRange TimeRange) ([]QuorumVolumePoint, error) {
+	bucket := fmt.Sprintf("date_trunc('%s', epoch)", interval)
+
+	query := p.db.Model(&models.QuorumPledge{}).
+		Select(bucket + ` AS interval_start,
+			COUNT(DISTINCT block_hash) AS validations,
+			COUNT(*) AS pledged_tokens,
+			COALESCE(SUM(pledged_value), 0) AS pledged_value`).
+		Where("epoch IS NOT NULL")
+
+	if quorumDID != "" {
+		query = query.Where("quorum_did = ?", quorumDID)
+	}
+	query = applyRange(query, "epoch", timeRange)
+
+	var points []QuorumVolumePoint
+	err := query.Group("interval_start").Order("interval_start").Scan(&points).Error
+	return points, err
+}
+
+func (p *pgAnalytics) QuorumTransactions(quorumDID string, page Page) ([]QuorumTransaction, int64, error) {
+	var total int64
+	if err := p.db.Model(&models.QuorumPledge{}).
+		Where("quorum_did = ?", quorumDID).
+		Distinct("block_hash").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var txns []QuorumTransaction
+	if err := p.db.Table(`"QuorumPledges" AS q`).
+		Select(`q.block_hash,
+			MAX(q.txn_id) AS txn_id,
+			MAX(q.txn_type) AS txn_type,
+			MAX(t.sender_did) AS sender_did,
+			MAX(t.receiver_did) AS receiver_did,
+			MAX(t.amount) AS amount,
+			MAX(q.epoch) AS epoch,
+			COUNT(*) AS pledged_tokens,
+			COALESCE(SUM(q.pledged_value), 0) AS pledged_value`).
+		Joins(`LEFT JOIN "TransferBlocks" t ON t.block_hash = q.block_hash`).
+		Where("q.quorum_did = ?", quorumDID).
+		Group("q.block_hash").
+		Order("epoch DESC NULLS LAST, q.block_hash").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Scan(&txns).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return txns, total, nil
+}
+
+func (p *pgAnalytics) SaveTokenLineage(rows []models.TokenLineage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}, {Name: "related_token_id"}, {Name: "relation"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"token_value": gorm.Expr(`COALESCE(excluded.token_value, "TokenLineage".token_value)`)}),
+	}).Create(&rows).Error
+}
+
+func (p *pgAnalytics) LineageOf(tokenIDs []string) ([]models.TokenLineage, error) {
+	var rows []models.TokenLineage
+	err := p.db.Where("token_id IN ?", tokenIDs).Order("related_token_id").Find(&rows).Error
+	return rows, err
+}
+
+func (p *pgAnalytics) LineageChildren(parentIDs []string) ([]models.TokenLineage, error) {
+	var rows []models.TokenLineage
+	err := p.db.Where("related_token_id IN ? AND relation = ?", parentIDs, LineageParent).
+		Order("token_id").Find(&rows).Error
+	return rows, err
+}
+
+func (p *pgAnalytics) SaveOwnershipEvents(rows []models.TokenOwnership) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}, {Name: "block_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"event_type", "from_did", "to_did", "txn_id", "epoch"}),
+	}).Create(&rows).Error
+}
+
+func (p *pgAnalytics) TokenOwnershipHistory(tokenID string, timeRange TimeRange, page Page) ([]models.TokenOwnership, int64, error) {
+	query := applyRange(p.db.Model(&models.TokenOwnership{}).Where("token_id = ?", tokenID), "epoch", timeRange)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.TokenOwnership
+	err := query.Order("epoch ASC NULLS LAST, id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (p *pgAnalytics) TokensHeldBy(did string, page Page) ([]HeldToken, int64, error) {
+	var total int64
+	if err := p.db.Model(&models.TokenOwnership{}).
+		Where("to_did = ? OR from_did = ?", did, did).
+		Distinct("token_id").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tokens []HeldToken
+	if err := p.db.Model(&models.TokenOwnership{}).
+		Select(`token_id,
+			COUNT(*) FILTER (WHERE to_did = @did) AS acquisitions,
+			COUNT(*) FILTER (WHERE from_did = @did) AS releases,
+			MIN(epoch) FILTER (WHERE to_did = @did) AS first_acquired,
+			MAX(epoch) FILTER (WHERE from_did = @did) AS last_released`, sql.Named("did", did)).
+		Where("to_did = @did OR from_did = @did", sql.Named("did", did)).
+		Group("token_id").
+		Order("first_acquired ASC NULLS LAST, token_id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Scan(&tokens).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range tokens {
+		tokens[i].Holding = tokens[i].Acquisitions > tokens[i].Releases
+	}
+	return tokens, total, nil
+}
+
+func (p *pgAnalytics) OwnershipEventsUntil(did string, until time.Time) ([]models.TokenOwnership, error) {
+	var rows []models.TokenOwnership
+	err := p.db.
+		Where("(to_did = ? OR from_did = ?) AND epoch IS NOT NULL AND epoch <= ?", did, did, until).
+		Order("epoch, id").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (p *pgAnalytics) EachTransferWithoutPledges(batchSize int, fn func([]models.TransferBlocks) error) error {
+	var batch []models.TransferBlocks
+	return p.db.
+		Where("validator_pledge_map IS NOT NULL AND validator_pledge_map::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "QuorumPledges" q WHERE q.block_hash = "TransferBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgAnalytics) EachMintWithoutLineage(batchSize int, fn func([]models.MintBlocks) error) error {
+	var batch []models.MintBlocks
+	return p.db.
+		Where("genesis_block IS NOT NULL AND genesis_block::text <> 'null'").
+		Where(`NOT EXISTS (SELECT 1 FROM "TokenLineage" l WHERE l.block_hash = "MintBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgAnalytics) EachBurntWithoutLineage(batchSize int, fn func([]models.BurntBlocks) error) error {
+	var batch []models.BurntBlocks
+	return p.db.
+		Where("child_tokens IS NOT NULL AND child_tokens::text NOT IN ('null', '[]')").
+		Where(`NOT EXISTS (SELECT 1 FROM "TokenLineage" l WHERE l.block_hash = "BurntBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgAnalytics) EachTransferWithoutOwnership(batchSize int, fn func([]models.TransferBlocks) error) error {
+	var batch []models.TransferBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "TokenOwnershipHistory" o WHERE o.block_hash = "TransferBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgAnalytics) EachMintWithoutOwnership(batchSize int, fn func([]models.MintBlocks) error) error {
+	var batch []models.MintBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "TokenOwnershipHistory" o WHERE o.block_hash = "MintBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgAnalytics) EachBurntWithoutOwnership(batchSize int, fn func([]models.BurntBlocks) error) error {
+	var batch []models.BurntBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "TokenOwnershipHistory" o WHERE o.block_hash = "BurntBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pgBlocks struct{ db *gorm.DB }
+
+// listBlocks loads one page of a block table within the time range, newest first,
+// filtered by txn_type when txnType is not empty
+func listBlocks[T any](db *gorm.DB, txnType string, timeRange TimeRange, page Page) ([]T, int64, error) {
+	query := applyRange(db.Model(new(T)), "epoch", timeRange)
+	if txnType != "" {
+		query = query.Where("txn_type = ?", txnType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []T
+	if err := query.
+		Order("epoch DESC NULLS LAST").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (p *pgBlocks) IndexBlock(entry *models.AllBlocks) error {
+	return p.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error
+}
+
+func (p *pgBlocks) FindIndexedBlock(id string) (*models.AllBlocks, error) {
+	return first[models.AllBlocks](p.db, "block_hash = ? OR txn_id = ?", id, id)
+}
+
+func (p *pgBlocks) BlockTypeOfTxn(txnID string) (string, error) {
+	var blockType string
+	err := p.db.Model(&models.AllBlocks{}).
+		Select("block_type").
+		Where("txn_id = ?", txnID).
+		Scan(&blockType).Error
+	return blockType, err
+}
+
+func (p *pgBlocks) CountTransferBlocks() (int64, error) {
+	return count[models.TransferBlocks](p.db)
+}
+
+func (p *pgBlocks) SaveTransferBlock(block *models.TransferBlocks) error {
+	return upsert(p.db, block)
+}
+
+func (p *pgBlocks) GetTransferBlock(blockHash string) (*models.TransferBlocks, error) {
+	return first[models.TransferBlocks](p.db, "block_hash = ?", blockHash)
+}
+
+func (p *pgBlocks) GetTransferBlockByTxnID(txnID string) (*models.TransferBlocks, error) {
+	return first[models.TransferBlocks](p.db, "txn_id = ?", txnID)
+}
+
+func (p *pgBlocks) ListTransferBlocks(timeRange TimeRange, page Page) ([]models.TransferBlocks, int64, error) {
+	return listBlocks[models.TransferBlocks](p.db.Where("epoch IS NOT NULL"), "", timeRange, page)
+}
+
+func (p *pgBlocks) ListTransferBlocksWithoutAmount() ([]models.TransferBlocks, error) {
+	var blocks []models.TransferBlocks
+	err := p.db.Where("amount IS NULL OR amount = 0").Find(&blocks).Error
+	return blocks, err
+}
+
+func (p *pgBlocks) UpdateTransferAmount(blockHash string, amount decimal.Decimal) error {
+	return p.db.Model(&models.TransferBlocks{}).
+		Where("block_hash = ?", blockHash).
+		Update("amount", amount).Error
+}
+
+func (p *pgBlocks) SaveBurntBlock(block *models.BurntBlocks) error { return upsert(p.db, block) }
+
+func (p *pgBlocks) GetBurntBlock(blockHash string) (*models.BurntBlocks, error) {
+	return first[models.BurntBlocks](p.db, "block_hash = ?", blockHash)
+}
+
+func (p *pgBlocks) ListBurntBlocks(timeRange TimeRange, page Page) ([]models.BurntBlocks, int64, error) {
+	return listBlocks[models.BurntBlocks](p.db, "", timeRange, page)
+}
+
+func (p *pgBlocks) SaveMintBlock(block *models.MintBlocks) error { return upsert(p.db, block) }
+
+func (p *pgBlocks) GetMintBlock(blockHash string) (*models.MintBlocks, error) {
+	return first[models.MintBlocks](p.db, "block_hash = ?", blockHash)
+}
+
+func (p *pgBlocks) ListMintBlocks(txnType string, timeRange TimeRange, page Page) ([]models.MintBlocks, int64, error) {
+	return listBlocks[models.MintBlocks](p.db, txnType, timeRange, page)
+}
+
+func (p *pgBlocks) SavePledgeBlock(block *models.PledgeBlocks) error { return upsert(p.db, block) }
+
+func (p *pgBlocks) GetPledgeBlock(blockHash string) (*models.PledgeBlocks, error) {
+	return first[models.PledgeBlocks](p.db, "block_hash = ?", blockHash)
+}
+
+func (p *pgBlocks) ListPledgeBlocks(txnType string, timeRange TimeRange, page Page) ([]models.PledgeBlocks, int64, error) {
+	return listBlocks[models.PledgeBlocks](p.db, txnType, timeRange, page)
+}
+
+func (p *pgBlocks) SaveCommitBlock(block *models.CommitBlocks) error { return upsert(p.db, block) }
+
+func (p *pgBlocks) GetCommitBlock(blockHash string) (*models.CommitBlocks, error) {
+	return first[models.CommitBlocks](p.db, "block_hash = ?", blockHash)
+}
+
+func (p *pgBlocks) ListCommitBlocks(txnType string, timeRange TimeRange, page Page) ([]models.CommitBlocks, int64, error) {
+	return listBlocks[models.CommitBlocks](p.db, txnType, timeRange, page)
+}
+
+func (p *pgBlocks) SavePinBlock(block *models.PinBlocks) error { return upsert(p.db, block) }
+
+func (p *pgBlocks) GetPinBlock(blockHash string) (*models.PinBlocks, error) {
+	return first[models.PinBlocks](p.db, "block_hash = ?", blockHash)
+}
+
+func (p *pgBlocks) ListPinBlocks(timeRange TimeRange, page Page) ([]models.PinBlocks, int64, error) {
+	return listBlocks[models.PinBlocks](p.db, "", timeRange, page)
+}
+
+func (p *pgBlocks) SaveVerification(v *models.BlockVerification) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "block_hash"}},
+		UpdateAll: true,
+	}).Create(v).Error
+}
+
+func (p *pgBlocks) GetVerification(id string) (*models.BlockVerification, error) {
+	return first[models.BlockVerification](p.db, "block_hash = ? OR txn_id = ?", id, id)
+}
+
+func (p *pgBlocks) ListVerificationsSignedBy(status, did string) ([]models.BlockVerification, error) {
+	filter, err := json.Marshal([]map[string]string{{"did": did}})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.BlockVerification
+	err = p.db.Where("status = ? AND signatures @> ?", status, string(filter)).Find(&rows).Error
+	return rows, err
+}
+
+func (p *pgBlocks) UpdateHashCheck(blockHash, hashStatus, computedHash string) error {
+	return p.db.Model(&models.BlockVerification{}).
+		Where("block_hash = ?", blockHash).
+		Updates(map[string]interface{}{
+			"hash_status":   hashStatus,
+			"computed_hash": computedHash,
+		}).Error
+}
+
+func (p *pgBlocks) ListVerificationsByStatus(status string, page Page) ([]models.BlockVerification, int64, error) {
+	return p.listVerifications(p.db.Model(&models.BlockVerification{}).Where("status = ?", status), page)
+}
+
+func (p *pgBlocks) ListVerificationsByHashStatus(hashStatus string, page Page) ([]models.BlockVerification, int64, error) {
+	return p.listVerifications(p.db.Model(&models.BlockVerification{}).Where("hash_status = ?", hashStatus), page)
+}
+
+func (p *pgBlocks) listVerifications(query *gorm.DB, page Page) ([]models.BlockVerification, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.BlockVerification
+	if err := query.
+		Order("verified_at DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (p *pgBlocks) SaveChainBlock(block *models.TokenChainBlock) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}, {Name: "block_hash"}},
+		UpdateAll: true,
+	}).Create(block).Error
+}
+
+func (p *pgBlocks) ChainStats(tokenID string) (ChainStats, error) {
+	var stats ChainStats
+	err := p.db.Model(&models.TokenChainBlock{}).
+		Select("COUNT(*) AS count, MIN(block_height) AS min_height, MAX(block_height) AS max_height").
+		Where("token_id = ?", tokenID).
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (p *pgBlocks) ListChainBlocks(tokenID string, page Page) ([]models.TokenChainBlock, error) {
+	var rows []models.TokenChainBlock
+	err := p.db.
+		Where("token_id = ?", tokenID).
+		Order("block_height, block_hash").
+		Offset(page.Offset).
+		Limit(page.Limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+func (p *pgBlocks) DeleteChain(tokenID string) error {
+	return p.db.Where("token_id = ?", tokenID).Delete(&models.TokenChainBlock{}).Error
+}
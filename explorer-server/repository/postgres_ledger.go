@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"explorer-server/database/models"
+
+	"gorm.io/gorm"
+)
+
+type pgLedger struct{ db *gorm.DB }
+
+func (p *pgLedger) SaveLedgerEntries(blockHash string, rows []models.LedgerEntry, unvalued []string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("block_hash = ?", blockHash).Delete(&models.LedgerEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_hash = ?", blockHash).Delete(&models.UnvaluedLedgerToken{}).Error; err != nil {
+			return err
+		}
+		if len(unvalued) > 0 {
+			flagged := make([]models.UnvaluedLedgerToken, len(unvalued))
+			for i, tokenID := range unvalued {
+				flagged[i] = models.UnvaluedLedgerToken{BlockHash: blockHash, TokenID: tokenID, FlaggedAt: time.Now()}
+			}
+			if err := tx.Create(&flagged).Error; err != nil {
+				return err
+			}
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (p *pgLedger) ListLedgerEntries(accounts []string, page Page) ([]models.LedgerEntry, int64, error) {
+	query := p.db.Model(&models.LedgerEntry{}).Where("account IN ?", accounts)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.LedgerEntry
+	err := query.Order("epoch ASC NULLS LAST, id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (p *pgLedger) LedgerBalances(accounts []string) ([]LedgerBalance, error) {
+	query := p.db.Model(&models.LedgerEntry{}).
+		Select(`account, asset,
+			SUM(debit) AS debits,
+			SUM(credit) AS credits,
+			SUM(debit) - SUM(credit) AS balance`)
+	if accounts != nil {
+		query = query.Where("account IN ?", accounts)
+	}
+
+	var balances []LedgerBalance
+	err := query.Group("account, asset").Order("account, asset").Scan(&balances).Error
+	return balances, err
+}
+
+func (p *pgLedger) UnbalancedLedgerBlocks() ([]LedgerImbalance, error) {
+	var unbalanced []LedgerImbalance
+	err := p.db.Model(&models.LedgerEntry{}).
+		Select("block_hash, asset, SUM(debit) AS debits, SUM(credit) AS credits").
+		Group("block_hash, asset").
+		Having("SUM(debit) <> SUM(credit)").
+		Order("block_hash, asset").
+		Scan(&unbalanced).Error
+	return unbalanced, err
+}
+
+func (p *pgLedger) EachTransferWithoutLedger(batchSize int, fn func([]models.TransferBlocks) error) error {
+	var batch []models.TransferBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "LedgerEntries" l WHERE l.block_hash = "TransferBlocks".block_hash)
+			OR EXISTS (SELECT 1 FROM "UnvaluedLedgerTokens" u WHERE u.block_hash = "TransferBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgLedger) EachMintWithoutLedger(batchSize int, fn func([]models.MintBlocks) error) error {
+	var batch []models.MintBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "LedgerEntries" l WHERE l.block_hash = "MintBlocks".block_hash)
+			OR EXISTS (SELECT 1 FROM "UnvaluedLedgerTokens" u WHERE u.block_hash = "MintBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgLedger) EachBurntWithoutLedger(batchSize int, fn func([]models.BurntBlocks) error) error {
+	var batch []models.BurntBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "LedgerEntries" l WHERE l.block_hash = "BurntBlocks".block_hash)
+			OR EXISTS (SELECT 1 FROM "UnvaluedLedgerTokens" u WHERE u.block_hash = "BurntBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
+
+func (p *pgLedger) EachPledgeWithoutLedger(batchSize int, fn func([]models.PledgeBlocks) error) error {
+	var batch []models.PledgeBlocks
+	return p.db.
+		Where("tokens IS NOT NULL AND tokens::text NOT IN ('null', '{}')").
+		Where(`NOT EXISTS (SELECT 1 FROM "LedgerEntries" l WHERE l.block_hash = "PledgeBlocks".block_hash)
+			OR EXISTS (SELECT 1 FROM "UnvaluedLedgerTokens" u WHERE u.block_hash = "PledgeBlocks".block_hash)`).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error { return fn(batch) }).Error
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"time"
+
+	"explorer-server/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pgSync struct{ db *gorm.DB }
+
+func (p *pgSync) ListCheckpoints() ([]models.TokenSyncCheckpoint, error) {
+	var checkpoints []models.TokenSyncCheckpoint
+	err := p.db.Find(&checkpoints).Error
+	return checkpoints, err
+}
+
+func (p *pgSync) GetCheckpoint(tokenID string) (*models.TokenSyncCheckpoint, error) {
+	return first[models.TokenSyncCheckpoint](p.db, "token_id = ?", tokenID)
+}
+
+func (p *pgSync) SaveCheckpoint(cp *models.TokenSyncCheckpoint) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		UpdateAll: true,
+	}).Create(cp).Error
+}
+
+// ========================= Ingest inbox =========================
+
+func (p *pgSync) AddInboxRow(row *models.IngestInbox) error { return p.db.Create(row).Error }
+
+// ClaimInboxRows skips rows locked by another worker so concurrent pollers never share a row
+func (p *pgSync) ClaimInboxRows(kind string, now, staleBefore time.Time, limit int) ([]models.IngestInbox, error) {
+	var rows []models.IngestInbox
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("kind = ?", kind).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_at < ?)",
+				InboxPending, now, InboxProcessing, staleBefore).
+			Order("id").
+			Limit(limit).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+			rows[i].Attempts++
+			rows[i].Status = InboxProcessing
+			rows[i].LockedAt = &now
+		}
+		return tx.Model(&models.IngestInbox{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":    InboxProcessing,
+				"locked_at": now,
+				"attempts":  gorm.Expr("attempts + 1"),
+			}).Error
+	})
+
+	return rows, err
+}
+
+func (p *pgSync) SaveInboxRow(row *models.IngestInbox) error { return p.db.Save(row).Error }
+
+func (p *pgSync) PruneInbox(processedBefore time.Time) (int64, error) {
+	res := p.db.
+		Where("status = ? AND processed_at < ?", InboxProcessed, processedBefore).
+		Delete(&models.IngestInbox{})
+	return res.RowsAffected, res.Error
+}
+
+func (p *pgSync) InboxStatus() ([]IngestInboxStatus, error) {
+	var counts []IngestInboxStatus
+	err := p.db.Model(&models.IngestInbox{}).
+		Select("kind, status, COUNT(*) AS count").
+		Group("kind, status").
+		Order("kind, status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// ========================= Failed syncs =========================
+
+// recordFailedSync inserts an entry or refreshes the given columns of the existing one
+func (p *pgSync) recordFailedSync(entry *models.FailedTokenSync, refresh ...string) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		DoUpdates: clause.AssignmentColumns(refresh),
+	}).Create(entry).Error
+}
+
+func (p *pgSync) RecordFailedSync(entry *models.FailedTokenSync) error {
+	return p.recordFailedSync(entry, "last_error", "updated_at")
+}
+
+func (p *pgSync) RecordNodeFailure(entry *models.FailedTokenSync) error {
+	return p.recordFailedSync(entry, "node_token_type", "did", "asset_type", "updated_at")
+}
+
+func (p *pgSync) GetFailedSync(tokenID string) (*models.FailedTokenSync, error) {
+	return first[models.FailedTokenSync](p.db, "token_id = ?", tokenID)
+}
+
+func (p *pgSync) SaveFailedSync(entry *models.FailedTokenSync) error { return p.db.Save(entry).Error }
+
+func (p *pgSync) SetFailedSyncStatus(tokenID, from, to string) (bool, error) {
+	query := p.db.Model(&models.FailedTokenSync{}).Where("token_id = ?", tokenID)
+	if from != "" {
+		query = query.Where("status = ?", from)
+	}
+
+	res := query.Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (p *pgSync) ReleaseFailedSyncClaims() error {
+	return p.db.Model(&models.FailedTokenSync{}).
+		Where("status = ?", FailedSyncRetrying).
+		Update("status", FailedSyncPending).Error
+}
+
+func (p *pgSync) ResolveFailedSync(tokenID string) error {
+	return p.db.
+		Where("token_id = ? AND status <> ?", tokenID, FailedSyncDiscarded).
+		Delete(&models.FailedTokenSync{}).Error
+}
+
+func (p *pgSync) DeleteFailedSync(tokenID string) error {
+	return p.db.Where("token_id = ?", tokenID).Delete(&models.FailedTokenSync{}).Error
+}
+
+func (p *pgSync) ListDueFailedSyncs(now time.Time, limit int) ([]models.FailedTokenSync, error) {
+	var due []models.FailedTokenSync
+	err := p.db.
+		Where("status = ? AND next_retry_at <= ?", FailedSyncPending, now).
+		Order("next_retry_at").
+		Limit(limit).
+		Find(&due).Error
+	return due, err
+}
+
+func (p *pgSync) ListFailedSyncs(status string, page Page) ([]models.FailedTokenSync, int64, error) {
+	query := p.db.Model(&models.FailedTokenSync{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.FailedTokenSync
+	if err := query.
+		Order("updated_at DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// ========================= Divergences =========================
+
+// SaveDivergences records divergences; one already known is kept once with its latest detection
+func (p *pgSync) SaveDivergences(rows []models.ChainDivergence) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return p.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "token_id"}, {Name: "block_index"}, {Name: "kind"}, {Name: "node_a"}, {Name: "node_b"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"detected_at", "hash_a", "hash_b", "detail"}),
+	}).Create(&rows).Error
+}
+
+func (p *pgSync) ListDivergences(tokenID string, page Page) ([]models.ChainDivergence, int64, error) {
+	query := p.db.Model(&models.ChainDivergence{})
+	if tokenID != "" {
+		query = query.Where("token_id = ?", tokenID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.ChainDivergence
+	if err := query.
+		Order("detected_at DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
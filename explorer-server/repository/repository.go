@@ -0,0 +1,435 @@
+// Package repository is the storage behind the explorer services: one interface per
+// aggregate, with a Postgres implementation for the server and an in-memory one for tests.
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+// ErrNotFound is returned when a requested row does not exist
+var ErrNotFound = errors.New("record not found")
+
+// TimeRange restricts a query to rows with From <= epoch < To; nil bounds are open
+type TimeRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// Contains reports whether t falls inside the range; a missing time only matches an open range
+func (r TimeRange) Contains(t *time.Time) bool {
+	if t == nil {
+		return r.From == nil && r.To == nil
+	}
+	if r.From != nil && t.Before(*r.From) {
+		return false
+	}
+	if r.To != nil && !t.Before(*r.To) {
+		return false
+	}
+	return true
+}
+
+// isEmptyDID reports whether a DID holds nothing
+func isEmptyDID(d models.DIDs) bool {
+	return d.TotalRBTs.IsZero() && d.TotalFTs.IsZero() && d.TotalNFTs == 0 && d.TotalSC == 0
+}
+
+// Page selects Limit rows starting at Offset
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// NewPage converts a 1-based page number into a Page, defaulting to 10 rows on page 1
+func NewPage(limit, page int) Page {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return Page{Limit: limit, Offset: (page - 1) * limit}
+}
+
+// TokenRepository stores RBT, FT and NFT tokens and the asset type of every token ID
+type TokenRepository interface {
+	CountRBTs() (int64, error)
+	GetRBT(tokenID string) (*models.RBT, error)
+	ListRBTs(page Page) ([]models.RBT, error)
+	// ListFreeRBTs returns the RBTs of a DID that are not locked (token_status 0)
+	ListFreeRBTs(ownerDID string, page Page) ([]models.RBT, int64, error)
+	// RBTValues returns the value of every known RBT among tokenIDs
+	RBTValues(tokenIDs []string) (map[string]decimal.Decimal, error)
+	CreateRBT(rbt *models.RBT) error
+	SaveRBT(rbt *models.RBT) error
+	DeleteRBT(tokenID string) error
+
+	CountFTs() (int64, error)
+	GetFT(ftID string) (*models.FT, error)
+	ListFTsByOwner(ownerDID string) ([]models.FT, error)
+	CreateFT(ft *models.FT) error
+	SaveFT(ft *models.FT) error
+	DeleteFT(ftID string) error
+
+	CountNFTs() (int64, error)
+	GetNFT(nftID string) (*models.NFT, error)
+	CreateNFT(nft *models.NFT) error
+	SaveNFT(nft *models.NFT) error
+	DeleteNFT(nftID string) error
+
+	GetTokenType(tokenID string) (*models.TokenType, error)
+	ListTokenTypes() ([]models.TokenType, error)
+	// TokenTypes returns the asset type of every known token among tokenIDs
+	TokenTypes(tokenIDs []string) (map[string]string, error)
+	// EnsureTokenType creates the token type entry unless the token already has one
+	EnsureTokenType(tokenType *models.TokenType) error
+	DeleteTokenType(tokenID string) error
+}
+
+// BlockRepository stores the AllBlocks index, the per-type block tables and block verifications
+type BlockRepository interface {
+	// IndexBlock adds a block to AllBlocks; an existing entry is kept
+	IndexBlock(entry *models.AllBlocks) error
+	// FindIndexedBlock looks a block up in AllBlocks by block hash or txn ID
+	FindIndexedBlock(id string) (*models.AllBlocks, error)
+	// BlockTypeOfTxn returns the AllBlocks type of a txn ID, or "" when it is not indexed
+	BlockTypeOfTxn(txnID string) (string, error)
+
+	CountTransferBlocks() (int64, error)
+	SaveTransferBlock(block *models.TransferBlocks) error
+	GetTransferBlock(blockHash string) (*models.TransferBlocks, error)
+	GetTransferBlockByTxnID(txnID string) (*models.TransferBlocks, error)
+	// ListTransferBlocks returns timestamped transfer blocks within the range, newest first
+	ListTransferBlocks(timeRange TimeRange, page Page) ([]models.TransferBlocks, int64, error)
+	ListTransferBlocksWithoutAmount() ([]models.TransferBlocks, error)
+	UpdateTransferAmount(blockHash string, amount decimal.Decimal) error
+
+	SaveBurntBlock(block *models.BurntBlocks) error
+	GetBurntBlock(blockHash string) (*models.BurntBlocks, error)
+	ListBurntBlocks(timeRange TimeRange, page Page) ([]models.BurntBlocks, int64, error)
+
+	// The lifecycle lists are filtered by txnType when it is not empty
+	SaveMintBlock(block *models.MintBlocks) error
+	GetMintBlock(blockHash string) (*models.MintBlocks, error)
+	ListMintBlocks(txnType string, timeRange TimeRange, page Page) ([]models.MintBlocks, int64, error)
+	SavePledgeBlock(block *models.PledgeBlocks) error
+	GetPledgeBlock(blockHash string) (*models.PledgeBlocks, error)
+	ListPledgeBlocks(txnType string, timeRange TimeRange, page Page) ([]models.PledgeBlocks, int64, error)
+	SaveCommitBlock(block *models.CommitBlocks) error
+	GetCommitBlock(blockHash string) (*models.CommitBlocks, error)
+	ListCommitBlocks(txnType string, timeRange TimeRange, page Page) ([]models.CommitBlocks, int64, error)
+	SavePinBlock(block *models.PinBlocks) error
+	GetPinBlock(blockHash string) (*models.PinBlocks, error)
+	ListPinBlocks(timeRange TimeRange, page Page) ([]models.PinBlocks, int64, error)
+
+	SaveVerification(v *models.BlockVerification) error
+	// GetVerification looks a verification up by block hash or txn ID
+	GetVerification(id string) (*models.BlockVerification, error)
+	// ListVerificationsSignedBy returns the verifications of one status carrying a signature of did
+	ListVerificationsSignedBy(status, did string) ([]models.BlockVerification, error)
+	// UpdateHashCheck records a recomputed hash on the verification of a stored block
+	UpdateHashCheck(blockHash, hashStatus, computedHash string) error
+	// ListVerificationsByStatus lists verifications by signature status, most recently verified first
+	ListVerificationsByStatus(status string, page Page) ([]models.BlockVerification, int64, error)
+	// ListVerificationsByHashStatus lists verifications by hash status, most recently verified first
+	ListVerificationsByHashStatus(hashStatus string, page Page) ([]models.BlockVerification, int64, error)
+
+	// SaveChainBlock stores a block of a token's local chain, replacing the same block
+	SaveChainBlock(block *models.TokenChainBlock) error
+	// ChainStats describes the heights stored for a token
+	ChainStats(tokenID string) (ChainStats, error)
+	// ListChainBlocks returns a token's stored blocks in height order
+	ListChainBlocks(tokenID string, page Page) ([]models.TokenChainBlock, error)
+	DeleteChain(tokenID string) error
+}
+
+// ChainStats is the number of stored blocks of a token chain and their height range
+type ChainStats struct {
+	Count     int64
+	MinHeight *int64
+	MaxHeight *int64
+}
+
+// DIDRepository stores the per-DID token totals and the public keys of DIDs.
+// The totals are derived from the token tables: the value of free RBTs, the number of
+// free FTs, the NFTs held and the smart contracts deployed.
+type DIDRepository interface {
+	CountDIDs() (int64, error)
+	GetDID(did string) (*models.DIDs, error)
+	// ListDIDs returns stored DIDs in DID order
+	ListDIDs(page Page) ([]models.DIDs, error)
+	// ListRBTHolders returns DIDs ordered by their RBT total, largest first
+	ListRBTHolders(page Page) ([]models.DIDs, error)
+	CreateDID(did *models.DIDs) error
+	SaveDID(did *models.DIDs) error
+
+	// DerivedDIDTotals computes the totals of dids from the token tables; nil dids covers
+	// every DID that holds a token. CreatedAt is not set on the returned rows.
+	DerivedDIDTotals(dids []string) (map[string]models.DIDs, error)
+	// RecomputeDIDs overwrites the stored totals of dids with the derived ones in one
+	// transaction and returns the rows written. A missing row is only created when the
+	// DID holds something.
+	RecomputeDIDs(dids []string) ([]models.DIDs, error)
+
+	SaveDIDKey(key *models.DIDKey) error
+	// PublicKeys returns the registered public key of every known DID among dids
+	PublicKeys(dids []string) (map[string]string, error)
+}
+
+// SmartContractRepository stores smart contracts and their deploy/execute blocks
+type SmartContractRepository interface {
+	CountSmartContracts() (int64, error)
+	GetSmartContract(contractID string) (*models.SmartContract, error)
+	CreateSmartContract(sc *models.SmartContract) error
+	SaveSmartContract(sc *models.SmartContract) error
+	DeleteSmartContract(contractID string) error
+
+	SaveSCBlock(block *models.SC_Block) error
+	GetSCBlock(blockID string) (*models.SC_Block, error)
+	ListSCBlocks(timeRange TimeRange, page Page) ([]models.SC_Block, int64, error)
+}
+
+// QuorumStats summarizes the participation of one quorum DID
+type QuorumStats struct {
+	QuorumDID     string          `json:"quorum_did"`
+	Validations   int64           `json:"validations"`
+	PledgedTokens int64           `json:"pledged_tokens"`
+	PledgedValue  decimal.Decimal `json:"pledged_value"`
+	FirstEpoch    *time.Time      `json:"first_epoch"`
+	LastEpoch     *time.Time      `json:"last_epoch"`
+}
+
+// QuorumVolumePoint is the pledged volume of one time bucket
+type QuorumVolumePoint struct {
+	IntervalStart time.Time       `json:"interval_start"`
+	Validations   int64           `json:"validations"`
+	PledgedTokens int64           `json:"pledged_tokens"`
+	PledgedValue  decimal.Decimal `json:"pledged_value"`
+}
+
+// QuorumTransaction is one block a quorum pledged tokens for
+type QuorumTransaction struct {
+	BlockHash     string           `json:"block_hash"`
+	TxnID         *string          `json:"txn_id"`
+	TxnType       string           `json:"txn_type"`
+	SenderDID     *string          `json:"sender_did"`
+	ReceiverDID   *string          `json:"receiver_did"`
+	Amount        *decimal.Decimal `json:"amount"`
+	Epoch         *time.Time       `json:"epoch"`
+	PledgedTokens int64            `json:"pledged_tokens"`
+	PledgedValue  decimal.Decimal  `json:"pledged_value"`
+}
+
+// Token lineage relations: RelatedTokenID is the <relation> of TokenID
+const (
+	LineageParent      = "parent"
+	LineageGrandParent = "grandparent"
+	LineagePrevious    = "previous"
+)
+
+// Token ownership events
+const (
+	OwnershipMint     = "mint"
+	OwnershipTransfer = "transfer"
+	OwnershipBurn     = "burn"
+)
+
+// HeldToken summarizes the ownership history of one token for one DID. Holding is set
+// while the DID received the token more often than it gave it away.
+type HeldToken struct {
+	TokenID       string     `json:"token_id"`
+	Acquisitions  int64      `json:"acquisitions"`
+	Releases      int64      `json:"releases"`
+	FirstAcquired *time.Time `json:"first_acquired"`
+	LastReleased  *time.Time `json:"last_released"`
+	Holding       bool       `json:"holding" gorm:"-"`
+}
+
+// AnalyticsRepository stores the rows derived from blocks: quorum pledges, token lineage
+// and token ownership history
+type AnalyticsRepository interface {
+	// SaveQuorumPledges upserts pledges keyed by block hash, quorum DID and pledged token
+	SaveQuorumPledges(rows []models.QuorumPledge) error
+	// QuorumStats returns per-quorum totals, most active first, and the number of quorums
+	QuorumStats(page Page) ([]QuorumStats, int64, error)
+	// QuorumVolume buckets the timestamped pledges by interval (hour, day, week or month);
+	// an empty quorumDID covers every quorum
+	QuorumVolume(quorumDID, interval string, timeRange TimeRange) ([]QuorumVolumePoint, error)
+	// QuorumTransactions lists the blocks a quorum pledged for, newest first
+	QuorumTransactions(quorumDID string, page Page) ([]QuorumTransaction, int64, error)
+
+	// SaveTokenLineage upserts lineage relations, keeping a known token value over a missing one
+	SaveTokenLineage(rows []models.TokenLineage) error
+	// LineageOf returns every relation recorded for the given tokens
+	LineageOf(tokenIDs []string) ([]models.TokenLineage, error)
+	// LineageChildren returns the tokens split from the given tokens
+	LineageChildren(parentIDs []string) ([]models.TokenLineage, error)
+
+	// SaveOwnershipEvents upserts ownership events keyed by token and block hash
+	SaveOwnershipEvents(rows []models.TokenOwnership) error
+	// TokenOwnershipHistory lists the ownership events of a token within the range, oldest first
+	TokenOwnershipHistory(tokenID string, timeRange TimeRange, page Page) ([]models.TokenOwnership, int64, error)
+	// TokensHeldBy lists every token a DID received or gave away, by first acquisition
+	TokensHeldBy(did string, page Page) ([]HeldToken, int64, error)
+	// OwnershipEventsUntil returns the timestamped events a DID received or gave a token in
+	// at or before until, oldest first
+	OwnershipEventsUntil(did string, until time.Time) ([]models.TokenOwnership, error)
+
+	// The backfill iterators hand over, in batches, the blocks stored before the derived
+	// rows were tracked: transfers with a pledge map but no pledges, mint blocks with a
+	// genesis block or burnt blocks with child tokens but no lineage, and transfer, mint
+	// and burnt blocks with tokens but no ownership events.
+	EachTransferWithoutPledges(batchSize int, fn func([]models.TransferBlocks) error) error
+	EachMintWithoutLineage(batchSize int, fn func([]models.MintBlocks) error) error
+	EachBurntWithoutLineage(batchSize int, fn func([]models.BurntBlocks) error) error
+	EachTransferWithoutOwnership(batchSize int, fn func([]models.TransferBlocks) error) error
+	EachMintWithoutOwnership(batchSize int, fn func([]models.MintBlocks) error) error
+	EachBurntWithoutOwnership(batchSize int, fn func([]models.BurntBlocks) error) error
+}
+
+// Ledger system accounts: the source of minted value, the sink of burnt value and the
+// prefix of the account holding the tokens a DID pledged
+const (
+	LedgerIssuance      = "@issuance"
+	LedgerBurnt         = "@burnt"
+	LedgerPledgedPrefix = "@pledged:"
+)
+
+// Ledger entry types
+const (
+	LedgerMint       = "mint"
+	LedgerTransfer   = "transfer"
+	LedgerBurn       = "burn"
+	LedgerBurntForFT = "burnt_for_ft"
+	LedgerPledge     = "pledge"
+	LedgerUnpledge   = "unpledge"
+)
+
+// LedgerBalance sums the entries of one account in one asset; Balance is debits minus credits
+type LedgerBalance struct {
+	Account string          `json:"account"`
+	Asset   string          `json:"asset"`
+	Debits  decimal.Decimal `json:"debits"`
+	Credits decimal.Decimal `json:"credits"`
+	Balance decimal.Decimal `json:"balance"`
+}
+
+// LedgerImbalance is a block whose entries of one asset do not net to zero
+type LedgerImbalance struct {
+	BlockHash string          `json:"block_hash"`
+	Asset     string          `json:"asset"`
+	Debits    decimal.Decimal `json:"debits"`
+	Credits   decimal.Decimal `json:"credits"`
+}
+
+// LedgerRepository stores the double-entry ledger of value movements
+type LedgerRepository interface {
+	// SaveLedgerEntries replaces the entries of a block and its unvalued tokens in one transaction
+	SaveLedgerEntries(blockHash string, rows []models.LedgerEntry, unvalued []string) error
+	// ListLedgerEntries lists the entries of the given accounts, oldest first
+	ListLedgerEntries(accounts []string, page Page) ([]models.LedgerEntry, int64, error)
+	// LedgerBalances sums the entries per account and asset; nil accounts covers every account
+	LedgerBalances(accounts []string) ([]LedgerBalance, error)
+	// UnbalancedLedgerBlocks returns the blocks whose debits and credits differ for an asset
+	UnbalancedLedgerBlocks() ([]LedgerImbalance, error)
+
+	// The backfill iterators hand over, in batches, the transfer, mint, burnt and pledge
+	// blocks with tokens but no ledger entries, or with tokens left out as unvalued
+	EachTransferWithoutLedger(batchSize int, fn func([]models.TransferBlocks) error) error
+	EachMintWithoutLedger(batchSize int, fn func([]models.MintBlocks) error) error
+	EachBurntWithoutLedger(batchSize int, fn func([]models.BurntBlocks) error) error
+	EachPledgeWithoutLedger(batchSize int, fn func([]models.PledgeBlocks) error) error
+}
+
+// IngestInboxStatus counts inbox rows per kind and status
+type IngestInboxStatus struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// Inbox statuses
+const (
+	InboxPending    = "pending"
+	InboxProcessing = "processing"
+	InboxProcessed  = "processed"
+	InboxDead       = "dead"
+)
+
+// Failed-sync statuses
+const (
+	FailedSyncPending   = "pending"
+	FailedSyncRetrying  = "retrying"
+	FailedSyncExhausted = "exhausted"
+	FailedSyncDiscarded = "discarded"
+)
+
+// SyncRepository stores the sync bookkeeping: chain checkpoints, the ingest inbox,
+// the failed-sync dead letters and the divergences found across fullnodes
+type SyncRepository interface {
+	ListCheckpoints() ([]models.TokenSyncCheckpoint, error)
+	GetCheckpoint(tokenID string) (*models.TokenSyncCheckpoint, error)
+	SaveCheckpoint(cp *models.TokenSyncCheckpoint) error
+
+	AddInboxRow(row *models.IngestInbox) error
+	// ClaimInboxRows leases up to limit due rows of a kind: pending rows whose next attempt
+	// is due and processing rows locked before staleBefore. Claimed rows count one attempt.
+	ClaimInboxRows(kind string, now, staleBefore time.Time, limit int) ([]models.IngestInbox, error)
+	SaveInboxRow(row *models.IngestInbox) error
+	// PruneInbox deletes rows processed before the given time and returns how many went
+	PruneInbox(processedBefore time.Time) (int64, error)
+	InboxStatus() ([]IngestInboxStatus, error)
+
+	// RecordFailedSync adds a dead letter; an existing entry only gets its error refreshed
+	RecordFailedSync(entry *models.FailedTokenSync) error
+	// RecordNodeFailure adds a dead letter reported by the fullnode; an existing entry only
+	// gets the reported node token type, DID and asset type
+	RecordNodeFailure(entry *models.FailedTokenSync) error
+	GetFailedSync(tokenID string) (*models.FailedTokenSync, error)
+	SaveFailedSync(entry *models.FailedTokenSync) error
+	// SetFailedSyncStatus moves an entry from one status to another ("" matches any status)
+	// and reports whether an entry moved
+	SetFailedSyncStatus(tokenID, from, to string) (bool, error)
+	// ReleaseFailedSyncClaims moves every retrying entry back to pending
+	ReleaseFailedSyncClaims() error
+	// ResolveFailedSync deletes an entry once its token synced, unless it was discarded
+	ResolveFailedSync(tokenID string) error
+	DeleteFailedSync(tokenID string) error
+	// ListDueFailedSyncs returns pending entries whose retry is due, earliest first
+	ListDueFailedSyncs(now time.Time, limit int) ([]models.FailedTokenSync, error)
+	// ListFailedSyncs lists entries, optionally of one status, most recently updated first
+	ListFailedSyncs(status string, page Page) ([]models.FailedTokenSync, int64, error)
+
+	// SaveDivergences records divergences; a divergence already recorded for the same token,
+	// block, kind and nodes is updated with its latest detection instead of added again
+	SaveDivergences(rows []models.ChainDivergence) error
+	// ListDivergences lists divergences, optionally of one token, newest first
+	ListDivergences(tokenID string, page Page) ([]models.ChainDivergence, int64, error)
+}
+
+// Repositories groups the repositories the services run on
+type Repositories struct {
+	Tokens         TokenRepository
+	Blocks         BlockRepository
+	DIDs           DIDRepository
+	SmartContracts SmartContractRepository
+	Analytics      AnalyticsRepository
+	Ledger         LedgerRepository
+	Sync           SyncRepository
+
+	// inTx runs fn on repositories bound to one database transaction; nil runs fn directly
+	inTx func(fn func(Repositories) error) error
+}
+
+// Transaction runs fn with repositories whose writes are committed together, or rolled back
+// together when fn returns an error. The in-memory repositories apply writes immediately.
+func (r Repositories) Transaction(fn func(Repositories) error) error {
+	if r.inTx == nil {
+		return fn(r)
+	}
+	return r.inTx(fn)
+}
@@ -12,6 +12,11 @@ import (
 func NewRouter() *mux.Router {
 	r := mux.NewRouter()
 
+	// Every answer carries a request ID; errors use the JSON envelope, unknown routes included
+	r.Use(handlers.RequestID)
+	r.NotFoundHandler = handlers.RequestID(http.HandlerFunc(handlers.NotFoundHandler))
+	r.MethodNotAllowedHandler = handlers.RequestID(http.HandlerFunc(handlers.MethodNotAllowedHandler))
+
 	// Health
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -39,9 +44,25 @@ func NewRouter() *mux.Router {
 
 	r.HandleFunc("/api/search", handlers.GetInfo).Methods(http.MethodGet)
 	r.HandleFunc("/api/token-chain", handlers.GetTokenChainFromTokenID).Methods(http.MethodGet)
+	r.HandleFunc("/api/token-lineage", handlers.GetTokenLineageHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/token-owners", handlers.GetTokenOwnershipHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/did/held-tokens", handlers.GetDIDHeldTokensHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/did/balance-history", handlers.GetDIDBalanceHistoryHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/did/ledger", handlers.GetDIDLedgerHandler).Methods(http.MethodGet)
 	r.HandleFunc("/api/token-blocks", handlers.GetTokenBlocksFromTokenID).Methods(http.MethodGet)
 	r.HandleFunc("/api/sc-blocks", handlers.GetSCBlockList).Methods(http.MethodGet)
 	r.HandleFunc("/api/burnt-blocks", handlers.GetBurntBlockList).Methods(http.MethodGet)
+	r.HandleFunc("/api/mint-blocks", handlers.GetMintBlockList).Methods(http.MethodGet)
+	r.HandleFunc("/api/pledge-blocks", handlers.GetPledgeBlockList).Methods(http.MethodGet)
+	r.HandleFunc("/api/commit-blocks", handlers.GetCommitBlockList).Methods(http.MethodGet)
+	r.HandleFunc("/api/pin-blocks", handlers.GetPinBlockList).Methods(http.MethodGet)
+	r.HandleFunc("/api/block", handlers.GetBlockInfo).Methods(http.MethodGet)
+	r.HandleFunc("/api/quorums", handlers.GetQuorumStatsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/quorum/volume", handlers.GetQuorumVolumeHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/quorum/transactions", handlers.GetQuorumTransactionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/block-verification", handlers.GetBlockVerificationHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/divergences", handlers.GetChainDivergencesHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/verify-token-chain", handlers.VerifyTokenChainHandler).Methods(http.MethodPost)
 
 	r.HandleFunc("/api/sctxn-info", handlers.GetSCBlockInfoFromTxnHash).Methods(http.MethodGet)
 	r.HandleFunc("/api/burnttxn-info", handlers.GetBurntTxnInfoFromTxnHash).Methods(http.MethodGet)
@@ -53,6 +74,23 @@ func NewRouter() *mux.Router {
 
 	// Worker pool / queue status (for monitoring)
 	r.HandleFunc("/api/queue-status", handlers.QueueStatusHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/sync-status", handlers.SyncStatusHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/node-status", handlers.NodeStatusHandler).Methods(http.MethodGet)
+
+	// Dead-letter queue for token syncs that keep failing
+	r.HandleFunc("/api/admin/failed-syncs", handlers.GetFailedSyncsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/admin/failed-syncs/retry", handlers.RetryFailedSyncHandler).Methods(http.MethodPost)
+	r.HandleFunc("/api/admin/failed-syncs/discard", handlers.DiscardFailedSyncHandler).Methods(http.MethodPost)
+
+
+	// DID totals checked against the token tables
+	r.HandleFunc("/api/admin/did-audit", handlers.AuditDIDTotalsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/api/admin/did-audit/repair", handlers.RepairDIDTotalsHandler).Methods(http.MethodPost)
+	r.HandleFunc("/api/admin/ledger-check", handlers.CheckLedgerHandler).Methods(http.MethodGet)
+
+	// Public keys used to verify block signatures
+	r.HandleFunc("/api/admin/did-keys", handlers.RegisterDIDKeyHandler).Methods(http.MethodPost)
+	r.HandleFunc("/api/admin/unverified-blocks", handlers.GetUnverifiedBlocksHandler).Methods(http.MethodGet)
 
 	return r
 }
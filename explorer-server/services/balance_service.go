@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"time"
+)
+
+// maxBalancePoints bounds the length of one balance series
+const maxBalancePoints = 1000
+
+// ErrTooManyBalancePoints is returned when a balance series would exceed maxBalancePoints
+var ErrTooManyBalancePoints = errors.New("balance history is limited to 1000 points: narrow the range or widen the interval")
+
+// Balance is what a DID held at At, counting events at At: the value of its RBTs and the
+// number of FTs and NFTs. Unvalued counts held RBTs whose value is unknown and so is
+// missing from RBT.
+type Balance struct {
+	At       time.Time       `json:"at"`
+	RBT      decimal.Decimal `json:"rbt"`
+	FTs      int64           `json:"fts"`
+	NFTs     int64           `json:"nfts"`
+	Unvalued int64           `json:"unvalued_rbts,omitempty"`
+}
+
+// DIDBalanceHistory is the balance of a DID at the end of a range and, with an interval,
+// its balance at every interval boundary of the range
+type DIDBalanceHistory struct {
+	DID      string    `json:"did"`
+	Interval string    `json:"interval,omitempty"`
+	Balance  Balance   `json:"balance"`
+	Balances []Balance `json:"balances,omitempty"`
+}
+
+// heldAsset is the type and value a token adds to a balance
+type heldAsset struct {
+	tokenType string
+	value     decimal.Decimal
+	// unvalued marks RBTs whose value is known neither from the RBT table nor from lineage
+	unvalued bool
+}
+
+// tokenAssets classifies tokens for balances and the ledger. RBTs are valued from the RBT
+// table, then from their lineage, and are flagged unvalued otherwise; tokens without a
+// known type are RBTs or parts that have since been burnt. Smart contracts are left out.
+func tokenAssets(r repository.Repositories, ids []string) (map[string]heldAsset, error) {
+	types, err := r.Tokens.TokenTypes(ids)
+	if err != nil {
+		return nil, err
+	}
+	values, err := r.Tokens.RBTValues(ids)
+	if err != nil {
+		return nil, err
+	}
+	lineage, err := r.Analytics.LineageOf(ids)
+	if err != nil {
+		return nil, err
+	}
+	lineageValues := map[string]decimal.Decimal{}
+	for _, l := range lineage {
+		if l.TokenValue != nil {
+			lineageValues[l.TokenID] = *l.TokenValue
+		}
+	}
+
+	assets := make(map[string]heldAsset, len(ids))
+	for _, id := range ids {
+		tokenType := types[id]
+		switch tokenType {
+		case FTType, NFTType:
+			assets[id] = heldAsset{tokenType: tokenType}
+			continue
+		case SCType:
+			continue
+		}
+
+		value, ok := values[id]
+		if !ok {
+			value, ok = lineageValues[id]
+		}
+		assets[id] = heldAsset{tokenType: RBTType, value: value, unvalued: !ok}
+	}
+	return assets, nil
+}
+
+// balanceReplay applies the ownership events of one DID in time order
+type balanceReplay struct {
+	did     string
+	events  []models.TokenOwnership
+	assets  map[string]heldAsset
+	next    int
+	held    map[string]bool
+	balance Balance
+}
+
+// at applies every event up to and including t, the same bound the events of the range end
+// are loaded with, and returns the balance held at t
+func (r *balanceReplay) at(t time.Time) Balance {
+	for ; r.next < len(r.events) && !r.events[r.next].Epoch.After(t); r.next++ {
+		e := r.events[r.next]
+		asset, ok := r.assets[e.TokenID]
+		if !ok {
+			continue
+		}
+		holds := e.ToDID != nil && *e.ToDID == r.did
+		if holds == r.held[e.TokenID] {
+			continue
+		}
+		r.held[e.TokenID] = holds
+
+		sign := int64(1)
+		if !holds {
+			sign = -1
+		}
+		switch {
+		case asset.tokenType == FTType:
+			r.balance.FTs += sign
+		case asset.tokenType == NFTType:
+			r.balance.NFTs += sign
+		case asset.unvalued:
+			r.balance.Unvalued += sign
+		default:
+			r.balance.RBT = r.balance.RBT.Add(asset.value.Mul(sign))
+		}
+	}
+
+	b := r.balance
+	b.At = t
+	return b
+}
+
+// nextBoundary returns the interval boundary following t
+func nextBoundary(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return t.Add(time.Hour)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// GetDIDBalanceHistory replays the ownership history of a DID. The balance is taken at the
+// end of the range, now when it is open. With an interval (hour, day, week or month) the
+// balances series starts at the range start, or at the interval of the DID's first event,
+// and has one point per interval boundary up to the range end.
+func GetDIDBalanceHistory(did, interval string, timeRange TimeRange) (*DIDBalanceHistory, error) {
+	if interval != "" && !volumeIntervals[interval] {
+		return nil, ErrInvalidInterval
+	}
+
+	end := time.Now().UTC()
+	if timeRange.To != nil {
+		end = timeRange.To.UTC()
+	}
+
+	events, err := repos.Analytics.OwnershipEventsUntil(did, end)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	seen := map[string]bool{}
+	for _, e := range events {
+		if !seen[e.TokenID] {
+			seen[e.TokenID] = true
+			ids = append(ids, e.TokenID)
+		}
+	}
+	assets, err := tokenAssets(repos, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &balanceReplay{did: did, events: events, assets: assets, held: map[string]bool{}}
+	history := &DIDBalanceHistory{DID: did, Interval: interval}
+
+	if interval != "" {
+		start := end
+		if timeRange.From != nil {
+			start = timeRange.From.UTC()
+		} else if len(events) > 0 {
+			start = repository.TruncateInterval(*events[0].Epoch, interval)
+		}
+
+		var points []time.Time
+		for t := start; t.Before(end); t = nextBoundary(t, interval) {
+			if len(points) == maxBalancePoints {
+				return nil, ErrTooManyBalancePoints
+			}
+			points = append(points, t)
+		}
+
+		history.Balances = make([]Balance, 0, len(points)+1)
+		for _, t := range points {
+			history.Balances = append(history.Balances, replay.at(t))
+		}
+	}
+
+	history.Balance = replay.at(end)
+	if interval != "" {
+		history.Balances = append(history.Balances, history.Balance)
+	}
+	return history, nil
+}
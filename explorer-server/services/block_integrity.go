@@ -0,0 +1,51 @@
+package services
+
+import (
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+)
+
+// BlockHashResult is the hash check of one block of a token chain
+type BlockHashResult struct {
+	Index int `json:"index"`
+	util.HashCheck
+}
+
+// VerifyTokenChainHashes streams a token's chain from the fullnode and recomputes every block hash.
+// Stored verification records of the checked blocks are updated with the outcome.
+func VerifyTokenChainHashes(tokenID string) ([]BlockHashResult, error) {
+	var results []BlockHashResult
+
+	_, err := StreamTokenChainFromTokenID(tokenID, func(block map[string]interface{}) error {
+		check := util.CheckBlockHash(block)
+		results = append(results, BlockHashResult{Index: len(results), HashCheck: check})
+		recordHashCheck(check)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// recordHashCheck updates the verification record of a stored block with a fresh hash check
+func recordHashCheck(check util.HashCheck) {
+	if check.ExpectedHash == "" {
+		return
+	}
+
+	if err := repos.Blocks.UpdateHashCheck(check.ExpectedHash, check.Status, check.ComputedHash); err != nil {
+		log.Printf("⚠️ Failed to record hash check of %s: %v", check.ExpectedHash, err)
+	}
+	if check.Status == util.HashMismatch {
+		log.Printf("⚠️ Block %s has a hash mismatch (computed %s); the recomputation is not yet confirmed against fullnode data", check.ExpectedHash, check.ComputedHash)
+	}
+}
+
+// ListHashMismatches returns stored blocks whose recomputed hash differs from TCBlockHashKey
+func ListHashMismatches(limit, page int) ([]models.BlockVerification, int64, error) {
+	return repos.Blocks.ListVerificationsByHashStatus(util.HashMismatch, repository.NewPage(limit, page))
+}
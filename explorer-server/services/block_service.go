@@ -2,10 +2,10 @@ package services
 
 import (
 	"encoding/json"
-	"explorer-server/config"
-	"explorer-server/database"
 	"explorer-server/database/models"
+	"explorer-server/decimal"
 	"explorer-server/model"
+	"explorer-server/repository"
 	"explorer-server/util"
 	"fmt"
 	"io"
@@ -15,63 +15,40 @@ import (
 
 // GetTxnsCount returns total number of TransferBlocks records
 func GetTxnsCount() (int64, error) {
-	var count int64
-	if err := database.DB.Model(&models.TransferBlocks{}).Count(&count).Error; err != nil {
+	count, err := repos.Blocks.CountTransferBlocks()
+	if err != nil {
 		return 0, err
 	}
 	fmt.Printf("Total RBT count: %d\n", count)
 	return count, nil
 }
 
-func GetTransferBlocksList(limit, page int) (model.TransactionsResponse, error) {
-	var blocks []models.TransferBlocks
+// GetTransferBlocksList returns timestamped transfer blocks within the time range, newest first
+func GetTransferBlocksList(limit, page int, timeRange TimeRange) (model.TransactionsResponse, error) {
 	var response model.TransactionsResponse
 
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
-	// Fetch paginated blocks
-	if err := database.DB.
-		Where("epoch IS NOT NULL AND epoch <> 0").
-		Order("epoch DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&blocks).Error; err != nil {
-		return response, err
-	}
-
-	// Count total records
-	var count int64
-	if err := database.DB.
-		Model(&models.TransferBlocks{}).
-		Where("epoch IS NOT NULL AND epoch <> 0").
-		Count(&count).Error; err != nil {
+	// Fetch paginated blocks with the total count
+	blocks, count, err := repos.Blocks.ListTransferBlocks(timeRange, repository.NewPage(limit, page))
+	if err != nil {
 		return response, err
 	}
 
 	for _, b := range blocks {
-		if (b.Amount == nil || *b.Amount == 0) && b.TxnID != nil && *b.TxnID != "" {
+		if (b.Amount == nil || b.Amount.IsZero()) && b.TxnID != nil && *b.TxnID != "" {
 			if newAmt := fetchTxnAmountFromFullNode(*b.TxnID); newAmt != nil {
 				b.Amount = newAmt
-				_ = database.DB.Model(&b).Update("amount", *newAmt).Error
+				_ = repos.Blocks.UpdateTransferAmount(b.BlockHash, *newAmt)
 			}
 		}
 
 		response.TransactionsResponse = append(response.TransactionsResponse, model.TransactionResponse{
 			TxnHash:     deref(b.TxnID),
 			TxnType:     deref(b.TxnType),
-			Amount:      derefFloat(b.Amount),
+			Amount:      derefDecimal(b.Amount),
 			SenderDID:   deref(b.SenderDID),
 			ReceiverDID: deref(b.ReceiverDID),
 			Epoch:       b.Epoch,
 		})
-
-		log.Printf("epoch: %d\n", b.Epoch)
 	}
 	log.Printf("Total Transfer Blocks fetched: %d\n", len(response.TransactionsResponse))
 
@@ -79,21 +56,40 @@ func GetTransferBlocksList(limit, page int) (model.TransactionsResponse, error)
 	return response, nil
 }
 
-func GetTransferBlockInfoFromTxnID(hash string) (models.TransferBlocks, error) {
-	var block models.TransferBlocks
+// GetTransferBlockInfoFromTxnID returns a transfer block by txn ID together with its signature verification
+func GetTransferBlockInfoFromTxnID(hash string) (model.TransferBlockResponse, error) {
+	block, err := transferBlockByTxnID(hash)
+	return withVerification(block), err
+}
+
+// GetTransferBlockInfoFromBlockHash returns a transfer block by block hash together with its signature verification
+func GetTransferBlockInfoFromBlockHash(hash string) (model.TransferBlockResponse, error) {
+	block, err := transferBlockByHash(hash)
+	return withVerification(block), err
+}
 
-	if err := database.DB.Where("txn_id = ?", hash).First(&block).Error; err != nil {
-		return block, err
+func withVerification(block models.TransferBlocks) model.TransferBlockResponse {
+	return model.TransferBlockResponse{
+		TransferBlocks: block,
+		Verification:   GetBlockVerification(block.BlockHash),
 	}
+}
+
+func transferBlockByTxnID(hash string) (models.TransferBlocks, error) {
+	stored, err := repos.Blocks.GetTransferBlockByTxnID(hash)
+	if err != nil {
+		return models.TransferBlocks{}, err
+	}
+	block := *stored
 
 	// Fetch missing amount if needed
-	if (block.Amount == nil || *block.Amount == 0) && block.TxnID != nil && *block.TxnID != "" {
+	if (block.Amount == nil || block.Amount.IsZero()) && block.TxnID != nil && *block.TxnID != "" {
 		if newAmt := fetchTxnAmountFromFullNode(*block.TxnID); newAmt != nil {
 			block.Amount = newAmt
-			if err := database.DB.Model(&block).Update("amount", *newAmt).Error; err != nil {
+			if err := repos.Blocks.UpdateTransferAmount(block.BlockHash, *newAmt); err != nil {
 				fmt.Printf("⚠️ Failed to update amount in DB for txnID %s: %v\n", *block.TxnID, err)
 			} else {
-				fmt.Printf("✅ Updated amount %.6f for txnID %s\n", *newAmt, *block.TxnID)
+				fmt.Printf("✅ Updated amount %s for txnID %s\n", newAmt, *block.TxnID)
 			}
 		}
 	}
@@ -101,24 +97,21 @@ func GetTransferBlockInfoFromTxnID(hash string) (models.TransferBlocks, error) {
 	return block, nil
 }
 
-func GetTransferBlockInfoFromBlockHash(hash string) (models.TransferBlocks, error) {
-	var block models.TransferBlocks
-
-	if err := database.DB.Where("block_hash = ?", hash).First(&block).Error; err != nil {
-		return block, err
+func transferBlockByHash(hash string) (models.TransferBlocks, error) {
+	stored, err := repos.Blocks.GetTransferBlock(hash)
+	if err != nil {
+		return models.TransferBlocks{}, err
 	}
+	block := *stored
 
 	// If amount is missing, fetch it from fullnode
-	if (block.Amount == nil || *block.Amount == 0) && block.TxnID != nil && *block.TxnID != "" {
-		apiURL := fmt.Sprintf("%s/api/de-exp/get-txn-amount-by-txnID?txnID=%s",
-			config.RubixNodeURL, *block.TxnID,
-		)
+	if (block.Amount == nil || block.Amount.IsZero()) && block.TxnID != nil && *block.TxnID != "" {
+		apiPath := fmt.Sprintf("/api/de-exp/get-txn-amount-by-txnID?txnID=%s", *block.TxnID)
 
-		client := GetNodeHTTPClient()
 		release := acquireNodeSlot()
 		defer release()
 
-		resp, err := client.Get(apiURL)
+		resp, _, err := nodeGet(apiPath)
 		if err != nil {
 			log.Printf("⚠️ Failed to call fullnode for txn %s: %v", *block.TxnID, err)
 		} else {
@@ -131,20 +124,17 @@ func GetTransferBlockInfoFromBlockHash(hash string) (models.TransferBlocks, erro
 					Status  bool   `json:"status"`
 					Message string `json:"message"`
 					Result  struct {
-						TransactionID    string  `json:"TransactionID"`
-						TransactionValue float64 `json:"TransactionValue"`
-						BlockHash        string  `json:"BlockHash"`
+						TransactionID    string          `json:"TransactionID"`
+						TransactionValue decimal.Decimal `json:"TransactionValue"`
+						BlockHash        string          `json:"BlockHash"`
 					} `json:"result"`
 				}
 
 				if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result.Status {
-					if result.Result.TransactionValue != 0 {
+					if !result.Result.TransactionValue.IsZero() {
 						block.Amount = &result.Result.TransactionValue
 
-						_ = database.DB.
-							Model(&models.TransferBlocks{}).
-							Where("txn_id = ?", block.TxnID).
-							Update("amount", block.Amount).Error
+						_ = repos.Blocks.UpdateTransferAmount(block.BlockHash, *block.Amount)
 					}
 				}
 			}
@@ -154,16 +144,13 @@ func GetTransferBlockInfoFromBlockHash(hash string) (models.TransferBlocks, erro
 	return block, nil
 }
 
-func fetchTxnAmountFromFullNode(txnID string) *float64 {
-	url := fmt.Sprintf("%s/api/de-exp/get-txn-amount-by-txnID?txnID=%s",
-		config.RubixNodeURL, txnID,
-	)
+func fetchTxnAmountFromFullNode(txnID string) *decimal.Decimal {
+	apiPath := fmt.Sprintf("/api/de-exp/get-txn-amount-by-txnID?txnID=%s", txnID)
 
-	client := GetNodeHTTPClient()
 	release := acquireNodeSlot()
 	defer release()
 
-	resp, err := client.Get(url)
+	resp, _, err := nodeGet(apiPath)
 	if err != nil {
 		fmt.Printf("❌ Failed to call fullnode for txnID %s: %v\n", txnID, err)
 		return nil
@@ -186,9 +173,9 @@ func fetchTxnAmountFromFullNode(txnID string) *float64 {
 		Status  bool   `json:"status"`
 		Message string `json:"message"`
 		Result  struct {
-			TransactionID    string  `json:"TransactionID"`
-			TransactionValue float64 `json:"TransactionValue"`
-			BlockHash        string  `json:"BlockHash"`
+			TransactionID    string          `json:"TransactionID"`
+			TransactionValue decimal.Decimal `json:"TransactionValue"`
+			BlockHash        string          `json:"BlockHash"`
 		} `json:"result"`
 	}
 
@@ -203,14 +190,7 @@ func fetchTxnAmountFromFullNode(txnID string) *float64 {
 func GetBlockType(txnId string) (int64, error) {
 	// NOTE: DB column block_type is string (transfer/burnt/etc.).
 	// Keeping signature as int64 to match existing usage.
-	var blockTypeStr string
-
-	err := database.DB.
-		Table("all_blocks").
-		Select("block_type").
-		Where("txn_id = ?", txnId).
-		Scan(&blockTypeStr).Error
-
+	blockTypeStr, err := repos.Blocks.BlockTypeOfTxn(txnId)
 	if err != nil {
 		return 0, fmt.Errorf("❌ failed to get block_type for txn_id %s: %v", txnId, err)
 	}
@@ -229,59 +209,50 @@ func GetBlockType(txnId string) (int64, error) {
 		return 5, nil
 	case "mint":
 		return 6, nil
+	case "minted":
+		return 7, nil
+	case "migrated":
+		return 8, nil
+	case "pledged":
+		return 9, nil
+	case "unpledged":
+		return 10, nil
+	case "committed":
+		return 11, nil
+	case "contract_committed":
+		return 12, nil
+	case "pinned":
+		return 13, nil
 	default:
 		return 0, nil
 	}
 }
 
 func GetSCBlockInfoFromTxnId(hash string) (interface{}, error) {
-	var block models.SC_Block
-
-	if err := database.DB.
-		Where("block_id = ?", hash).
-		First(&block).Error; err != nil {
+	block, err := repos.SmartContracts.GetSCBlock(hash)
+	if err != nil {
 		return models.SC_Block{}, err
 	}
 
-	return block, nil
+	return *block, nil
 }
 
 func GetBurntBlockInfo(hash string) (interface{}, error) {
-	var block models.BurntBlocks
-
-	if err := database.DB.
-		Where("block_hash = ?", hash).
-		First(&block).Error; err != nil {
+	block, err := repos.Blocks.GetBurntBlock(hash)
+	if err != nil {
 		return models.BurntBlocks{}, err
 	}
 
-	return block, nil
+	return *block, nil
 }
 
-func GetBurntBlockList(limit, page int) (interface{}, error) {
-	var blocks []models.BurntBlocks
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
-	if err := database.DB.
-		Order("epoch DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&blocks).Error; err != nil {
+// GetBurntBlockList returns burnt blocks within the time range, newest first
+func GetBurntBlockList(limit, page int, timeRange TimeRange) (interface{}, error) {
+	blocks, count, err := repos.Blocks.ListBurntBlocks(timeRange, repository.NewPage(limit, page))
+	if err != nil {
 		return nil, err
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.BurntBlocks{}).Count(&count).Error; err != nil {
-		return model.BurntBlocksListResponse{}, err
-	}
-
 	response := model.BurntBlocksListResponse{
 		BurntBlocks: blocks,
 		Count:       count,
@@ -298,41 +269,24 @@ func deref(ptr *string) string {
 	return *ptr
 }
 
-func derefFloat(ptr *float64) float64 {
+func derefDecimal(ptr *decimal.Decimal) decimal.Decimal {
 	if ptr == nil {
-		return 0
+		return decimal.Zero
 	}
 	return *ptr
 }
 
-// ProcessIncomingBlock flattens numeric keys and maps them to readable names
-func ProcessIncomingBlock(blockData map[string]interface{}) map[string]interface{} {
-	flattened := util.FlattenKeys("", blockData).(map[string]interface{})
-	mapped := util.ApplyKeyMapping(flattened).(map[string]interface{})
-	return mapped
-}
+// UpdateBlocks decodes an incoming block (numeric or named keys) and routes it to the right storage function
+func UpdateBlocks(blockMap map[string]interface{}) error {
+	block, err := util.ParseTokenChainBlock(blockMap)
+	if err != nil {
+		log.Printf("❌ Rejecting pushed block: %v", err)
+		return err
+	}
 
-// UpdateBlocks processes an incoming block and routes it to the right storage function
-func UpdateBlocks(blockMap map[string]interface{}) {
-	mappedBlock := ProcessIncomingBlock(blockMap)
-
-	// Store in AllBlocks first
-	StoreBlockInAllBlocks(mappedBlock)
-
-	transType, _ := mappedBlock["TCTransTypeKey"].(string)
-
-	switch transType {
-	case "02", "2":
-		fmt.Println("Storing transfer block")
-		StoreTransferBlock(mappedBlock)
-	case "08", "13":
-		fmt.Println("Storing burnt block")
-		StoreBurntBlock(mappedBlock)
-	case "09", "9":
-		fmt.Println("Storing smart contract deploy block")
-		StoreSCDeployBlock(mappedBlock)
-	case "10":
-		fmt.Println("Storing smart contract execute block")
-		StoreSCExecuteBlock(mappedBlock)
+	if err := storeTokenChainBlock(block, BlockSourcePush, false); err != nil {
+		return err
 	}
+	storePushedChainBlock(block)
+	return nil
 }
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"explorer-server/util"
+)
+
+// transferBlockJSON is a numeric-key transfer block moving one token at height 3
+const transferBlockJSON = `{
+	"2": "02",
+	"3": "did-receiver",
+	"5": {
+		"1": "did-sender",
+		"2": "did-receiver",
+		"4": "txn-1",
+		"6": {"QmToken1": {"1": 0, "4": "3", "5": "prev-hash"}}
+	},
+	"10": 1.5,
+	"98": "hash-1",
+	"epoch": 1700000000
+}`
+
+// runInline drains the inbox on the calling goroutine instead of the worker pools
+func runInline(task func()) bool {
+	task()
+	return true
+}
+
+func TestPushedBlockIsStoredAndQueryable(t *testing.T) {
+	useMemoryRepos(t)
+
+	if _, err := EnqueueInboxPayload(InboxKindBlock, []byte(transferBlockJSON)); err != nil {
+		t.Fatal(err)
+	}
+	drainInbox(InboxKindBlock, runInline)
+
+	blockType, data, err := GetBlockByHashOrTxnID("txn-1")
+	if err != nil {
+		t.Fatalf("GetBlockByHashOrTxnID: %v", err)
+	}
+	transfer, ok := data.(*models.TransferBlocks)
+	if blockType != "transfer" || !ok {
+		t.Fatalf("got %s block %T, want transfer", blockType, data)
+	}
+	if transfer.Amount == nil || *transfer.Amount != decimal.MustParse("1.5") || deref(transfer.SenderDID) != "did-sender" {
+		t.Fatalf("stored transfer = %+v", transfer)
+	}
+
+	if next := nextChainHeight("QmToken1"); next != 4 {
+		t.Fatalf("next chain height = %d, want 4", next)
+	}
+
+	epoch := time.Unix(1700000000, 0)
+	before, after := epoch.Add(-time.Hour), epoch.Add(time.Hour)
+	for _, tc := range []struct {
+		name      string
+		timeRange TimeRange
+		want      int64
+	}{
+		{"open range", TimeRange{}, 1},
+		{"range around the block", TimeRange{From: &before, To: &after}, 1},
+		{"range after the block", TimeRange{From: &after}, 0},
+	} {
+		list, err := GetTransferBlocksList(10, 1, tc.timeRange)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if list.Count != tc.want || int64(len(list.TransactionsResponse)) != tc.want {
+			t.Fatalf("%s: got %d blocks (count %d), want %d", tc.name, len(list.TransactionsResponse), list.Count, tc.want)
+		}
+	}
+
+	status, _ := GetIngestInboxStatus()
+	if len(status) != 1 || status[0].Status != InboxProcessed || status[0].Count != 1 {
+		t.Fatalf("inbox status = %+v, want one processed row", status)
+	}
+}
+
+func TestMalformedPushIsRetriedNotLost(t *testing.T) {
+	useMemoryRepos(t)
+
+	var block map[string]interface{}
+	json.Unmarshal([]byte(transferBlockJSON), &block)
+	delete(block, "98") // no block hash
+	payload, _ := json.Marshal(block)
+
+	id, err := EnqueueInboxPayload(InboxKindBlock, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drainInbox(InboxKindBlock, runInline)
+
+	if _, _, err := GetBlockByHashOrTxnID("txn-1"); !errors.Is(err, ErrBlockNotFound) {
+		t.Fatalf("invalid block was stored: %v", err)
+	}
+
+	rows, _ := repos.Sync.ClaimInboxRows(InboxKindBlock, time.Now().Add(inboxMaxDelay), time.Now(), 10)
+	if len(rows) != 1 || rows[0].ID != id || rows[0].LastError == "" {
+		t.Fatalf("failed payload should stay in the inbox for a retry, got %+v", rows)
+	}
+}
+
+// TestSCSyncSkipsNonSCBlocks checks that a non-SC block met while syncing a smart-contract
+// chain leaves no rows behind, not just no transfer row
+func TestSCSyncSkipsNonSCBlocks(t *testing.T) {
+	mem := useMemoryRepos(t)
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(transferBlockJSON), &raw); err != nil {
+		t.Fatal(err)
+	}
+	block, err := util.DecodeTokenChainBlock(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storeTokenChainBlock(block, "node-1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.Blocks.FindIndexedBlock("txn-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("skipped block was indexed in AllBlocks (%v)", err)
+	}
+	if events, _ := mem.Analytics.OwnershipEventsUntil("did-receiver", time.Now()); len(events) != 0 {
+		t.Errorf("skipped block stored %d ownership events", len(events))
+	}
+
+	if err := storeTokenChainBlock(block, "node-1", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.Blocks.FindIndexedBlock("txn-1"); err != nil {
+		t.Errorf("stored block is not indexed: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+	"regexp"
+	"time"
+)
+
+// commentTimePattern matches the time written in burn comments (e.g. "Token burnt at : 2025-10-09 15:31:14")
+var commentTimePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`)
+
+// commentTimeZone is the zone comment times are written in; nodes stamp them in IST (UTC+5:30)
+var commentTimeZone = loadCommentTimeZone()
+
+func loadCommentTimeZone() *time.Location {
+	ist, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		log.Printf("⚠️ Failed to load IST timezone: %v", err)
+		return time.FixedZone("IST", 5*3600+30*60)
+	}
+	return ist
+}
+
+// blockTime derives the timestamp of a block: its own epoch when present, otherwise a time
+// written in its comment. Blocks with neither get no timestamp rather than the ingestion time.
+func blockTime(block *util.TokenChainBlock) models.BlockTime {
+	if t, ok := block.EpochTime(); ok {
+		t = t.UTC()
+		return models.BlockTime{Epoch: &t, EpochSource: models.EpochSourceBlock, EpochConfidence: models.EpochConfidenceHigh}
+	}
+
+	if match := commentTimePattern.FindString(block.TransInfo.Comment); match != "" {
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05", match, commentTimeZone); err == nil {
+			t = t.UTC()
+			return models.BlockTime{Epoch: &t, EpochSource: models.EpochSourceComment, EpochConfidence: models.EpochConfidenceMedium}
+		}
+	}
+
+	return models.BlockTime{EpochSource: models.EpochSourceNone, EpochConfidence: models.EpochConfidenceNone}
+}
+
+// unixEpoch converts a stored timestamp back to the unix seconds carried by blocks
+func unixEpoch(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	sec := t.Unix()
+	return &sec
+}
+
+// TimeRange restricts a query to blocks with From <= epoch < To; nil bounds are open
+type TimeRange = repository.TimeRange
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Verification statuses of a block or a single signature
+const (
+	VerificationVerified   = "verified"
+	VerificationUnverified = "unverified"
+	VerificationFailed     = "failed"
+)
+
+// DIDKeySourceAdmin marks public keys registered through the admin API
+const DIDKeySourceAdmin = "admin"
+
+// ErrInvalidPublicKey is returned when a registered public key cannot be parsed
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
+// SignatureCheck is the outcome of checking one block signature
+type SignatureCheck struct {
+	util.BlockSignature
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// VerifyBlockSignatures checks every signature of a block against the known DID keys
+func VerifyBlockSignatures(block *util.TokenChainBlock) models.BlockVerification {
+	sigs := block.Signatures()
+	checks := make([]SignatureCheck, len(sigs))
+
+	keys := loadDIDKeys(sigs)
+	for i, sig := range sigs {
+		checks[i] = checkSignature(sig, keys)
+	}
+
+	v := summarizeSignatureChecks(checks)
+	v.BlockHash = block.BlockHash
+	v.TxnID = optionalString(block.TransInfo.TID)
+	return v
+}
+
+// loadDIDKeys loads the public keys of every DID that signed, keyed by DID
+func loadDIDKeys(sigs []util.BlockSignature) map[string]string {
+	var dids []string
+	for _, s := range sigs {
+		if s.DID != "" {
+			dids = append(dids, s.DID)
+		}
+	}
+	if len(dids) == 0 {
+		return nil
+	}
+
+	keys, err := repos.DIDs.PublicKeys(dids)
+	if err != nil {
+		log.Printf("⚠️ Failed to load DID keys: %v", err)
+		return nil
+	}
+	return keys
+}
+
+func checkSignature(sig util.BlockSignature, keys map[string]string) SignatureCheck {
+	check := SignatureCheck{BlockSignature: sig, Status: VerificationUnverified}
+
+	switch {
+	case sig.NLCOnly:
+		check.Reason = "only an NLC signature is present"
+		return check
+	case sig.DID == "":
+		check.Status, check.Reason = VerificationFailed, "signature has no DID"
+		return check
+	case sig.Message == "":
+		check.Reason = "signed hash is missing"
+		return check
+	}
+
+	pemOrHex, ok := keys[sig.DID]
+	if !ok {
+		check.Reason = "public key of DID is unknown; register it with POST /api/admin/did-keys"
+		return check
+	}
+	pub, err := util.ParsePublicKey(pemOrHex)
+	if err != nil {
+		check.Reason = fmt.Sprintf("stored public key unusable: %v", err)
+		return check
+	}
+
+	if err := util.VerifySignature(pub, sig.Message, sig.Signature); err != nil {
+		check.Status, check.Reason = VerificationFailed, err.Error()
+		return check
+	}
+
+	check.Status = VerificationVerified
+	return check
+}
+
+// summarizeSignatureChecks folds per-signature results into a block status:
+// failed if any signature fails, verified only if every signature verifies.
+func summarizeSignatureChecks(checks []SignatureCheck) models.BlockVerification {
+	checksJSON, _ := json.Marshal(checks)
+	v := models.BlockVerification{
+		Status:       VerificationVerified,
+		SenderStatus: VerificationUnverified,
+		Signatures:   datatypes.JSON(checksJSON),
+		VerifiedAt:   time.Now(),
+	}
+
+	if len(checks) == 0 {
+		v.Status, v.Reason = VerificationUnverified, "block carries no signatures"
+		return v
+	}
+
+	var failed, unchecked []string
+	for _, c := range checks {
+		switch c.Role {
+		case util.SignatureRoleSender:
+			v.SenderStatus = c.Status
+		case util.SignatureRoleQuorum:
+			v.QuorumTotal++
+			if c.Status == VerificationVerified {
+				v.QuorumVerified++
+			}
+		}
+
+		label := fmt.Sprintf("%s %s: %s", c.Role, c.DID, c.Reason)
+		switch c.Status {
+		case VerificationFailed:
+			failed = append(failed, label)
+		case VerificationUnverified:
+			unchecked = append(unchecked, label)
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		v.Status, v.Reason = VerificationFailed, strings.Join(failed, "; ")
+	case len(unchecked) > 0:
+		v.Status = VerificationUnverified
+		v.Reason = fmt.Sprintf("%d of %d signatures unchecked: %s",
+			len(unchecked), len(checks), strings.Join(unchecked, "; "))
+	}
+	return v
+}
+
+// saveBlockVerification upserts the verification result of a block
+func saveBlockVerification(v models.BlockVerification) error {
+	return repos.Blocks.SaveVerification(&v)
+}
+
+// verifyAndStoreBlock checks the signatures and hash of an ingested block.
+// Failures are logged and recorded, never fatal to ingestion. A hash mismatch is only a
+// warning until the hash recomputation is confirmed against blocks from a real fullnode.
+func verifyAndStoreBlock(block *util.TokenChainBlock, sourceNode string) {
+	v := VerifyBlockSignatures(block)
+
+	hash := util.CheckBlockHash(block.Raw)
+	v.HashStatus, v.ComputedHash = hash.Status, hash.ComputedHash
+
+	if err := saveBlockVerification(v); err != nil {
+		log.Printf("⚠️ Failed to store verification of %s: %v", block.BlockHash, err)
+		return
+	}
+	if v.Status == VerificationFailed {
+		log.Printf("🚨 Block %s failed signature verification: %s", block.BlockHash, v.Reason)
+	}
+	if hash.Status == util.HashMismatch {
+		log.Printf("⚠️ Block %s from %s has a hash mismatch (computed %s); stored anyway", block.BlockHash, sourceNode, hash.ComputedHash)
+	}
+}
+
+// ListUnverifiedBlocks returns stored blocks with signatures that could not be checked,
+// mostly because the signer's public key was never registered
+func ListUnverifiedBlocks(limit, page int) ([]models.BlockVerification, int64, error) {
+	return repos.Blocks.ListVerificationsByStatus(VerificationUnverified, repository.NewPage(limit, page))
+}
+
+// GetBlockVerification returns the stored verification of a block by block hash or txn ID,
+// or nil when the block was never verified.
+func GetBlockVerification(id string) *models.BlockVerification {
+	if id == "" {
+		return nil
+	}
+
+	v, err := repos.Blocks.GetVerification(id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("⚠️ Failed to load verification of %s: %v", id, err)
+		}
+		return nil
+	}
+	return v
+}
+
+// RegisterDIDKey stores a DID's public key and re-checks the unverified blocks it signed.
+// It returns the number of blocks re-checked.
+func RegisterDIDKey(did, publicKey, source string) (int, error) {
+	if _, err := util.ParsePublicKey(publicKey); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	key := models.DIDKey{DID: did, PublicKey: strings.TrimSpace(publicKey), Source: source, UpdatedAt: time.Now()}
+	if err := repos.DIDs.SaveDIDKey(&key); err != nil {
+		return 0, err
+	}
+
+	return reverifyBlocksSignedBy(did)
+}
+
+// reverifyBlocksSignedBy re-checks stored signatures of unverified blocks signed by did
+func reverifyBlocksSignedBy(did string) (int, error) {
+	pending, err := repos.Blocks.ListVerificationsSignedBy(VerificationUnverified, did)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, prev := range pending {
+		var checks []SignatureCheck
+		if err := json.Unmarshal(prev.Signatures, &checks); err != nil {
+			log.Printf("⚠️ Skipping re-verification of %s: %v", prev.BlockHash, err)
+			continue
+		}
+
+		sigs := make([]util.BlockSignature, len(checks))
+		for i, c := range checks {
+			sigs[i] = c.BlockSignature
+		}
+		keys := loadDIDKeys(sigs)
+		for i, sig := range sigs {
+			checks[i] = checkSignature(sig, keys)
+		}
+
+		v := summarizeSignatureChecks(checks)
+		v.BlockHash, v.TxnID = prev.BlockHash, prev.TxnID
+		v.HashStatus, v.ComputedHash = prev.HashStatus, prev.ComputedHash
+		if err := saveBlockVerification(v); err != nil {
+			return 0, err
+		}
+	}
+
+	log.Printf("🔑 Re-verified %d blocks signed by %s", len(pending), did)
+	return len(pending), nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// chainStoreBatchSize is how many stored blocks are loaded at a time when replaying a chain
+const chainStoreBatchSize = 200
+
+// storeChainBlock keeps the raw block at the given height of a token's local chain
+func storeChainBlock(tokenID string, height int64, block map[string]interface{}) error {
+	hash := chainBlockHash(block)
+	if tokenID == "" || hash == "" {
+		return nil
+	}
+
+	raw, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+	txnType, _ := getValue(block, "2", "TCTransTypeKey").(string)
+
+	row := models.TokenChainBlock{
+		TokenID:     tokenID,
+		BlockHash:   hash,
+		BlockHeight: height,
+		TxnType:     util.NormalizeTransType(txnType),
+		Block:       datatypes.JSON(raw),
+		StoredAt:    time.Now(),
+	}
+
+	if err := repos.Blocks.SaveChainBlock(&row); err != nil {
+		log.Printf("⚠️ Failed to store chain block %s of %s: %v", hash, tokenID, err)
+		return err
+	}
+	return nil
+}
+
+// storePushedChainBlock adds a pushed block to the local chain of every token it moves.
+// Heights come from the block's token info, or follow the last stored block when missing.
+func storePushedChainBlock(block *util.TokenChainBlock) {
+	for tokenID, info := range block.TransInfo.Tokens {
+		height := info.Height()
+		if info.BlockNumber == "" {
+			height = nextChainHeight(tokenID)
+		}
+		storeChainBlock(tokenID, height, block.Raw)
+	}
+}
+
+func nextChainHeight(tokenID string) int64 {
+	stats, err := repos.Blocks.ChainStats(tokenID)
+	if err != nil {
+		log.Printf("⚠️ Failed to read chain height of %s: %v", tokenID, err)
+	}
+	if stats.MaxHeight == nil {
+		return 0
+	}
+	return *stats.MaxHeight + 1
+}
+
+// clearStoredChain drops a token's local chain, e.g. before a full resync after a reorg
+func clearStoredChain(tokenID string) {
+	if err := repos.Blocks.DeleteChain(tokenID); err != nil {
+		log.Printf("⚠️ Failed to clear stored chain of %s: %v", tokenID, err)
+	}
+}
+
+// storedChainLength returns the number of locally stored blocks of a token when the stored
+// chain is complete (heights 0..n-1 without gaps), and 0 otherwise.
+func storedChainLength(tokenID string) int {
+	stats, err := repos.Blocks.ChainStats(tokenID)
+	if err != nil {
+		log.Printf("⚠️ Failed to read stored chain of %s: %v", tokenID, err)
+		return 0
+	}
+
+	if stats.Count == 0 || stats.MinHeight == nil || stats.MaxHeight == nil {
+		return 0
+	}
+	if *stats.MinHeight != 0 || *stats.MaxHeight != stats.Count-1 {
+		return 0
+	}
+	return int(stats.Count)
+}
+
+// storedChainPage loads one page of a token's local chain in height order
+func storedChainPage(tokenID string, offset, limit int) ([]map[string]interface{}, error) {
+	rows, err := repos.Blocks.ListChainBlocks(tokenID, repository.Page{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return decodeStoredBlocks(rows)
+}
+
+// forEachStoredBlock replays a token's local chain in height order, one page at a time
+func forEachStoredBlock(tokenID string, fn func(block map[string]interface{}) error) (int, error) {
+	count := 0
+	for offset := 0; ; offset += chainStoreBatchSize {
+		blocks, err := storedChainPage(tokenID, offset, chainStoreBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, block := range blocks {
+			if err := fn(block); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(blocks) < chainStoreBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func decodeStoredBlocks(rows []models.TokenChainBlock) ([]map[string]interface{}, error) {
+	blocks := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		var block map[string]interface{}
+		if err := util.DecodeNodeJSON(row.Block, &block); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"explorer-server/database/models"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TokenChainStream describes the non-block parts of a get-token-chain response
+type TokenChainStream struct {
+	// Fields holds the top-level fields other than the block array (status, message, ...)
+	Fields map[string]interface{}
+	// BlocksKey is the key the block array was found under; empty when there was none
+	BlocksKey string
+	// BlockCount is the number of blocks handed to the callback
+	BlockCount int
+}
+
+// Failed reports whether the fullnode answered with "status": false
+func (s TokenChainStream) Failed() bool {
+	status, ok := s.Fields["status"].(bool)
+	return ok && !status
+}
+
+// FieldKeys returns the top-level keys seen in the response, for diagnostics
+func (s TokenChainStream) FieldKeys() []string {
+	keys := make([]string, 0, len(s.Fields)+1)
+	for k := range s.Fields {
+		keys = append(keys, k)
+	}
+	if s.BlocksKey != "" {
+		keys = append(keys, s.BlocksKey)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeTokenChainStream walks a get-token-chain response one block at a time.
+// Blocks under "TokenChainData" (or "blocks") are decoded individually and handed to fn,
+// so memory use does not grow with the chain length. Non-object entries are skipped.
+func decodeTokenChainStream(r io.Reader, fn func(block map[string]interface{}) error) (TokenChainStream, error) {
+	stream := TokenChainStream{Fields: map[string]interface{}{}}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return stream, err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return stream, err
+		}
+		key, _ := tok.(string)
+
+		if (key == "TokenChainData" || key == "blocks") && stream.BlocksKey == "" {
+			stream.BlocksKey = key
+			if err := decodeBlockArray(dec, &stream, fn); err != nil {
+				return stream, err
+			}
+			continue
+		}
+
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return stream, err
+		}
+		stream.Fields[key] = value
+	}
+
+	return stream, expectDelim(dec, '}')
+}
+
+// decodeBlockArray decodes the elements of the block array (or a null) one by one
+func decodeBlockArray(dec *json.Decoder, stream *TokenChainStream, fn func(block map[string]interface{}) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("expected block array, got %v", tok)
+	}
+
+	for dec.More() {
+		var element interface{}
+		if err := dec.Decode(&element); err != nil {
+			return err
+		}
+		block, ok := element.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		stream.BlockCount++
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q in token chain response, got %v", want, tok)
+	}
+	return nil
+}
+
+// openTokenChain requests a token chain from the fullnode upstreams, retrying with backoff.
+// The fullnode slot stays held while the body is read: the caller must close the returned
+// response body and then call release.
+func openTokenChain(token models.TokenType, maxRetries int) (*http.Response, string, func(), error) {
+	apiPath := fmt.Sprintf("/api/de-exp/get-token-chain?tokenID=%s&tokenType=%s",
+		token.TokenID, token.TokenType)
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		release := acquireNodeSlot()
+		resp, nodeURL, err := nodeGet(apiPath)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			return resp, nodeURL, release, nil
+		}
+
+		if err != nil {
+			log.Printf("❌ Attempt %d: Error fetching chain for %s: %v",
+				attempt+1, token.TokenID, err)
+			lastErr = err
+		} else {
+			log.Printf("❌ Attempt %d: Bad status %d for token %s",
+				attempt+1, resp.StatusCode, token.TokenID)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("fullnode returned status %d for token %s", resp.StatusCode, token.TokenID)
+		}
+		release()
+
+		if attempt < maxRetries-1 {
+			backoff := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
+			time.Sleep(backoff)
+		}
+	}
+
+	return nil, "", nil, lastErr
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"explorer-server/database/models"
+)
+
+// TestTokenChainHoldsNodeSlotWhileStreaming checks that the fullnode slot taken for a chain
+// request is held until the caller has read the body, not just until the headers arrive
+func TestTokenChainHoldsNodeSlotWhileStreaming(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":true,"TokenChainData":[]}`)
+	}))
+	defer node.Close()
+	prev := upstreams
+	SetNodeUpstreams([]string{node.URL})
+	defer func() { upstreams = prev }()
+
+	resp, _, release, err := openTokenChain(models.TokenType{TokenID: "QmToken1", TokenType: "0"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if held := len(nodeReqLimiter); held != 1 {
+		t.Fatalf("%d node slots held while the body is unread, want 1", held)
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	release()
+	if held := len(nodeReqLimiter); held != 0 {
+		t.Fatalf("%d node slots held after release, want 0", held)
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"log"
+	"sort"
+
+	"explorer-server/database/models"
+	"explorer-server/repository"
+)
+
+// didRecomputeBatch bounds the DIDs rederived in one transaction
+const didRecomputeBatch = 500
+
+// recomputeDIDTotals rederives the stored totals of dids from the token tables.
+// Empty and repeated DIDs are ignored.
+func recomputeDIDTotals(dids ...string) error {
+	seen := make(map[string]bool, len(dids))
+	var batch []string
+	for _, did := range dids {
+		if did == "" || seen[did] {
+			continue
+		}
+		seen[did] = true
+		batch = append(batch, did)
+	}
+	sort.Strings(batch)
+
+	for start := 0; start < len(batch); start += didRecomputeBatch {
+		end := start + didRecomputeBatch
+		if end > len(batch) {
+			end = len(batch)
+		}
+		if _, err := repos.DIDs.RecomputeDIDs(batch[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DIDTotalsMismatch is a DID whose stored totals disagree with the token tables.
+// Stored is nil when the DID holds tokens but has no row.
+type DIDTotalsMismatch struct {
+	DID     string       `json:"did"`
+	Stored  *models.DIDs `json:"stored"`
+	Derived models.DIDs  `json:"derived"`
+	Fields  []string     `json:"fields"`
+}
+
+// DIDAuditReport is the outcome of one audit of the DID totals
+type DIDAuditReport struct {
+	Checked    int                 `json:"checked"`
+	Mismatches []DIDTotalsMismatch `json:"mismatches"`
+	Repaired   bool                `json:"repaired"`
+}
+
+// AuditDIDTotals compares every stored DID, and every DID holding a token, with the
+// totals derived from the token tables. With repair set the mismatching DIDs are
+// recomputed afterwards.
+func AuditDIDTotals(repair bool) (*DIDAuditReport, error) {
+	derived, err := repos.DIDs.DerivedDIDTotals(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DIDAuditReport{Mismatches: []DIDTotalsMismatch{}}
+	page := repository.Page{Limit: didRecomputeBatch}
+	for {
+		stored, err := repos.DIDs.ListDIDs(page)
+		if err != nil {
+			return nil, err
+		}
+		for i := range stored {
+			want, ok := derived[stored[i].DID]
+			if !ok {
+				want = models.DIDs{DID: stored[i].DID}
+			}
+			delete(derived, stored[i].DID)
+			report.Checked++
+
+			if fields := differingTotals(stored[i], want); len(fields) > 0 {
+				report.Mismatches = append(report.Mismatches, DIDTotalsMismatch{
+					DID: stored[i].DID, Stored: &stored[i], Derived: want, Fields: fields,
+				})
+			}
+		}
+		if len(stored) < page.Limit {
+			break
+		}
+		page.Offset += page.Limit
+	}
+
+	// DIDs holding tokens without a row of their own
+	for did, want := range derived {
+		report.Checked++
+		report.Mismatches = append(report.Mismatches, DIDTotalsMismatch{
+			DID: did, Derived: want, Fields: differingTotals(models.DIDs{}, want),
+		})
+	}
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		return report.Mismatches[i].DID < report.Mismatches[j].DID
+	})
+
+	if len(report.Mismatches) > 0 {
+		log.Printf("⚠️ DID audit: %d of %d DIDs disagree with the token tables", len(report.Mismatches), report.Checked)
+	}
+
+	if repair && len(report.Mismatches) > 0 {
+		dids := make([]string, len(report.Mismatches))
+		for i, m := range report.Mismatches {
+			dids[i] = m.DID
+		}
+		if err := recomputeDIDTotals(dids...); err != nil {
+			return report, err
+		}
+		report.Repaired = true
+		log.Printf("✅ DID audit: repaired %d DIDs", len(dids))
+	}
+
+	return report, nil
+}
+
+// differingTotals names the totals that differ between a stored and a derived DID row
+func differingTotals(stored, derived models.DIDs) []string {
+	var fields []string
+	if stored.TotalRBTs != derived.TotalRBTs {
+		fields = append(fields, "total_rbts")
+	}
+	if stored.TotalFTs != derived.TotalFTs {
+		fields = append(fields, "total_fts")
+	}
+	if stored.TotalNFTs != derived.TotalNFTs {
+		fields = append(fields, "total_nfts")
+	}
+	if stored.TotalSC != derived.TotalSC {
+		fields = append(fields, "total_sc")
+	}
+	return fields
+}
@@ -1,34 +1,24 @@
 package services
 
 import (
-	"explorer-server/database"
 	"explorer-server/database/models"
 	"explorer-server/model"
+	"explorer-server/repository"
 )
 
 // GetRBTCount returns the total number of RBTs in the database
 func GetDIDCount() (int64, error) {
-	var count int64
-	if err := database.DB.Model(&models.DIDs{}).Count(&count).Error; err != nil {
-		return 0, err
-	}
-	return count, nil
+	return repos.DIDs.CountDIDs()
 }
 
 func GetDIDInfoFromDID(did string) (*models.DIDs, error) {
-	var didInfo models.DIDs
-	if err := database.DB.First(&didInfo, "did = ?", did).Error; err != nil {
-		return nil, err
-	}
-	return &didInfo, nil
+	return repos.DIDs.GetDID(did)
 }
 
 func GetDIDHoldersList(limit, page int) (interface{}, error) {
-	var dids []models.DIDs
-	offset := (page - 1) * limit
-
 	// Fetch paginated DIDs ordered by TotalRBTs descending
-	if err := database.DB.Where("did IS NOT NULL AND did != '0'").Order("total_rbts desc").Limit(limit).Offset(offset).Find(&dids).Error; err != nil {
+	dids, err := repos.DIDs.ListRBTHolders(repository.Page{Limit: limit, Offset: (page - 1) * limit})
+	if err != nil {
     return nil, err
 }
 	// Map to response format
@@ -41,8 +31,8 @@ func GetDIDHoldersList(limit, page int) (interface{}, error) {
 	}
 
 	// Get total count of DIDs
-	var count int64
-	if err := database.DB.Model(&models.DIDs{}).Count(&count).Error; err != nil {
+	count, err := repos.DIDs.CountDIDs()
+	if err != nil {
 		return nil, err
 	}
 
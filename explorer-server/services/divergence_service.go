@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Divergence kinds
+const (
+	DivergenceHashMismatch   = "hash_mismatch"
+	DivergenceLengthMismatch = "length_mismatch"
+	DivergenceBrokenLinkage  = "broken_linkage"
+)
+
+// ErrNotEnoughNodes is returned when fewer than two healthy fullnodes are available for verification
+var ErrNotEnoughNodes = errors.New("at least two healthy fullnodes are required for chain verification")
+
+// chainBlockSummary keeps only what is needed to compare chains across nodes
+type chainBlockSummary struct {
+	Hash   string
+	PrevID string
+}
+
+type nodeChain struct {
+	node   string
+	blocks []chainBlockSummary
+}
+
+// fetchChainSummary downloads a token chain from one upstream and reduces it to hashes and prev links
+func fetchChainSummary(u *nodeUpstream, token models.TokenType) ([]chainBlockSummary, error) {
+	apiPath := fmt.Sprintf("/api/de-exp/get-token-chain?tokenID=%s&tokenType=%s",
+		token.TokenID, token.TokenType)
+
+	release := acquireNodeSlot()
+	defer release()
+
+	resp, err := nodeGetFrom(u, apiPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("fullnode %s returned status %d", u.url, resp.StatusCode)
+	}
+
+	var summaries []chainBlockSummary
+	stream, err := decodeTokenChainStream(resp.Body, func(block map[string]interface{}) error {
+		summaries = append(summaries, chainBlockSummary{
+			Hash:   chainBlockHash(block),
+			PrevID: chainPrevBlockID(block, token.TokenID),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error decoding chain from %s: %w", u.url, err)
+	}
+	// a refused request is not an empty chain
+	if stream.Failed() {
+		return nil, fmt.Errorf("fullnode %s refused the chain: %v", u.url, stream.Fields["message"])
+	}
+	return summaries, nil
+}
+
+// chainPrevBlockID extracts the previous block ID of tokenID from a block in numeric or named key format
+func chainPrevBlockID(block map[string]interface{}, tokenID string) string {
+	transInfo := getMap(block, "5", "TCTransInfoKey")
+	tokens := getMap(transInfo, "6", "TITokensKey")
+
+	tokenInfo, ok := tokens[tokenID].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	prev, _ := getValue(tokenInfo, "5", "TTPreviousBlockIDKey").(string)
+	return prev
+}
+
+// linksTo reports whether a previous-block ID ("<height>-<hash>" or "<hash>") references hash
+func linksTo(prevID, hash string) bool {
+	return prevID == hash || strings.HasSuffix(prevID, "-"+hash)
+}
+
+// compareChains returns divergences between a reference chain and another node's chain
+func compareChains(token models.TokenType, ref, other nodeChain) []models.ChainDivergence {
+	var found []models.ChainDivergence
+	now := time.Now()
+
+	common := len(ref.blocks)
+	if len(other.blocks) < common {
+		common = len(other.blocks)
+	}
+
+	for i := 0; i < common; i++ {
+		if ref.blocks[i].Hash != other.blocks[i].Hash {
+			// chains forked here; everything after is expected to differ
+			return append(found, models.ChainDivergence{
+				TokenID:    token.TokenID,
+				TokenType:  token.TokenType,
+				BlockIndex: i,
+				Kind:       DivergenceHashMismatch,
+				NodeA:      ref.node,
+				NodeB:      other.node,
+				HashA:      ref.blocks[i].Hash,
+				HashB:      other.blocks[i].Hash,
+				Detail:     fmt.Sprintf("chains fork at block %d", i),
+				DetectedAt: now,
+			})
+		}
+	}
+
+	if len(ref.blocks) != len(other.blocks) {
+		d := models.ChainDivergence{
+			TokenID:    token.TokenID,
+			TokenType:  token.TokenType,
+			BlockIndex: common,
+			Kind:       DivergenceLengthMismatch,
+			NodeA:      ref.node,
+			NodeB:      other.node,
+			Detail: fmt.Sprintf("%s has %d blocks, %s has %d blocks",
+				ref.node, len(ref.blocks), other.node, len(other.blocks)),
+			DetectedAt: now,
+		}
+		if common < len(ref.blocks) {
+			d.HashA = ref.blocks[common].Hash
+		}
+		if common < len(other.blocks) {
+			d.HashB = other.blocks[common].Hash
+		}
+		found = append(found, d)
+	}
+
+	return found
+}
+
+// checkChainLinkage returns divergences where a block does not point at its predecessor
+func checkChainLinkage(token models.TokenType, chain nodeChain) []models.ChainDivergence {
+	var found []models.ChainDivergence
+
+	for i := 1; i < len(chain.blocks); i++ {
+		prevID := chain.blocks[i].PrevID
+		if prevID == "" || linksTo(prevID, chain.blocks[i-1].Hash) {
+			continue
+		}
+		found = append(found, models.ChainDivergence{
+			TokenID:    token.TokenID,
+			TokenType:  token.TokenType,
+			BlockIndex: i,
+			Kind:       DivergenceBrokenLinkage,
+			NodeA:      chain.node,
+			HashA:      chain.blocks[i].Hash,
+			HashB:      chain.blocks[i-1].Hash,
+			Detail:     fmt.Sprintf("block %d points at %s instead of the previous block", i, prevID),
+			DetectedAt: time.Now(),
+		})
+	}
+	return found
+}
+
+// VerifyTokenChainAcrossNodes fetches a token chain from every healthy fullnode,
+// compares the chains block by block and records any disagreement.
+func VerifyTokenChainAcrossNodes(token models.TokenType) ([]models.ChainDivergence, error) {
+	var healthy []*nodeUpstream
+	for _, u := range nodeUpstreams() {
+		if u.isHealthy() {
+			healthy = append(healthy, u)
+		}
+	}
+	if len(healthy) < 2 {
+		return nil, ErrNotEnoughNodes
+	}
+
+	var chains []nodeChain
+	for _, u := range healthy {
+		blocks, err := fetchChainSummary(u, token)
+		if err != nil {
+			log.Printf("⚠️ Skipping %s for verification of %s: %v", u.url, token.TokenID, err)
+			continue
+		}
+		chains = append(chains, nodeChain{node: u.url, blocks: blocks})
+	}
+	if len(chains) < 2 {
+		return nil, ErrNotEnoughNodes
+	}
+
+	var divergences []models.ChainDivergence
+	for _, chain := range chains {
+		divergences = append(divergences, checkChainLinkage(token, chain)...)
+	}
+	for _, other := range chains[1:] {
+		divergences = append(divergences, compareChains(token, chains[0], other)...)
+	}
+
+	if len(divergences) > 0 {
+		if err := repos.Sync.SaveDivergences(divergences); err != nil {
+			log.Printf("❌ Failed to record divergences for %s: %v", token.TokenID, err)
+		}
+		log.Printf("⚠️ Token %s diverges across fullnodes (%d findings)", token.TokenID, len(divergences))
+	}
+
+	return divergences, nil
+}
+
+// VerifyTokenChainByID looks up the token type and verifies the chain across fullnodes
+func VerifyTokenChainByID(tokenID string) ([]models.ChainDivergence, error) {
+	tokenType, err := GetAssetType(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyTokenChainAcrossNodes(models.TokenType{TokenID: tokenID, TokenType: tokenType})
+}
+
+// GetChainDivergences lists recorded divergences, newest first, optionally filtered by token
+func GetChainDivergences(tokenID string, limit, page int) ([]models.ChainDivergence, int64, error) {
+	return repos.Sync.ListDivergences(tokenID, repository.NewPage(limit, page))
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/model"
+	"explorer-server/repository"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Failed-sync sources
+const (
+	FailedSyncSourceFullnode = "fullnode"
+	FailedSyncSourceSync     = "sync"
+)
+
+// Failed-sync statuses
+const (
+	FailedSyncPending   = repository.FailedSyncPending
+	FailedSyncRetrying  = repository.FailedSyncRetrying
+	FailedSyncExhausted = repository.FailedSyncExhausted
+	FailedSyncDiscarded = repository.FailedSyncDiscarded
+)
+
+const (
+	maxFailedSyncAttempts = 8
+	failedSyncBaseDelay   = time.Minute
+	failedSyncMaxDelay    = 6 * time.Hour
+	failedSyncBatchSize   = 100
+)
+
+// ErrFailedSyncNotFound is returned when a dead-letter entry does not exist
+var ErrFailedSyncNotFound = errors.New("failed sync entry not found")
+
+// ErrFailedSyncRetrying is returned when a retry is requested for an entry already being retried
+var ErrFailedSyncRetrying = errors.New("failed sync entry is already being retried")
+
+// fullnode token type codes that map directly onto a get-token-chain type
+var nodeTokenTypeNames = map[int]string{
+	0: RBTType,
+	1: PartType,
+	2: NFTType,
+}
+
+// failedSyncRetryDelay returns the exponential backoff delay after the given number of attempts
+func failedSyncRetryDelay(attempts int) time.Duration {
+	delay := failedSyncBaseDelay
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if delay >= failedSyncMaxDelay {
+			return failedSyncMaxDelay
+		}
+	}
+	return delay
+}
+
+// RecordFailedTokenSync stores (or refreshes) a dead-letter entry for a token whose chain sync failed
+func RecordFailedTokenSync(token models.TokenType, source string, syncErr error) {
+	now := time.Now()
+	entry := models.FailedTokenSync{
+		TokenID:     token.TokenID,
+		TokenType:   token.TokenType,
+		Source:      source,
+		Status:      FailedSyncPending,
+		LastError:   syncErr.Error(),
+		NextRetryAt: now.Add(failedSyncRetryDelay(0)),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	// Keep the retry schedule of an existing entry, only refresh the error
+	if err := repos.Sync.RecordFailedSync(&entry); err != nil {
+		log.Printf("❌ Failed to record failed sync for %s: %v", token.TokenID, err)
+	}
+}
+
+// recordNodeFailedToken stores a failed-token notification pushed by the fullnode
+func recordNodeFailedToken(info model.FailedToSyncTokenDetailsInfo) error {
+	tokenType, err := GetAssetType(info.TokenID)
+	if err != nil {
+		tokenType = nodeTokenTypeNames[info.TokenType]
+	}
+
+	now := time.Now()
+	entry := models.FailedTokenSync{
+		TokenID:       info.TokenID,
+		TokenType:     tokenType,
+		NodeTokenType: info.TokenType,
+		DID:           info.Did,
+		AssetType:     info.AssetType,
+		Source:        FailedSyncSourceFullnode,
+		Status:        FailedSyncPending,
+		LastError:     "reported by fullnode",
+		NextRetryAt:   now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	return repos.Sync.RecordNodeFailure(&entry)
+}
+
+// resolveFailedTokenSync removes a dead-letter entry once the token synced successfully
+func resolveFailedTokenSync(tokenID string) {
+	if err := repos.Sync.ResolveFailedSync(tokenID); err != nil {
+		log.Printf("⚠️ Failed to resolve failed sync for %s: %v", tokenID, err)
+	}
+}
+
+// retryFailedTokenSync re-runs the chain sync for one dead-letter entry and reschedules it on failure
+func retryFailedTokenSync(entry models.FailedTokenSync) {
+	token := models.TokenType{TokenID: entry.TokenID, TokenType: entry.TokenType}
+	if token.TokenType == "" {
+		if tokenType, err := GetAssetType(entry.TokenID); err == nil {
+			token.TokenType = tokenType
+		}
+	}
+
+	var err error
+	if token.TokenType == "" {
+		err = fmt.Errorf("unknown token type for %s", entry.TokenID)
+	} else {
+		cp, _ := repos.Sync.GetCheckpoint(entry.TokenID)
+
+		err = verifyBeforeSync(token)
+		if err == nil {
+			err = fetchAndStoreTokenChain(token, cp)
+		}
+	}
+
+	if err == nil {
+		log.Printf("✅ Retried failed sync for %s after %d attempts", entry.TokenID, entry.Attempts+1)
+		resolveFailedTokenSync(entry.TokenID)
+		return
+	}
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+	entry.Status = FailedSyncPending
+	entry.NextRetryAt = time.Now().Add(failedSyncRetryDelay(entry.Attempts))
+	entry.UpdatedAt = time.Now()
+	if entry.Attempts >= maxFailedSyncAttempts {
+		entry.Status = FailedSyncExhausted
+		log.Printf("⚠️ Giving up on %s after %d attempts: %v", entry.TokenID, entry.Attempts, err)
+	}
+
+	if dbErr := repos.Sync.SaveFailedSync(&entry); dbErr != nil {
+		log.Printf("❌ Failed to reschedule failed sync for %s: %v", entry.TokenID, dbErr)
+	}
+}
+
+// scheduleFailedTokenSync claims an entry and enqueues its retry on the sync pool
+func scheduleFailedTokenSync(entry models.FailedTokenSync) {
+	claimed, err := repos.Sync.SetFailedSyncStatus(entry.TokenID, FailedSyncPending, FailedSyncRetrying)
+	if err != nil || !claimed {
+		return
+	}
+
+	if !EnqueueBackgroundSyncTask(func() { retryFailedTokenSync(entry) }) {
+		// sync queue full → release the claim and try again on the next tick
+		repos.Sync.SetFailedSyncStatus(entry.TokenID, "", FailedSyncPending)
+	}
+}
+
+// retryDueFailedSyncs schedules every pending entry whose backoff has elapsed
+func retryDueFailedSyncs() {
+	due, err := repos.Sync.ListDueFailedSyncs(time.Now(), failedSyncBatchSize)
+	if err != nil {
+		log.Printf("❌ Failed to load due failed syncs: %v", err)
+		return
+	}
+
+	for _, entry := range due {
+		scheduleFailedTokenSync(entry)
+	}
+}
+
+// StartFailedSyncRetryLoop periodically retries dead-letter entries on the sync pool
+func StartFailedSyncRetryLoop(interval time.Duration) {
+	// Entries claimed by a previous process never finished; make them eligible again
+	if err := repos.Sync.ReleaseFailedSyncClaims(); err != nil {
+		log.Printf("⚠️ Failed to release stale failed-sync claims: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			retryDueFailedSyncs()
+		}
+	}()
+	log.Printf("🔁 Failed-sync retry loop started (every %s)", interval)
+}
+
+// ListFailedTokenSyncs returns dead-letter entries, optionally filtered by status
+func ListFailedTokenSyncs(status string, limit, page int) ([]models.FailedTokenSync, int64, error) {
+	return repos.Sync.ListFailedSyncs(status, repository.NewPage(limit, page))
+}
+
+// RetryFailedTokenSync schedules an immediate retry of an entry that is not already being retried
+func RetryFailedTokenSync(tokenID string) error {
+	entry, err := repos.Sync.GetFailedSync(tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrFailedSyncNotFound
+		}
+		return err
+	}
+
+	if entry.Status == FailedSyncRetrying {
+		return ErrFailedSyncRetrying
+	}
+
+	// claim the entry in the status it was read in, so a concurrent request or the retry
+	// loop cannot schedule it a second time
+	claimed, err := repos.Sync.SetFailedSyncStatus(tokenID, entry.Status, FailedSyncRetrying)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrFailedSyncRetrying
+	}
+
+	if !EnqueueBackgroundSyncTask(func() { retryFailedTokenSync(*entry) }) {
+		// sync queue full → make the entry due so the retry loop picks it up
+		entry.Status = FailedSyncPending
+		entry.NextRetryAt = time.Now()
+		entry.UpdatedAt = time.Now()
+		return repos.Sync.SaveFailedSync(entry)
+	}
+	return nil
+}
+
+// DiscardFailedTokenSync stops retrying an entry but keeps it for reference
+func DiscardFailedTokenSync(tokenID string) error {
+	discarded, err := repos.Sync.SetFailedSyncStatus(tokenID, "", FailedSyncDiscarded)
+	if err != nil {
+		return err
+	}
+	if !discarded {
+		return ErrFailedSyncNotFound
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"explorer-server/database/models"
+)
+
+// TestRetryOfEntryBeingRetriedIsRejected checks that a manual retry does not schedule an
+// entry a second time while its retry is running
+func TestRetryOfEntryBeingRetriedIsRejected(t *testing.T) {
+	mem := useMemoryRepos(t)
+
+	due := time.Now().Add(time.Hour)
+	if err := mem.Sync.SaveFailedSync(&models.FailedTokenSync{
+		TokenID: "QmToken1", TokenType: RBTType, Status: FailedSyncRetrying, NextRetryAt: due,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RetryFailedTokenSync("QmToken1"); !errors.Is(err, ErrFailedSyncRetrying) {
+		t.Fatalf("RetryFailedTokenSync = %v, want ErrFailedSyncRetrying", err)
+	}
+	entry, err := mem.Sync.GetFailedSync("QmToken1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != FailedSyncRetrying || !entry.NextRetryAt.Equal(due) {
+		t.Errorf("entry changed to %s due %s", entry.Status, entry.NextRetryAt)
+	}
+
+	if err := RetryFailedTokenSync("QmUnknown"); !errors.Is(err, ErrFailedSyncNotFound) {
+		t.Errorf("RetryFailedTokenSync(unknown) = %v, want ErrFailedSyncNotFound", err)
+	}
+}
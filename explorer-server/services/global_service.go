@@ -1,14 +1,12 @@
 package services
 
 import (
-	"encoding/json"
-	"errors"
-	"explorer-server/config"
-	"explorer-server/database"
 	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"explorer-server/util"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
 	"strings"
 )
 
@@ -30,194 +28,221 @@ var transactionTypeNames = map[string]string{
 }
 
 func GetAssetType(id string) (string, error) {
-	var asset models.TokenType
-
-	result := database.DB.Where("token_id = ?", id).First(&asset)
-	if result.Error != nil {
-		return "", fmt.Errorf("failed to fetch asset type: %w", result.Error)
+	asset, err := repos.Tokens.GetTokenType(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch asset type: %w", err)
 	}
 
 	if asset.TokenType == "" {
-		return "", errors.New("asset type not found")
+		return "", fmt.Errorf("asset type of %s: %w", id, repository.ErrNotFound)
 	}
 
 	return asset.TokenType, nil
 }
 
-// GetTokenChainFromTokenID fetches the complete token chain from the full node for a given tokenID
-func GetTokenChainFromTokenID(tokenID string) (map[string]interface{}, error) {
-	// Step 1: Get token type from database
+// chainTokenType returns the get-token-chain type of a token; RBTs worth less than 1 are PART tokens
+func chainTokenType(tokenID string) (string, error) {
 	tokenType, err := GetAssetType(tokenID)
 	if err != nil {
-		return nil, fmt.Errorf("❌ failed to get token type from asset table: %v", err)
+		return "", fmt.Errorf("❌ failed to get token type from asset table: %v", err)
 	}
 
-	// Step 2: For RBT, check value and possibly use PART
 	if strings.ToUpper(tokenType) == "RBT" {
 		rbt, err := GetRBTInfoFromRBTID(tokenID)
 		if err != nil {
-			return nil, fmt.Errorf("❌ failed to get RBT from RBT table: %v", err)
+			return "", fmt.Errorf("❌ failed to get RBT from RBT table: %v", err)
 		}
 
-		if rbt.TokenValue < 1.0 {
+		// fractional RBTs and tokens split from a whole RBT live on PART chains
+		if rbt.TokenValue.Cmp(decimal.FromInt(1)) < 0 || hasLineageParent(tokenID) {
 			tokenType = PartType
 		}
 	}
 
-	apiURL := fmt.Sprintf("%s/api/de-exp/get-token-chain?tokenID=%s&tokenType=%s",
-		config.RubixNodeURL, tokenID, tokenType)
+	return tokenType, nil
+}
 
-	client := GetNodeHTTPClient()
-	release := acquireNodeSlot()
-	defer release()
+// StreamTokenChainFromTokenID fetches the complete token chain from the full node for a given tokenID
+// and hands it to fn one block at a time. Errors returned before the first block are safe to report
+// to the client; the returned stream carries the non-block response fields.
+func StreamTokenChainFromTokenID(tokenID string, fn func(block map[string]interface{}) error) (TokenChainStream, error) {
+	if storedChainLength(tokenID) > 0 {
+		return streamStoredTokenChain(tokenID, fn)
+	}
 
-	resp, err := client.Get(apiURL)
+	tokenType, err := chainTokenType(tokenID)
 	if err != nil {
-		return nil, fmt.Errorf("❌ error fetching token chain for %s: %v", tokenID, err)
+		return TokenChainStream{}, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		io.Copy(io.Discard, resp.Body)
-		return nil, fmt.Errorf("❌ fullnode returned status %d for token %s", resp.StatusCode, tokenID)
+	resp, _, release, err := openTokenChain(models.TokenType{TokenID: tokenID, TokenType: tokenType}, 1)
+	if err != nil {
+		return TokenChainStream{}, fmt.Errorf("❌ error fetching token chain for %s: %w", tokenID, err)
 	}
+	defer release()
+	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// cache miss: keep the blocks while passing them on
+	index := 0
+	stream, err := decodeTokenChainStream(resp.Body, func(block map[string]interface{}) error {
+		cacheChainBlock(tokenID, index, block)
+		index++
+		return fn(block)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("❌ error reading response for %s: %v", tokenID, err)
+		return stream, fmt.Errorf("❌ error decoding JSON for %s: %v", tokenID, err)
 	}
-	fmt.Println("RAW DATA:", string(body))
 
-	var chainData map[string]interface{}
-	if err := json.Unmarshal(body, &chainData); err != nil {
-		return nil, fmt.Errorf("❌ error decoding JSON for %s: %v", tokenID, err)
+	return stream, nil
+}
+
+// streamStoredTokenChain replays a token chain from the local store in the shape of a fullnode response
+func streamStoredTokenChain(tokenID string, fn func(block map[string]interface{}) error) (TokenChainStream, error) {
+	stream := TokenChainStream{
+		Fields:    map[string]interface{}{"status": true, "message": "Token chain served from local store"},
+		BlocksKey: "TokenChainData",
 	}
 
-	return chainData, nil
+	count, err := forEachStoredBlock(tokenID, fn)
+	stream.BlockCount = count
+	if err != nil {
+		return stream, fmt.Errorf("❌ error reading stored chain for %s: %v", tokenID, err)
+	}
+	return stream, nil
 }
 
-// Fetches all blocks from a given token chain with pagination
-func GetTokenBlocksFromTokenID(tokenID string, page int, limit int) ([]map[string]interface{}, int, error) {
-	// Step 1: Get token type
-	tokenType, err := GetAssetType(tokenID)
-	if err != nil {
-		return nil, 0, fmt.Errorf("❌ failed to get token type from asset table: %v", err)
+// cacheChainBlock stores a block fetched on a cache miss; failures only cost a later node fetch
+func cacheChainBlock(tokenID string, index int, block map[string]interface{}) {
+	height := chainBlockHeight(block, tokenID)
+	if height == 0 {
+		height = int64(index)
 	}
+	storeChainBlock(tokenID, height, block)
+}
 
-	// Step 2: For RBT, check value and possibly use PART
-	if strings.ToUpper(tokenType) == "RBT" {
-		rbt, err := GetRBTInfoFromRBTID(tokenID)
+// Fetches all blocks from a given token chain with pagination.
+// Complete chains are paged from the local store; otherwise the chain is streamed from the
+// fullnode, stored locally, and only the blocks of the requested page are kept in memory.
+// With a format (raw or named) each entry also carries the full block in that format.
+func GetTokenBlocksFromTokenID(tokenID string, page int, limit int, format string) ([]map[string]interface{}, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	start := (page - 1) * limit
+	end := start + limit
+
+	if total := storedChainLength(tokenID); total > 0 {
+		blocks, err := storedChainPage(tokenID, start, limit)
 		if err != nil {
-			return nil, 0, fmt.Errorf("❌ failed to get RBT from RBT table: %v", err)
+			return nil, 0, fmt.Errorf("❌ error reading stored chain for %s: %v", tokenID, err)
 		}
 
-		if rbt.TokenValue < 1.0 {
-			tokenType = PartType
+		paginated := make([]map[string]interface{}, 0, len(blocks))
+		for _, block := range blocks {
+			entry, err := chainBlockEntry(block, format)
+			if err != nil {
+				return nil, 0, err
+			}
+			paginated = append(paginated, entry)
 		}
+		return paginated, total, nil
 	}
 
-	apiURL := fmt.Sprintf("%s/api/de-exp/get-token-chain?tokenID=%s&tokenType=%s",
-		config.RubixNodeURL, tokenID, tokenType)
-
-	client := GetNodeHTTPClient()
-	release := acquireNodeSlot()
-	defer release()
+	tokenType, err := chainTokenType(tokenID)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	resp, err := client.Get(apiURL)
+	resp, _, release, err := openTokenChain(models.TokenType{TokenID: tokenID, TokenType: tokenType}, 1)
 	if err != nil {
-		return nil, 0, fmt.Errorf("❌ error fetching token chain for %s: %v", tokenID, err)
+		return nil, 0, fmt.Errorf("❌ error fetching token chain for %s: %w", tokenID, err)
 	}
+	defer release()
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		io.Copy(io.Discard, resp.Body)
-		return nil, 0, fmt.Errorf("❌ fullnode returned status %d for token %s", resp.StatusCode, tokenID)
-	}
+	paginated := []map[string]interface{}{}
+	index := 0
 
-	body, err := io.ReadAll(resp.Body)
+	stream, err := decodeTokenChainStream(resp.Body, func(block map[string]interface{}) error {
+		cacheChainBlock(tokenID, index, block)
+		if index >= start && index < end {
+			entry, err := chainBlockEntry(block, format)
+			if err != nil {
+				return err
+			}
+			paginated = append(paginated, entry)
+		}
+		index++
+		return nil
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("❌ error reading response for %s: %v", tokenID, err)
-	}
-
-	var chainData map[string]interface{}
-	if err := json.Unmarshal(body, &chainData); err != nil {
 		return nil, 0, fmt.Errorf("❌ error decoding JSON for %s: %v", tokenID, err)
 	}
 
-	tokenChainData, ok := chainData["TokenChainData"].([]interface{})
-	if !ok || len(tokenChainData) == 0 {
+	if stream.BlocksKey != "TokenChainData" || stream.BlockCount == 0 {
 		return nil, 0, fmt.Errorf("❌ TokenChainData not found or empty for %s", tokenID)
 	}
 
-	var allBlocks []map[string]interface{}
-
-	for i, blk := range tokenChainData {
-		block, ok := blk.(map[string]interface{})
-		if !ok {
-			fmt.Printf("⚠️ Skipping invalid block at index %d for %s\n", i, tokenID)
-			continue
-		}
+	return paginated, stream.BlockCount, nil
+}
 
-		blockData := make(map[string]interface{})
+// ValidBlockFormat reports whether format is a supported block format (raw, named or empty)
+func ValidBlockFormat(format string) bool {
+	return util.ValidBlockFormat(format)
+}
 
-		blockHash := getValue(block, "98", "TCBlockHashKey")
-		owner := getValue(block, "3", "TCTokenOwnerKey")
-		epoch := getValue(block, "epoch", "TCEpoch")
-		transType := getValue(block, "2", "TCTransTypeKey")
-		transInfo := getMap(block, "5", "TCTransInfoKey")
+// ConvertBlockFormat returns a chain block in the numeric wire format (raw) or with named keys
+func ConvertBlockFormat(block map[string]interface{}, format string) (map[string]interface{}, error) {
+	return util.ConvertBlockFormat(block, format)
+}
 
-		if blockHash != nil {
-			blockData["block_hash"] = blockHash
-		}
-		if owner != nil {
-			blockData["owner_did"] = owner
-		}
-		if epoch != nil {
-			blockData["epoch"] = epoch
-		}
+// chainBlockEntry is one entry of a block page: the summary, plus the block itself when a format is requested
+func chainBlockEntry(block map[string]interface{}, format string) (map[string]interface{}, error) {
+	entry := summarizeChainBlock(block)
+	if format == "" {
+		return entry, nil
+	}
 
-		if transType != nil {
-			if transTypeStr, ok := transType.(string); ok {
-				if name, found := transactionTypeNames[transTypeStr]; found {
-					blockData["transaction_type"] = name
-				}
-			}
-		}
+	converted, err := util.ConvertBlockFormat(block, format)
+	if err != nil {
+		return nil, err
+	}
+	entry["block"] = converted
+	return entry, nil
+}
 
-		if len(transInfo) > 0 {
-			tid := getValue(transInfo, "4", "TITIDKey")
-			if tid != nil {
-				blockData["transaction_id"] = tid
-			}
-		}
+// summarizeChainBlock reduces a chain block to the fields shown in the block list
+func summarizeChainBlock(raw map[string]interface{}) map[string]interface{} {
+	blockData := make(map[string]interface{})
 
-		allBlocks = append(allBlocks, blockData)
+	block, err := util.DecodeTokenChainBlock(raw)
+	if block == nil {
+		return blockData
 	}
-
-	totalBlocks := len(allBlocks)
-	if totalBlocks == 0 {
-		return nil, 0, fmt.Errorf("❌ no valid token chain blocks found for %s", tokenID)
+	if err != nil {
+		log.Printf("⚠️ Partially decoded block %s: %v", block.BlockHash, err)
 	}
 
-	if page < 1 {
-		page = 1
+	if block.BlockHash != "" {
+		blockData["block_hash"] = block.BlockHash
 	}
-	if limit < 1 {
-		limit = 10
+	if block.TokenOwner != "" {
+		blockData["owner_did"] = block.TokenOwner
 	}
-
-	start := (page - 1) * limit
-	if start >= totalBlocks {
-		return []map[string]interface{}{}, totalBlocks, nil
+	if block.Epoch != nil {
+		blockData["epoch"] = *block.Epoch
 	}
-
-	end := start + limit
-	if end > totalBlocks {
-		end = totalBlocks
+	if name, found := transactionTypeNames[block.TransType]; found {
+		blockData["transaction_type"] = name
+	}
+	if block.TransInfo.TID != "" {
+		blockData["transaction_id"] = block.TransInfo.TID
 	}
 
-	paginated := allBlocks[start:end]
-	return paginated, totalBlocks, nil
+	return blockData
 }
 
 // Helper: safely get value using either numeric or string key
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Inbox payload kinds
+const (
+	InboxKindBlock = "block"
+	InboxKindToken = "token"
+)
+
+// Inbox statuses
+const (
+	InboxPending    = repository.InboxPending
+	InboxProcessing = repository.InboxProcessing
+	InboxProcessed  = repository.InboxProcessed
+	InboxDead       = repository.InboxDead
+)
+
+const (
+	inboxBatchSize    = 50
+	inboxMaxAttempts  = 10
+	inboxLeaseTimeout = 5 * time.Minute
+	inboxBaseDelay    = 5 * time.Second
+	inboxMaxDelay     = 10 * time.Minute
+	inboxRetention    = 7 * 24 * time.Hour
+)
+
+// TokenUpdatePayload is the body of /api/token-update as stored in the inbox
+type TokenUpdatePayload struct {
+	Table     string      `json:"table"`
+	Data      interface{} `json:"data"`
+	Operation string      `json:"operation"`
+}
+
+// IngestInboxStatus counts inbox rows per kind and status
+type IngestInboxStatus = repository.IngestInboxStatus
+
+// inboxWake nudges the pollers when a new payload arrives
+var inboxWake = map[string]chan struct{}{
+	InboxKindBlock: make(chan struct{}, 1),
+	InboxKindToken: make(chan struct{}, 1),
+}
+
+// EnqueueInboxPayload durably stores an incoming push payload before it is processed
+func EnqueueInboxPayload(kind string, payload []byte) (uint64, error) {
+	now := time.Now()
+	row := models.IngestInbox{
+		Kind:          kind,
+		Payload:       datatypes.JSON(payload),
+		Status:        InboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	if err := repos.Sync.AddInboxRow(&row); err != nil {
+		return 0, err
+	}
+
+	select {
+	case inboxWake[kind] <- struct{}{}:
+	default:
+	}
+	return row.ID, nil
+}
+
+// inboxRetryDelay returns the backoff before the next attempt of a failed payload
+func inboxRetryDelay(attempts int) time.Duration {
+	delay := inboxBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= inboxMaxDelay {
+			return inboxMaxDelay
+		}
+	}
+	return delay
+}
+
+// claimInboxBatch leases due rows of one kind. Rows locked by another worker are skipped,
+// and rows whose lease expired (the worker died mid-flight) are claimed again.
+func claimInboxBatch(kind string, limit int) ([]models.IngestInbox, error) {
+	now := time.Now()
+	return repos.Sync.ClaimInboxRows(kind, now, now.Add(-inboxLeaseTimeout), limit)
+}
+
+// processInboxRow applies one payload; panics in the update path are turned into errors
+func processInboxRow(row models.IngestInbox) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while processing inbox row %d: %v", row.ID, r)
+		}
+	}()
+
+	switch row.Kind {
+	case InboxKindBlock:
+		var block map[string]interface{}
+		if err := util.DecodeNodeJSON(row.Payload, &block); err != nil {
+			return err
+		}
+		return UpdateBlocks(block)
+
+	case InboxKindToken:
+		var payload TokenUpdatePayload
+		if err := util.DecodeNodeJSON(row.Payload, &payload); err != nil {
+			return err
+		}
+		return UpdateTokens(payload.Table, payload.Data, payload.Operation)
+
+	default:
+		return fmt.Errorf("unknown inbox kind %q", row.Kind)
+	}
+}
+
+// completeInboxRow records the outcome of one processing attempt
+func completeInboxRow(row models.IngestInbox, procErr error) {
+	now := time.Now()
+	row.LockedAt = nil
+
+	switch {
+	case procErr == nil:
+		row.Status = InboxProcessed
+		row.ProcessedAt = &now
+		row.LastError = ""
+	case row.Attempts >= inboxMaxAttempts:
+		row.Status = InboxDead
+		row.LastError = procErr.Error()
+		log.Printf("⚠️ Inbox %s #%d given up after %d attempts: %v", row.Kind, row.ID, row.Attempts, procErr)
+	default:
+		row.Status = InboxPending
+		row.LastError = procErr.Error()
+		row.NextAttemptAt = now.Add(inboxRetryDelay(row.Attempts))
+		log.Printf("⚠️ Inbox %s #%d failed (attempt %d), retrying: %v", row.Kind, row.ID, row.Attempts, procErr)
+	}
+
+	if err := repos.Sync.SaveInboxRow(&row); err != nil {
+		log.Printf("❌ Failed to update inbox row %d: %v", row.ID, err)
+	}
+}
+
+// releaseInboxRow hands a leased row back without counting the attempt
+func releaseInboxRow(row models.IngestInbox) {
+	row.Status = InboxPending
+	row.LockedAt = nil
+	row.Attempts--
+	if err := repos.Sync.SaveInboxRow(&row); err != nil {
+		log.Printf("❌ Failed to release inbox row %d: %v", row.ID, err)
+	}
+}
+
+// drainInbox claims and dispatches batches of one kind until none are due
+func drainInbox(kind string, enqueue func(func()) bool) {
+	for {
+		rows, err := claimInboxBatch(kind, inboxBatchSize)
+		if err != nil {
+			log.Printf("❌ Failed to claim %s inbox rows: %v", kind, err)
+			return
+		}
+		if len(rows) == 0 {
+			return
+		}
+
+		for i, row := range rows {
+			row := row
+			if !enqueue(func() { completeInboxRow(row, processInboxRow(row)) }) {
+				// worker queue full → give the rest back and try again on the next tick
+				for _, rest := range rows[i:] {
+					releaseInboxRow(rest)
+				}
+				return
+			}
+		}
+
+		if len(rows) < inboxBatchSize {
+			return
+		}
+	}
+}
+
+// DrainIngestInbox processes every due payload on the calling goroutine, for callers
+// that need pushes applied before they go on
+func DrainIngestInbox() {
+	inline := func(task func()) bool {
+		task()
+		return true
+	}
+	drainInbox(InboxKindBlock, inline)
+	drainInbox(InboxKindToken, inline)
+}
+
+// pruneInbox removes processed payloads older than the retention window
+func pruneInbox() {
+	pruned, err := repos.Sync.PruneInbox(time.Now().Add(-inboxRetention))
+	if err != nil {
+		log.Printf("⚠️ Failed to prune ingest inbox: %v", err)
+	} else if pruned > 0 {
+		log.Printf("🧹 Pruned %d processed inbox rows", pruned)
+	}
+}
+
+// StartIngestInbox starts one poller per payload kind. Pollers dispatch claimed rows onto
+// the block and token worker pools; new payloads wake them up immediately.
+func StartIngestInbox(pollInterval time.Duration) {
+	if blockPool == nil || tokenPool == nil {
+		InitWorkerPools(0)
+	}
+
+	pollers := map[string]func(func()) bool{
+		InboxKindBlock: EnqueueBlockUpdateTask,
+		InboxKindToken: tokenPool.enqueue,
+	}
+
+	for kind, enqueue := range pollers {
+		kind, enqueue := kind, enqueue
+		go func() {
+			ticker := time.NewTicker(pollInterval)
+			defer ticker.Stop()
+
+			for {
+				drainInbox(kind, enqueue)
+				select {
+				case <-ticker.C:
+				case <-inboxWake[kind]:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			pruneInbox()
+		}
+	}()
+
+	log.Printf("📬 Ingest inbox pollers started (every %s)", pollInterval)
+}
+
+// GetIngestInboxStatus returns row counts per kind and status
+func GetIngestInboxStatus() ([]IngestInboxStatus, error) {
+	return repos.Sync.InboxStatus()
+}
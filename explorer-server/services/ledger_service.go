@@ -0,0 +1,400 @@
+package services
+
+import (
+	"encoding/json"
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Ledger system accounts
+const (
+	LedgerIssuance = repository.LedgerIssuance
+	LedgerBurnt    = repository.LedgerBurnt
+)
+
+// LedgerBalance sums the entries of one account in one asset
+type LedgerBalance = repository.LedgerBalance
+
+// LedgerImbalance is a block whose entries of one asset do not net to zero
+type LedgerImbalance = repository.LedgerImbalance
+
+// pledgedAccount names the ledger account holding the tokens a DID pledged
+func pledgedAccount(did string) string {
+	return repository.LedgerPledgedPrefix + did
+}
+
+// ledgerMovement returns the entry type of a block and the accounts its tokens move to
+// (debit) and from (credit); an empty entry type means the block moves no value
+func ledgerMovement(block *util.TokenChainBlock) (entryType, debit, credit string) {
+	owner := block.TokenOwner
+	switch block.TransType {
+	case util.TransTypeMint, util.TransTypeMigrated, util.TransTypeGenerated:
+		return repository.LedgerMint, owner, LedgerIssuance
+	case util.TransTypeTransfer:
+		return repository.LedgerTransfer, block.TransInfo.ReceiverDID, block.TransInfo.SenderDID
+	case util.TransTypeBurnt:
+		return repository.LedgerBurn, LedgerBurnt, owner
+	case util.TransTypeBurntForFT:
+		return repository.LedgerBurntForFT, LedgerBurnt, owner
+	case util.TransTypePledged:
+		if owner != "" {
+			return repository.LedgerPledge, pledgedAccount(owner), owner
+		}
+	case util.TransTypeUnpledged:
+		if owner != "" {
+			return repository.LedgerUnpledge, owner, pledgedAccount(owner)
+		}
+	}
+	return "", "", ""
+}
+
+// ledgerEntries derives a debit and a matching credit for every token a block moves.
+// RBTs move their value, FTs and NFTs one unit each. RBTs of unknown value are left out
+// rather than recorded with a guessed amount, and returned as unvalued.
+func ledgerEntries(r repository.Repositories, block *util.TokenChainBlock) ([]models.LedgerEntry, []string, error) {
+	entryType, debit, credit := ledgerMovement(block)
+	if entryType == "" || debit == "" || credit == "" || debit == credit {
+		return nil, nil, nil
+	}
+
+	tokenIDs := make([]string, 0, len(block.TransInfo.Tokens))
+	for tokenID := range block.TransInfo.Tokens {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Strings(tokenIDs)
+
+	assets, err := tokenAssets(r, tokenIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	epoch := blockTime(block).Epoch
+	var rows []models.LedgerEntry
+	var unvalued []string
+	for _, tokenID := range tokenIDs {
+		asset, ok := assets[tokenID]
+		if !ok {
+			continue
+		}
+		if asset.unvalued {
+			log.Printf("⚠️ Ledger: value of %s moved by %s is unknown, leaving it out", tokenID, block.BlockHash)
+			unvalued = append(unvalued, tokenID)
+			continue
+		}
+		amount := decimal.FromInt(1)
+		if asset.tokenType == RBTType {
+			amount = asset.value
+		}
+
+		entry := models.LedgerEntry{
+			BlockHash: block.BlockHash,
+			TokenID:   tokenID,
+			EntryType: entryType,
+			Asset:     asset.tokenType,
+			TxnID:     optionalString(block.TransInfo.TID),
+			Epoch:     epoch,
+		}
+		debitEntry, creditEntry := entry, entry
+		debitEntry.Account, debitEntry.Debit = debit, amount
+		creditEntry.Account, creditEntry.Credit = credit, amount
+		rows = append(rows, debitEntry, creditEntry)
+	}
+	return rows, unvalued, nil
+}
+
+// storeLedgerEntries records the value a block moves; replays replace the block's entries.
+// Tokens left out as unvalued are recorded too, so the backfill derives the block again.
+func storeLedgerEntries(r repository.Repositories, block *util.TokenChainBlock) error {
+	rows, unvalued, err := ledgerEntries(r, block)
+	if err == nil && (len(rows) > 0 || len(unvalued) > 0) {
+		err = r.Ledger.SaveLedgerEntries(block.BlockHash, rows, unvalued)
+	}
+	if err != nil {
+		log.Printf("❌ Failed to store ledger entries for %s: %v", block.BlockHash, err)
+		return err
+	}
+	return nil
+}
+
+// BackfillLedger derives ledger entries from the Tokens of transfer, mint, burnt and
+// pledge blocks stored before the ledger was kept
+func BackfillLedger() {
+	filled, skipped := 0, 0
+
+	// fill decodes the stored Tokens of one block and stores its ledger entries
+	fill := func(block *util.TokenChainBlock, tokens []byte) error {
+		if err := json.Unmarshal(tokens, &block.TransInfo.Tokens); err != nil {
+			skipped++
+			return nil
+		}
+		if err := storeLedgerEntries(repos, block); err != nil {
+			return err
+		}
+		filled++
+		return nil
+	}
+
+	err := repos.Ledger.EachTransferWithoutLedger(500, func(batch []models.TransferBlocks) error {
+		for _, tb := range batch {
+			block := &util.TokenChainBlock{
+				BlockHash: tb.BlockHash,
+				TransType: util.TransTypeTransfer,
+				Epoch:     unixEpoch(tb.Epoch),
+			}
+			block.TransInfo.SenderDID = deref(tb.SenderDID)
+			block.TransInfo.ReceiverDID = deref(tb.ReceiverDID)
+			block.TransInfo.TID = deref(tb.TxnID)
+			if err := fill(block, tb.Tokens); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = repos.Ledger.EachMintWithoutLedger(500, func(batch []models.MintBlocks) error {
+			for _, mb := range batch {
+				block := &util.TokenChainBlock{
+					BlockHash:  mb.BlockHash,
+					TransType:  mb.TxnType,
+					TokenOwner: mb.OwnerDID,
+					Epoch:      unixEpoch(mb.Epoch),
+				}
+				block.TransInfo.TID = deref(mb.TxnID)
+				if err := fill(block, mb.Tokens); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = repos.Ledger.EachBurntWithoutLedger(500, func(batch []models.BurntBlocks) error {
+			for _, bb := range batch {
+				block := &util.TokenChainBlock{
+					BlockHash:  bb.BlockHash,
+					TransType:  util.TransTypeBurnt,
+					TokenOwner: bb.OwnerDID,
+					Epoch:      unixEpoch(bb.Epoch),
+				}
+				if deref(bb.TxnType) == BurntTxnTypeBurntForFT {
+					block.TransType = util.TransTypeBurntForFT
+				}
+				if err := fill(block, bb.Tokens); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = repos.Ledger.EachPledgeWithoutLedger(500, func(batch []models.PledgeBlocks) error {
+			for _, pb := range batch {
+				block := &util.TokenChainBlock{
+					BlockHash:  pb.BlockHash,
+					TransType:  pb.TxnType,
+					TokenOwner: pb.OwnerDID,
+					Epoch:      unixEpoch(pb.Epoch),
+				}
+				block.TransInfo.TID = deref(pb.TxnID)
+				if err := fill(block, pb.Tokens); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Ledger backfill stopped: %v", err)
+		return
+	}
+
+	log.Printf("✅ Ledger backfill done: %d blocks filled, %d skipped", filled, skipped)
+}
+
+// DIDLedger is the ledger of one DID: its balances, including the pledged account, and
+// a page of its entries
+type DIDLedger struct {
+	DID      string               `json:"did"`
+	Balances []LedgerBalance      `json:"balances"`
+	Entries  []models.LedgerEntry `json:"entries"`
+	Count    int64                `json:"count"`
+}
+
+// GetDIDLedger sums the ledger of a DID and lists its entries, oldest first
+func GetDIDLedger(did string, limit, page int) (*DIDLedger, error) {
+	accounts := []string{did, pledgedAccount(did)}
+
+	balances, err := repos.Ledger.LedgerBalances(accounts)
+	if err != nil {
+		return nil, err
+	}
+	entries, count, err := repos.Ledger.ListLedgerEntries(accounts, repository.NewPage(limit, page))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DIDLedger{DID: did, Balances: balances, Entries: entries, Count: count}, nil
+}
+
+// LedgerSupply accounts for the supply of one asset: what was issued less what was burnt
+// must equal what DIDs hold plus what they pledged
+type LedgerSupply struct {
+	Asset     string          `json:"asset"`
+	Issued    decimal.Decimal `json:"issued"`
+	Burnt     decimal.Decimal `json:"burnt"`
+	Held      decimal.Decimal `json:"held"`
+	Pledged   decimal.Decimal `json:"pledged"`
+	Conserved bool            `json:"conserved"`
+}
+
+// LedgerReconciliation is a stored DID total that disagrees with the DID's ledger balance
+type LedgerReconciliation struct {
+	DID    string          `json:"did"`
+	Field  string          `json:"field"`
+	Stored decimal.Decimal `json:"stored"`
+	Ledger decimal.Decimal `json:"ledger"`
+}
+
+// LedgerCheckReport is the outcome of one run of the ledger invariant checker
+type LedgerCheckReport struct {
+	Consistent   bool                   `json:"consistent"`
+	Supply       []LedgerSupply         `json:"supply"`
+	Unbalanced   []LedgerImbalance      `json:"unbalanced"`
+	Overdrawn    []LedgerBalance        `json:"overdrawn"`
+	Unreconciled []LedgerReconciliation `json:"unreconciled"`
+}
+
+// ledgerTotalFields maps each ledger asset to the DID total it reconciles with
+var ledgerTotalFields = map[string]string{RBTType: "total_rbts", FTType: "total_fts", NFTType: "total_nfts"}
+
+// CheckLedgerInvariants proves the ledger consistent: every block nets to zero, no DID or
+// pledged account is overdrawn and, per asset, issued less burnt equals held plus pledged.
+// It also reconciles the stored DID totals with the ledger balances of the DIDs.
+func CheckLedgerInvariants() (*LedgerCheckReport, error) {
+	unbalanced, err := repos.Ledger.UnbalancedLedgerBlocks()
+	if err != nil {
+		return nil, err
+	}
+	balances, err := repos.Ledger.LedgerBalances(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LedgerCheckReport{
+		Supply:       []LedgerSupply{},
+		Unbalanced:   append([]LedgerImbalance{}, unbalanced...),
+		Overdrawn:    []LedgerBalance{},
+		Unreconciled: []LedgerReconciliation{},
+	}
+
+	supply := map[string]*LedgerSupply{}
+	held := map[string]map[string]decimal.Decimal{}
+	for _, b := range balances {
+		s, ok := supply[b.Asset]
+		if !ok {
+			s = &LedgerSupply{Asset: b.Asset}
+			supply[b.Asset] = s
+		}
+
+		switch {
+		case b.Account == LedgerIssuance:
+			s.Issued = s.Issued.Sub(b.Balance)
+			continue
+		case b.Account == LedgerBurnt:
+			s.Burnt = s.Burnt.Add(b.Balance)
+			continue
+		case strings.HasPrefix(b.Account, repository.LedgerPledgedPrefix):
+			s.Pledged = s.Pledged.Add(b.Balance)
+		default:
+			s.Held = s.Held.Add(b.Balance)
+			if held[b.Account] == nil {
+				held[b.Account] = map[string]decimal.Decimal{}
+			}
+			held[b.Account][b.Asset] = b.Balance
+		}
+		if b.Balance.Sign() < 0 {
+			report.Overdrawn = append(report.Overdrawn, b)
+		}
+	}
+
+	for _, asset := range sortedAssets(supply) {
+		s := supply[asset]
+		s.Conserved = s.Issued.Sub(s.Burnt) == s.Held.Add(s.Pledged)
+		report.Supply = append(report.Supply, *s)
+	}
+
+	if report.Unreconciled, err = reconcileDIDTotals(held); err != nil {
+		return nil, err
+	}
+
+	report.Consistent = len(report.Unbalanced) == 0 && len(report.Overdrawn) == 0
+	for _, s := range report.Supply {
+		report.Consistent = report.Consistent && s.Conserved
+	}
+	if !report.Consistent {
+		log.Printf("⚠️ Ledger check: %d unbalanced blocks, %d overdrawn accounts", len(report.Unbalanced), len(report.Overdrawn))
+	}
+	return report, nil
+}
+
+// sortedAssets returns the assets of a supply map in order
+func sortedAssets(supply map[string]*LedgerSupply) []string {
+	assets := make([]string, 0, len(supply))
+	for asset := range supply {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// reconcileDIDTotals compares the stored totals of every DID with its ledger balances
+func reconcileDIDTotals(held map[string]map[string]decimal.Decimal) ([]LedgerReconciliation, error) {
+	mismatches := []LedgerReconciliation{}
+	compare := func(did string, stored models.DIDs) {
+		ledger := held[did]
+		values := map[string]decimal.Decimal{
+			RBTType: stored.TotalRBTs,
+			FTType:  stored.TotalFTs,
+			NFTType: decimal.FromInt(stored.TotalNFTs),
+		}
+		for _, asset := range []string{RBTType, FTType, NFTType} {
+			if values[asset] != ledger[asset] {
+				mismatches = append(mismatches, LedgerReconciliation{
+					DID: did, Field: ledgerTotalFields[asset], Stored: values[asset], Ledger: ledger[asset],
+				})
+			}
+		}
+		delete(held, did)
+	}
+
+	page := repository.Page{Limit: didRecomputeBatch}
+	for {
+		stored, err := repos.DIDs.ListDIDs(page)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range stored {
+			compare(d.DID, d)
+		}
+		if len(stored) < page.Limit {
+			break
+		}
+		page.Offset += page.Limit
+	}
+
+	// DIDs the ledger credits without a stored row
+	dids := make([]string, 0, len(held))
+	for did := range held {
+		dids = append(dids, did)
+	}
+	sort.Strings(dids)
+	for _, did := range dids {
+		compare(did, models.DIDs{DID: did})
+	}
+	return mismatches, nil
+}
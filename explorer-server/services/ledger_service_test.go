@@ -0,0 +1,192 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+)
+
+func TestBackfillLedgerBalancesEveryMovement(t *testing.T) {
+	mem := useMemoryRepos(t)
+	value := decimal.MustParse("1.5")
+
+	if err := mem.Tokens.CreateRBT(&models.RBT{TokenID: "QmToken1", TokenValue: value, OwnerDID: "did-receiver"}); err != nil {
+		t.Fatalf("CreateRBT: %v", err)
+	}
+	epoch := time.Unix(1700000000, 0).UTC()
+	if err := mem.Blocks.SaveMintBlock(&models.MintBlocks{
+		BlockHash: "hash-mint",
+		TxnType:   "01",
+		OwnerDID:  "did-sender",
+		Tokens:    []byte(`{"QmToken1": {"1": 0}}`),
+		BlockTime: models.BlockTime{Epoch: &epoch},
+	}); err != nil {
+		t.Fatalf("SaveMintBlock: %v", err)
+	}
+	later := epoch.Add(time.Hour)
+	if err := mem.Blocks.SaveTransferBlock(&models.TransferBlocks{
+		BlockHash:   "hash-transfer",
+		SenderDID:   optionalString("did-sender"),
+		ReceiverDID: optionalString("did-receiver"),
+		BlockTime:   models.BlockTime{Epoch: &later},
+		Tokens:      []byte(`{"QmToken1": {"1": 0}}`),
+	}); err != nil {
+		t.Fatalf("SaveTransferBlock: %v", err)
+	}
+
+	BackfillLedger()
+
+	ledger, err := GetDIDLedger("did-receiver", 10, 1)
+	if err != nil {
+		t.Fatalf("GetDIDLedger: %v", err)
+	}
+	if len(ledger.Balances) != 1 || ledger.Balances[0].Balance != value || ledger.Count != 1 {
+		t.Fatalf("receiver ledger = %+v, want one 1.5 RBT debit", ledger)
+	}
+
+	report, err := CheckLedgerInvariants()
+	if err != nil {
+		t.Fatalf("CheckLedgerInvariants: %v", err)
+	}
+	if !report.Consistent || len(report.Supply) != 1 || report.Supply[0].Issued != value || report.Supply[0].Held != value {
+		t.Fatalf("report = %+v, want 1.5 RBT issued and held", report)
+	}
+	if len(report.Unreconciled) != 1 || report.Unreconciled[0].DID != "did-receiver" {
+		t.Fatalf("unreconciled = %+v, want the receiver without a DID row", report.Unreconciled)
+	}
+
+	// a lost credit breaks both the block balance and the sender's account
+	if err := mem.Ledger.SaveLedgerEntries("hash-transfer", []models.LedgerEntry{
+		{BlockHash: "hash-transfer", TokenID: "QmToken1", Account: "did-receiver", Asset: RBTType, Debit: value},
+		{BlockHash: "hash-transfer", TokenID: "QmToken1", Account: "did-sender", Asset: RBTType, Credit: decimal.FromInt(3)},
+	}, nil); err != nil {
+		t.Fatalf("SaveLedgerEntries: %v", err)
+	}
+	report, err = CheckLedgerInvariants()
+	if err != nil {
+		t.Fatalf("CheckLedgerInvariants: %v", err)
+	}
+	if report.Consistent || len(report.Unbalanced) != 1 || len(report.Overdrawn) != 1 || report.Overdrawn[0].Account != "did-sender" {
+		t.Fatalf("report = %+v, want an unbalanced transfer and an overdrawn sender", report)
+	}
+}
+
+func TestTokenOfUnknownValueIsNotGuessed(t *testing.T) {
+	mem := useMemoryRepos(t)
+
+	// QmToken2 is in no token table and has no lineage, so its value is unknown
+	epoch := time.Unix(1700000000, 0).UTC()
+	if err := mem.Blocks.SaveTransferBlock(&models.TransferBlocks{
+		BlockHash:   "hash-transfer",
+		SenderDID:   optionalString("did-sender"),
+		ReceiverDID: optionalString("did-receiver"),
+		BlockTime:   models.BlockTime{Epoch: &epoch},
+		Tokens:      []byte(`{"QmToken2": {"1": 0}}`),
+	}); err != nil {
+		t.Fatalf("SaveTransferBlock: %v", err)
+	}
+
+	BackfillLedger()
+	BackfillOwnershipHistory()
+
+	ledger, err := GetDIDLedger("did-receiver", 10, 1)
+	if err != nil {
+		t.Fatalf("GetDIDLedger: %v", err)
+	}
+	if ledger.Count != 0 || len(ledger.Balances) != 0 {
+		t.Fatalf("receiver ledger = %+v, want no entries for a token of unknown value", ledger)
+	}
+
+	history, err := GetDIDBalanceHistory("did-receiver", "", TimeRange{})
+	if err != nil {
+		t.Fatalf("GetDIDBalanceHistory: %v", err)
+	}
+	if b := history.Balance; !b.RBT.IsZero() || b.Unvalued != 1 {
+		t.Fatalf("balance = %+v, want no RBT value and one unvalued RBT", b)
+	}
+}
+
+// TestPartlyValuedBlockIsDerivedAgain checks that a block whose entries left a token out as
+// unvalued is picked up by the next backfill once the token's value is known
+func TestPartlyValuedBlockIsDerivedAgain(t *testing.T) {
+	mem := useMemoryRepos(t)
+	value := decimal.MustParse("1.5")
+
+	if err := mem.Tokens.CreateRBT(&models.RBT{TokenID: "QmToken1", TokenValue: value, OwnerDID: "did-receiver"}); err != nil {
+		t.Fatalf("CreateRBT: %v", err)
+	}
+	epoch := time.Unix(1700000000, 0).UTC()
+	if err := mem.Blocks.SaveTransferBlock(&models.TransferBlocks{
+		BlockHash:   "hash-transfer",
+		SenderDID:   optionalString("did-sender"),
+		ReceiverDID: optionalString("did-receiver"),
+		BlockTime:   models.BlockTime{Epoch: &epoch},
+		Tokens:      []byte(`{"QmToken1": {"1": 0}, "QmToken2": {"1": 0}}`),
+	}); err != nil {
+		t.Fatalf("SaveTransferBlock: %v", err)
+	}
+
+	BackfillLedger()
+	ledger, err := GetDIDLedger("did-receiver", 10, 1)
+	if err != nil {
+		t.Fatalf("GetDIDLedger: %v", err)
+	}
+	if ledger.Count != 1 || ledger.Balances[0].Balance != value {
+		t.Fatalf("receiver ledger = %+v, want only the valued token", ledger)
+	}
+
+	// QmToken2 is valued later, e.g. once its RBT row is synced
+	if err := mem.Tokens.CreateRBT(&models.RBT{TokenID: "QmToken2", TokenValue: decimal.FromInt(2), OwnerDID: "did-receiver"}); err != nil {
+		t.Fatalf("CreateRBT: %v", err)
+	}
+	for run := 1; run <= 2; run++ {
+		BackfillLedger()
+		if ledger, err = GetDIDLedger("did-receiver", 10, 1); err != nil {
+			t.Fatalf("GetDIDLedger: %v", err)
+		}
+		if ledger.Count != 2 || ledger.Balances[0].Balance != decimal.MustParse("3.5") {
+			t.Fatalf("run %d: receiver ledger = %+v, want both tokens worth 3.5 RBT", run, ledger)
+		}
+	}
+	if err := mem.Ledger.EachTransferWithoutLedger(10, func(batch []models.TransferBlocks) error {
+		t.Errorf("%d fully valued transfers are still selected for the backfill", len(batch))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackfillLedgerKeepsBurntForFT(t *testing.T) {
+	mem := useMemoryRepos(t)
+	value := decimal.MustParse("2")
+
+	if err := mem.Tokens.CreateRBT(&models.RBT{TokenID: "QmToken1", TokenValue: value, OwnerDID: "did-owner"}); err != nil {
+		t.Fatalf("CreateRBT: %v", err)
+	}
+	epoch := time.Unix(1700000000, 0).UTC()
+	if err := mem.Blocks.SaveBurntBlock(&models.BurntBlocks{
+		BlockHash: "hash-burnt",
+		TxnType:   optionalString(BurntTxnTypeBurntForFT),
+		OwnerDID:  "did-owner",
+		BlockTime: models.BlockTime{Epoch: &epoch},
+		Tokens:    []byte(`{"QmToken1": {"1": 0}}`),
+	}); err != nil {
+		t.Fatalf("SaveBurntBlock: %v", err)
+	}
+
+	BackfillLedger()
+
+	ledger, err := GetDIDLedger("did-owner", 10, 1)
+	if err != nil {
+		t.Fatalf("GetDIDLedger: %v", err)
+	}
+	if ledger.Count != 1 || len(ledger.Entries) != 1 {
+		t.Fatalf("owner ledger = %+v, want the credit of one burn", ledger)
+	}
+	if e := ledger.Entries[0]; e.EntryType != repository.LedgerBurntForFT || e.Credit != value || e.TokenID != "QmToken1" {
+		t.Fatalf("entry = %+v, want a %s credit of 2 RBT", e, repository.LedgerBurntForFT)
+	}
+}
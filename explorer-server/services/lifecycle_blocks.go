@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/model"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"fmt"
+	"log"
+
+	"gorm.io/datatypes"
+)
+
+// StoreMintBlock handles inserting a minted (01), migrated (03) or generation (05) block into DB
+func StoreMintBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	tokensJSON, _ := json.Marshal(block.TransInfo.Tokens)
+	genesisJSON, _ := json.Marshal(block.GenesisBlock)
+
+	mb := models.MintBlocks{
+		BlockHash:    block.BlockHash,
+		TxnType:      block.TransType,
+		TxnID:        optionalString(block.TransInfo.TID),
+		OwnerDID:     block.TokenOwner,
+		TokenValue:   block.TokenValue,
+		Tokens:       datatypes.JSON(tokensJSON),
+		GenesisBlock: datatypes.JSON(genesisJSON),
+		BlockTime:    blockTime(block),
+	}
+
+	if err := r.Blocks.SaveMintBlock(&mb); err != nil {
+		log.Printf("❌ Failed to store mint block %v: %v", mb.BlockHash, err)
+		return err
+	}
+
+	log.Println("Mint block stored:", mb.BlockHash)
+	return nil
+}
+
+// StorePledgeBlock handles inserting a pledged (04) or unpledged (06) block into DB
+func StorePledgeBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	tokensJSON, _ := json.Marshal(block.TransInfo.Tokens)
+
+	pb := models.PledgeBlocks{
+		BlockHash: block.BlockHash,
+		TxnType:   block.TransType,
+		TxnID:     optionalString(block.TransInfo.TID),
+		OwnerDID:  block.TokenOwner,
+		Tokens:    datatypes.JSON(tokensJSON),
+		BlockTime: blockTime(block),
+	}
+	if _, info, ok := block.FirstToken(); ok {
+		pb.PledgedDID = optionalString(info.PledgedDID)
+	}
+
+	if err := r.Blocks.SavePledgeBlock(&pb); err != nil {
+		log.Printf("❌ Failed to store pledge block %v: %v", pb.BlockHash, err)
+		return err
+	}
+
+	log.Println("Pledge block stored:", pb.BlockHash)
+	return nil
+}
+
+// StoreCommitBlock handles inserting a committed (07) or contract-committed (11) block into DB
+func StoreCommitBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	tokensJSON, _ := json.Marshal(block.TransInfo.Tokens)
+	committedJSON, _ := json.Marshal(block.TransInfo.CommittedTokens)
+
+	cb := models.CommitBlocks{
+		BlockHash:       block.BlockHash,
+		TxnType:         block.TransType,
+		TxnID:           optionalString(block.TransInfo.TID),
+		OwnerDID:        block.TokenOwner,
+		Tokens:          datatypes.JSON(tokensJSON),
+		CommittedTokens: datatypes.JSON(committedJSON),
+		BlockTime:       blockTime(block),
+	}
+	if _, info, ok := block.FirstToken(); ok {
+		cb.CommittedDID = optionalString(info.CommittedDID)
+	}
+
+	if err := r.Blocks.SaveCommitBlock(&cb); err != nil {
+		log.Printf("❌ Failed to store commit block %v: %v", cb.BlockHash, err)
+		return err
+	}
+
+	log.Println("Commit block stored:", cb.BlockHash)
+	return nil
+}
+
+// StorePinBlock handles inserting a pinned-as-service (12) block into DB
+func StorePinBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	tokensJSON, _ := json.Marshal(block.TransInfo.Tokens)
+
+	pb := models.PinBlocks{
+		BlockHash: block.BlockHash,
+		TxnID:     optionalString(block.TransInfo.TID),
+		OwnerDID:  block.TokenOwner,
+		Comment:   optionalString(block.TransInfo.Comment),
+		Tokens:    datatypes.JSON(tokensJSON),
+		BlockTime: blockTime(block),
+	}
+
+	if err := r.Blocks.SavePinBlock(&pb); err != nil {
+		log.Printf("❌ Failed to store pin block %v: %v", pb.BlockHash, err)
+		return err
+	}
+
+	log.Println("Pin block stored:", pb.BlockHash)
+	return nil
+}
+
+// GetMintBlockList returns minted/migrated/generation blocks, optionally filtered by txn type
+func GetMintBlockList(txnType string, timeRange TimeRange, limit, page int) (model.MintBlocksListResponse, error) {
+	var response model.MintBlocksListResponse
+	var err error
+	response.MintBlocks, response.Count, err = repos.Blocks.ListMintBlocks(normalizedTxnType(txnType), timeRange, repository.NewPage(limit, page))
+	return response, err
+}
+
+// GetPledgeBlockList returns pledged/unpledged blocks, optionally filtered by txn type
+func GetPledgeBlockList(txnType string, timeRange TimeRange, limit, page int) (model.PledgeBlocksListResponse, error) {
+	var response model.PledgeBlocksListResponse
+	var err error
+	response.PledgeBlocks, response.Count, err = repos.Blocks.ListPledgeBlocks(normalizedTxnType(txnType), timeRange, repository.NewPage(limit, page))
+	return response, err
+}
+
+// GetCommitBlockList returns committed/contract-committed blocks, optionally filtered by txn type
+func GetCommitBlockList(txnType string, timeRange TimeRange, limit, page int) (model.CommitBlocksListResponse, error) {
+	var response model.CommitBlocksListResponse
+	var err error
+	response.CommitBlocks, response.Count, err = repos.Blocks.ListCommitBlocks(normalizedTxnType(txnType), timeRange, repository.NewPage(limit, page))
+	return response, err
+}
+
+// GetPinBlockList returns pinned-as-service blocks
+func GetPinBlockList(timeRange TimeRange, limit, page int) (model.PinBlocksListResponse, error) {
+	var response model.PinBlocksListResponse
+	var err error
+	response.PinBlocks, response.Count, err = repos.Blocks.ListPinBlocks(timeRange, repository.NewPage(limit, page))
+	return response, err
+}
+
+// normalizedTxnType maps a txn type filter to its stored code; empty means no filter
+func normalizedTxnType(txnType string) string {
+	if txnType == "" {
+		return ""
+	}
+	return util.NormalizeTransType(txnType)
+}
+
+// ErrBlockNotFound is returned when no stored block matches a hash or transaction ID
+var ErrBlockNotFound = errors.New("block not found")
+
+// GetBlockByHashOrTxnID finds a stored block of any kind by block hash or transaction ID.
+// It returns the AllBlocks block type together with the row from the kind's own table.
+func GetBlockByHashOrTxnID(id string) (string, interface{}, error) {
+	entry, err := repos.Blocks.FindIndexedBlock(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", nil, ErrBlockNotFound
+		}
+		return "", nil, err
+	}
+
+	var data interface{}
+	switch entry.BlockType {
+	case "transfer":
+		data, err = repos.Blocks.GetTransferBlock(entry.BlockHash)
+	case "burnt", "burnt_for_ft":
+		data, err = repos.Blocks.GetBurntBlock(entry.BlockHash)
+	case "deploy", "execute":
+		var block *models.SC_Block
+		if block, err = repos.SmartContracts.GetSCBlock(entry.BlockHash); err == nil {
+			data = *block
+		}
+	case "mint", "minted", "migrated":
+		data, err = repos.Blocks.GetMintBlock(entry.BlockHash)
+	case "pledged", "unpledged":
+		data, err = repos.Blocks.GetPledgeBlock(entry.BlockHash)
+	case "committed", "contract_committed":
+		data, err = repos.Blocks.GetCommitBlock(entry.BlockHash)
+	case "pinned":
+		data, err = repos.Blocks.GetPinBlock(entry.BlockHash)
+	default:
+		return entry.BlockType, *entry, nil
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		// indexed in AllBlocks but the detail row is missing; return the index entry
+		return entry.BlockType, *entry, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load %s block %s: %w", entry.BlockType, entry.BlockHash, err)
+	}
+	return entry.BlockType, data, nil
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/json"
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+)
+
+// Token lineage relations: RelatedTokenID is the <relation> of TokenID
+const (
+	LineageParent      = repository.LineageParent
+	LineageGrandParent = repository.LineageGrandParent
+	LineagePrevious    = repository.LineagePrevious
+)
+
+const (
+	defaultLineageDepth = 8
+	maxLineageDepth     = 32
+	// maxLineageNodes caps the size of one lineage tree; heavily split RBTs can fan out widely
+	maxLineageNodes = 2000
+)
+
+// LineageNode is one token in a lineage tree
+type LineageNode struct {
+	TokenID        string           `json:"token_id"`
+	TokenValue     *decimal.Decimal `json:"token_value"`
+	BlockHash      string           `json:"block_hash,omitempty"`
+	PreviousID     string           `json:"previous_id,omitempty"`
+	GrandParentIDs []string         `json:"grandparent_ids,omitempty"`
+	Parents        []*LineageNode   `json:"parents,omitempty"`
+	Children       []*LineageNode   `json:"children,omitempty"`
+}
+
+// TokenLineageTree is a token with its ancestors (Parents) and descendants (Children)
+type TokenLineageTree struct {
+	Token     *LineageNode `json:"token"`
+	Depth     int          `json:"depth"`
+	Truncated bool         `json:"truncated"`
+}
+
+// lineageRows derives the lineage relations recorded in a block: genesis info links
+// new tokens to their parent/previous tokens, and burns link child tokens to the burnt ones.
+func lineageRows(block *util.TokenChainBlock) []models.TokenLineage {
+	var rows []models.TokenLineage
+	epoch := blockTime(block).Epoch
+
+	if block.GenesisBlock != nil {
+		for tokenID, info := range block.GenesisBlock.Info {
+			row := models.TokenLineage{TokenID: tokenID, BlockHash: block.BlockHash, Epoch: epoch}
+			if len(block.GenesisBlock.Info) == 1 {
+				row.TokenValue = block.TokenValue
+			}
+
+			if info.ParentID != "" {
+				r := row
+				r.RelatedTokenID, r.Relation = info.ParentID, LineageParent
+				rows = append(rows, r)
+			}
+			if info.PreviousID != "" {
+				r := row
+				r.RelatedTokenID, r.Relation = info.PreviousID, LineagePrevious
+				rows = append(rows, r)
+			}
+			for _, gp := range info.GrandParentIDs {
+				if gp == "" {
+					continue
+				}
+				r := row
+				r.RelatedTokenID, r.Relation = gp, LineageGrandParent
+				rows = append(rows, r)
+			}
+		}
+	}
+
+	for burnt := range block.TransInfo.Tokens {
+		for _, child := range block.ChildTokens {
+			if child == "" || child == burnt {
+				continue
+			}
+			rows = append(rows, models.TokenLineage{
+				TokenID:        child,
+				RelatedTokenID: burnt,
+				Relation:       LineageParent,
+				BlockHash:      block.BlockHash,
+				Epoch:          epoch,
+			})
+		}
+	}
+
+	return rows
+}
+
+// storeTokenLineage records the lineage relations of a block; existing relations are kept
+func storeTokenLineage(r repository.Repositories, block *util.TokenChainBlock) error {
+	rows := lineageRows(block)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := r.Analytics.SaveTokenLineage(rows); err != nil {
+		log.Printf("❌ Failed to store token lineage for %s: %v", block.BlockHash, err)
+		return err
+	}
+	return nil
+}
+
+// BackfillTokenLineage derives lineage from mint and burnt blocks stored before lineage was tracked
+func BackfillTokenLineage() {
+	filled := 0
+
+	err := repos.Analytics.EachMintWithoutLineage(500, func(mints []models.MintBlocks) error {
+		for _, mb := range mints {
+			var genesis util.GenesisBlock
+			if err := json.Unmarshal(mb.GenesisBlock, &genesis); err != nil {
+				continue
+			}
+			block := &util.TokenChainBlock{
+				BlockHash:    mb.BlockHash,
+				TokenValue:   mb.TokenValue,
+				Epoch:        unixEpoch(mb.Epoch),
+				GenesisBlock: &genesis,
+			}
+			if err := storeTokenLineage(repos, block); err != nil {
+				return err
+			}
+			filled++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Token lineage backfill stopped: %v", err)
+		return
+	}
+
+	err = repos.Analytics.EachBurntWithoutLineage(500, func(burns []models.BurntBlocks) error {
+		for _, bb := range burns {
+			block := &util.TokenChainBlock{BlockHash: bb.BlockHash, Epoch: unixEpoch(bb.Epoch)}
+			if json.Unmarshal(bb.ChildTokens, &block.ChildTokens) != nil ||
+				json.Unmarshal(bb.Tokens, &block.TransInfo.Tokens) != nil {
+				continue
+			}
+			if err := storeTokenLineage(repos, block); err != nil {
+				return err
+			}
+			filled++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Token lineage backfill stopped: %v", err)
+		return
+	}
+
+	log.Printf("✅ Token lineage backfill done: %d blocks processed", filled)
+}
+
+// hasLineageParent reports whether a token is known to be split from another token
+func hasLineageParent(tokenID string) bool {
+	rows, err := repos.Analytics.LineageOf([]string{tokenID})
+	if err != nil {
+		log.Printf("⚠️ Failed to read lineage of %s: %v", tokenID, err)
+		return false
+	}
+	for _, row := range rows {
+		if row.Relation == LineageParent {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTokenLineage builds the ancestor and descendant tree of a token, up to depth levels each way
+func GetTokenLineage(tokenID string, depth int) (TokenLineageTree, error) {
+	if depth <= 0 {
+		depth = defaultLineageDepth
+	}
+	if depth > maxLineageDepth {
+		depth = maxLineageDepth
+	}
+
+	root := &LineageNode{TokenID: tokenID}
+	tree := TokenLineageTree{Token: root, Depth: depth}
+	nodes := []*LineageNode{root}
+
+	// ancestors: follow token_id -> related_token_id
+	seen := map[string]bool{tokenID: true}
+	frontier := []*LineageNode{root}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		byID := lineageIndex(frontier)
+
+		rows, err := repos.Analytics.LineageOf(lineageIDs(byID))
+		if err != nil {
+			return tree, err
+		}
+
+		var next []*LineageNode
+		for _, row := range rows {
+			node := byID[row.TokenID]
+			if node.TokenValue == nil {
+				node.TokenValue = row.TokenValue
+			}
+
+			switch row.Relation {
+			case LineagePrevious:
+				node.PreviousID = row.RelatedTokenID
+			case LineageGrandParent:
+				node.GrandParentIDs = append(node.GrandParentIDs, row.RelatedTokenID)
+			case LineageParent:
+				if seen[row.RelatedTokenID] {
+					continue
+				}
+				if len(nodes) >= maxLineageNodes {
+					tree.Truncated = true
+					continue
+				}
+				seen[row.RelatedTokenID] = true
+				parent := &LineageNode{TokenID: row.RelatedTokenID, BlockHash: row.BlockHash}
+				node.Parents = append(node.Parents, parent)
+				next = append(next, parent)
+				nodes = append(nodes, parent)
+			}
+		}
+		frontier = next
+	}
+
+	// descendants: follow related_token_id -> token_id
+	seen = map[string]bool{tokenID: true}
+	frontier = []*LineageNode{root}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		byID := lineageIndex(frontier)
+
+		rows, err := repos.Analytics.LineageChildren(lineageIDs(byID))
+		if err != nil {
+			return tree, err
+		}
+
+		var next []*LineageNode
+		for _, row := range rows {
+			if seen[row.TokenID] {
+				continue
+			}
+			if len(nodes) >= maxLineageNodes {
+				tree.Truncated = true
+				break
+			}
+			seen[row.TokenID] = true
+			child := &LineageNode{TokenID: row.TokenID, TokenValue: row.TokenValue, BlockHash: row.BlockHash}
+			byID[row.RelatedTokenID].Children = append(byID[row.RelatedTokenID].Children, child)
+			next = append(next, child)
+			nodes = append(nodes, child)
+		}
+		frontier = next
+	}
+
+	fillLineageValues(nodes)
+	return tree, nil
+}
+
+// fillLineageValues takes missing token values from the RBT table
+func fillLineageValues(nodes []*LineageNode) {
+	var ids []string
+	for _, n := range nodes {
+		if n.TokenValue == nil {
+			ids = append(ids, n.TokenID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	values, err := repos.Tokens.RBTValues(ids)
+	if err != nil {
+		log.Printf("⚠️ Failed to look up lineage token values: %v", err)
+		return
+	}
+	for _, n := range nodes {
+		if v, ok := values[n.TokenID]; ok && n.TokenValue == nil {
+			value := v
+			n.TokenValue = &value
+		}
+	}
+}
+
+func lineageIndex(nodes []*LineageNode) map[string]*LineageNode {
+	byID := make(map[string]*LineageNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.TokenID] = n
+	}
+	return byID
+}
+
+func lineageIDs(m map[string]*LineageNode) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
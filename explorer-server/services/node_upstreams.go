@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"explorer-server/config"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// an upstream is taken out of rotation after this many consecutive failures
+const nodeUpstreamFailureThreshold = 3
+
+type nodeUpstream struct {
+	url    string
+	served int64 // atomic
+
+	mu                  sync.Mutex
+	healthy             bool
+	consecutiveFailures int
+	lastError           string
+	lastCheck           time.Time
+	lastLatency         time.Duration
+}
+
+// NodeUpstreamStatus is a monitoring snapshot of one fullnode upstream
+type NodeUpstreamStatus struct {
+	URL                 string     `json:"url"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastCheckedAt       *time.Time `json:"last_checked_at"`
+	LatencyMs           int64      `json:"latency_ms"`
+	Served              int64      `json:"served"`
+}
+
+var (
+	upstreamsMu    sync.RWMutex
+	upstreams      []*nodeUpstream
+	upstreamCursor uint32
+)
+
+// SetNodeUpstreams replaces the fullnode upstream list
+func SetNodeUpstreams(urls []string) {
+	list := make([]*nodeUpstream, 0, len(urls))
+	for _, u := range urls {
+		list = append(list, &nodeUpstream{url: u, healthy: true})
+	}
+
+	upstreamsMu.Lock()
+	upstreams = list
+	upstreamsMu.Unlock()
+}
+
+func nodeUpstreams() []*nodeUpstream {
+	upstreamsMu.RLock()
+	list := upstreams
+	upstreamsMu.RUnlock()
+
+	if list == nil {
+		SetNodeUpstreams(config.RubixNodeURLs())
+		return nodeUpstreams()
+	}
+	return list
+}
+
+func (u *nodeUpstream) markSuccess(latency time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.healthy {
+		log.Printf("✅ Fullnode %s is healthy again", u.url)
+	}
+	u.healthy = true
+	u.consecutiveFailures = 0
+	u.lastError = ""
+	u.lastCheck = time.Now()
+	u.lastLatency = latency
+}
+
+func (u *nodeUpstream) markFailure(err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.consecutiveFailures++
+	u.lastError = err.Error()
+	u.lastCheck = time.Now()
+
+	if u.healthy && u.consecutiveFailures >= nodeUpstreamFailureThreshold {
+		u.healthy = false
+		log.Printf("⚠️ Fullnode %s marked unhealthy after %d failures: %v", u.url, u.consecutiveFailures, err)
+	}
+}
+
+func (u *nodeUpstream) isHealthy() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.healthy
+}
+
+// candidateUpstreams returns healthy upstreams in round-robin order,
+// followed by unhealthy ones as a last resort.
+func candidateUpstreams() []*nodeUpstream {
+	list := nodeUpstreams()
+	if len(list) == 0 {
+		return nil
+	}
+
+	start := int(atomic.AddUint32(&upstreamCursor, 1)) % len(list)
+
+	healthy := make([]*nodeUpstream, 0, len(list))
+	var unhealthy []*nodeUpstream
+	for i := range list {
+		u := list[(start+i)%len(list)]
+		if u.isHealthy() {
+			healthy = append(healthy, u)
+		} else {
+			unhealthy = append(unhealthy, u)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// nodeGetFrom performs a GET of path against one upstream and records the outcome.
+// 5xx answers are turned into errors so callers can fail over.
+func nodeGetFrom(u *nodeUpstream, path string) (*http.Response, error) {
+	start := time.Now()
+	resp, err := GetNodeHTTPClient().Get(u.url + path)
+	if err != nil {
+		u.markFailure(err)
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		err := fmt.Errorf("fullnode %s returned status %d", u.url, resp.StatusCode)
+		u.markFailure(err)
+		return nil, err
+	}
+
+	u.markSuccess(time.Since(start))
+	atomic.AddInt64(&u.served, 1)
+	return resp, nil
+}
+
+// ErrNodeUnavailable is returned when no fullnode upstream could answer a request
+var ErrNodeUnavailable = errors.New("fullnode unavailable")
+
+// nodeGet performs a GET of path (e.g. "/api/de-exp/get-rbt-list") against the fullnode
+// upstreams, failing over to the next one on errors, timeouts and 5xx answers.
+// It returns the response together with the URL of the upstream that served it.
+func nodeGet(path string) (*http.Response, string, error) {
+	candidates := candidateUpstreams()
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("%w: no upstreams configured", ErrNodeUnavailable)
+	}
+
+	var lastErr error
+	for _, u := range candidates {
+		resp, err := nodeGetFrom(u, path)
+		if err == nil {
+			return resp, u.url, nil
+		}
+		log.Printf("⚠️ Fullnode %s failed for %s: %v", u.url, path, err)
+		lastErr = err
+	}
+
+	return nil, "", fmt.Errorf("%w: all upstreams failed: %w", ErrNodeUnavailable, lastErr)
+}
+
+// probeUpstream checks one upstream with a short timeout
+func probeUpstream(u *nodeUpstream) {
+	client := &http.Client{
+		Transport: GetNodeHTTPClient().Transport,
+		Timeout:   5 * time.Second,
+	}
+
+	start := time.Now()
+	resp, err := client.Get(u.url + config.RubixNodeHealthPath)
+	if err != nil {
+		u.markFailure(err)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		u.markFailure(fmt.Errorf("health probe returned status %d", resp.StatusCode))
+		return
+	}
+	u.markSuccess(time.Since(start))
+}
+
+// StartNodeHealthChecks probes every fullnode upstream periodically in the background
+func StartNodeHealthChecks(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, u := range nodeUpstreams() {
+				probeUpstream(u)
+			}
+			<-ticker.C
+		}
+	}()
+	log.Printf("🩺 Fullnode health checks started (%d upstreams, every %s)", len(nodeUpstreams()), interval)
+}
+
+// GetNodeUpstreamStatus returns a snapshot of all fullnode upstreams
+func GetNodeUpstreamStatus() []NodeUpstreamStatus {
+	list := nodeUpstreams()
+	statuses := make([]NodeUpstreamStatus, 0, len(list))
+
+	for _, u := range list {
+		u.mu.Lock()
+		s := NodeUpstreamStatus{
+			URL:                 u.url,
+			Healthy:             u.healthy,
+			ConsecutiveFailures: u.consecutiveFailures,
+			LastError:           u.lastError,
+			LatencyMs:           u.lastLatency.Milliseconds(),
+			Served:              atomic.LoadInt64(&u.served),
+		}
+		if !u.lastCheck.IsZero() {
+			checked := u.lastCheck
+			s.LastCheckedAt = &checked
+		}
+		u.mu.Unlock()
+
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"encoding/json"
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+	"sort"
+)
+
+// Token ownership events
+const (
+	OwnershipMint     = repository.OwnershipMint
+	OwnershipTransfer = repository.OwnershipTransfer
+	OwnershipBurn     = repository.OwnershipBurn
+)
+
+// HeldToken summarizes the ownership history of one token for one DID
+type HeldToken = repository.HeldToken
+
+// ownershipRows derives one ownership event per token of a mint, transfer or burn block:
+// mints give the tokens to the block owner, transfers move them from sender to receiver
+// and burns take them from the owner.
+func ownershipRows(block *util.TokenChainBlock) []models.TokenOwnership {
+	var event string
+	var from, to *string
+	switch block.TransType {
+	case util.TransTypeMint, util.TransTypeMigrated, util.TransTypeGenerated:
+		event, to = OwnershipMint, optionalString(block.TokenOwner)
+	case util.TransTypeTransfer:
+		event = OwnershipTransfer
+		from, to = optionalString(block.TransInfo.SenderDID), optionalString(block.TransInfo.ReceiverDID)
+	case util.TransTypeBurnt, util.TransTypeBurntForFT:
+		event, from = OwnershipBurn, optionalString(block.TokenOwner)
+	default:
+		return nil
+	}
+	if from == nil && to == nil {
+		return nil
+	}
+
+	tokenIDs := make([]string, 0, len(block.TransInfo.Tokens))
+	for tokenID := range block.TransInfo.Tokens {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Strings(tokenIDs)
+
+	epoch := blockTime(block).Epoch
+	rows := make([]models.TokenOwnership, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		rows = append(rows, models.TokenOwnership{
+			TokenID:   tokenID,
+			BlockHash: block.BlockHash,
+			EventType: event,
+			FromDID:   from,
+			ToDID:     to,
+			TxnID:     optionalString(block.TransInfo.TID),
+			Epoch:     epoch,
+		})
+	}
+	return rows
+}
+
+// storeOwnershipEvents records the ownership changes of a block; replays overwrite the same rows
+func storeOwnershipEvents(r repository.Repositories, block *util.TokenChainBlock) error {
+	rows := ownershipRows(block)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := r.Analytics.SaveOwnershipEvents(rows); err != nil {
+		log.Printf("❌ Failed to store ownership history for %s: %v", block.BlockHash, err)
+		return err
+	}
+	return nil
+}
+
+// BackfillOwnershipHistory derives ownership events from the Tokens of transfer, mint and
+// burnt blocks stored before ownership history was tracked
+func BackfillOwnershipHistory() {
+	filled, skipped := 0, 0
+
+	// fill decodes the stored Tokens of one block and stores its ownership events
+	fill := func(block *util.TokenChainBlock, tokens []byte) error {
+		if err := json.Unmarshal(tokens, &block.TransInfo.Tokens); err != nil {
+			skipped++
+			return nil
+		}
+		if err := storeOwnershipEvents(repos, block); err != nil {
+			return err
+		}
+		filled++
+		return nil
+	}
+
+	err := repos.Analytics.EachTransferWithoutOwnership(500, func(batch []models.TransferBlocks) error {
+		for _, tb := range batch {
+			block := &util.TokenChainBlock{
+				BlockHash: tb.BlockHash,
+				TransType: util.TransTypeTransfer,
+				Epoch:     unixEpoch(tb.Epoch),
+			}
+			block.TransInfo.SenderDID = deref(tb.SenderDID)
+			block.TransInfo.ReceiverDID = deref(tb.ReceiverDID)
+			block.TransInfo.TID = deref(tb.TxnID)
+			if err := fill(block, tb.Tokens); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = repos.Analytics.EachMintWithoutOwnership(500, func(batch []models.MintBlocks) error {
+			for _, mb := range batch {
+				block := &util.TokenChainBlock{
+					BlockHash:  mb.BlockHash,
+					TransType:  mb.TxnType,
+					TokenOwner: mb.OwnerDID,
+					Epoch:      unixEpoch(mb.Epoch),
+				}
+				block.TransInfo.TID = deref(mb.TxnID)
+				if err := fill(block, mb.Tokens); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = repos.Analytics.EachBurntWithoutOwnership(500, func(batch []models.BurntBlocks) error {
+			for _, bb := range batch {
+				block := &util.TokenChainBlock{
+					BlockHash:  bb.BlockHash,
+					TransType:  util.TransTypeBurnt,
+					TokenOwner: bb.OwnerDID,
+					Epoch:      unixEpoch(bb.Epoch),
+				}
+				if err := fill(block, bb.Tokens); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Ownership history backfill stopped: %v", err)
+		return
+	}
+
+	log.Printf("✅ Ownership history backfill done: %d blocks filled, %d skipped", filled, skipped)
+}
+
+// GetTokenOwnershipHistory lists who held a token over time, oldest event first
+func GetTokenOwnershipHistory(tokenID string, timeRange TimeRange, limit, page int) ([]models.TokenOwnership, int64, error) {
+	return repos.Analytics.TokenOwnershipHistory(tokenID, timeRange, repository.NewPage(limit, page))
+}
+
+// GetTokensHeldByDID lists every token a DID ever held, by first acquisition
+func GetTokensHeldByDID(did string, limit, page int) ([]HeldToken, int64, error) {
+	return repos.Analytics.TokensHeldBy(did, repository.NewPage(limit, page))
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+func TestBackfillOwnershipHistoryFromTransferTokens(t *testing.T) {
+	mem := useMemoryRepos(t)
+
+	epoch := time.Unix(1700000000, 0).UTC()
+	if err := mem.Blocks.SaveTransferBlock(&models.TransferBlocks{
+		BlockHash:   "hash-1",
+		SenderDID:   optionalString("did-sender"),
+		ReceiverDID: optionalString("did-receiver"),
+		BlockTime:   models.BlockTime{Epoch: &epoch},
+		Tokens:      []byte(`{"QmToken1": {"1": 0}, "QmToken2": {"1": 0}}`),
+		TxnID:       optionalString("txn-1"),
+	}); err != nil {
+		t.Fatalf("SaveTransferBlock: %v", err)
+	}
+
+	BackfillOwnershipHistory()
+	// a second run finds nothing left to fill
+	BackfillOwnershipHistory()
+
+	history, count, err := GetTokenOwnershipHistory("QmToken2", TimeRange{}, 10, 1)
+	if err != nil {
+		t.Fatalf("GetTokenOwnershipHistory: %v", err)
+	}
+	if count != 1 || len(history) != 1 {
+		t.Fatalf("history = %+v (count %d), want one event", history, count)
+	}
+	if got := history[0]; got.EventType != OwnershipTransfer || deref(got.FromDID) != "did-sender" ||
+		deref(got.ToDID) != "did-receiver" || deref(got.TxnID) != "txn-1" {
+		t.Fatalf("event = %+v", got)
+	}
+
+	held, count, err := GetTokensHeldByDID("did-sender", 10, 1)
+	if err != nil {
+		t.Fatalf("GetTokensHeldByDID: %v", err)
+	}
+	if count != 2 || held[0].Holding || held[0].Releases != 1 {
+		t.Fatalf("held by sender = %+v (count %d), want 2 released tokens", held, count)
+	}
+}
+
+func TestDIDBalanceHistoryBoundsTheSeries(t *testing.T) {
+	useMemoryRepos(t)
+
+	from := time.Date(2023, 11, 15, 10, 30, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	if _, err := GetDIDBalanceHistory("did-a", "hour", TimeRange{From: &from, To: &to}); err != ErrTooManyBalancePoints {
+		t.Fatalf("hourly series over a year: err = %v, want ErrTooManyBalancePoints", err)
+	}
+
+	history, err := GetDIDBalanceHistory("did-a", "month", TimeRange{From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("GetDIDBalanceHistory: %v", err)
+	}
+	if len(history.Balances) != 13 || !history.Balances[12].At.Equal(to) {
+		t.Fatalf("monthly series = %+v, want 12 boundaries and the range end", history.Balances)
+	}
+}
+
+func TestDIDBalanceHistoryCountsAnEventAtTheRangeEnd(t *testing.T) {
+	mem := useMemoryRepos(t)
+
+	received := time.Date(2023, 11, 15, 10, 0, 0, 0, time.UTC)
+	sent := received.Add(2 * time.Hour)
+	if err := mem.Tokens.CreateRBT(&models.RBT{TokenID: "QmToken1", TokenValue: decimal.FromInt(1), OwnerDID: "did-b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.Analytics.SaveOwnershipEvents([]models.TokenOwnership{
+		{TokenID: "QmToken1", BlockHash: "hash-1", EventType: OwnershipTransfer,
+			FromDID: optionalString("did-x"), ToDID: optionalString("did-a"), Epoch: &received},
+		{TokenID: "QmToken1", BlockHash: "hash-2", EventType: OwnershipTransfer,
+			FromDID: optionalString("did-a"), ToDID: optionalString("did-b"), Epoch: &sent},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		to   time.Time
+		want string
+	}{
+		{received.Add(-time.Second), "0"},
+		{received, "1"},
+		{sent.Add(-time.Second), "1"},
+		{sent, "0"},
+	} {
+		to := tc.to
+		history, err := GetDIDBalanceHistory("did-a", "hour", TimeRange{From: &received, To: &to})
+		if err != nil {
+			t.Fatalf("to=%s: %v", to, err)
+		}
+		if got := history.Balance.RBT.String(); got != tc.want {
+			t.Errorf("to=%s: balance %s RBT, want %s", to.Format(time.RFC3339), got, tc.want)
+		}
+		if last := history.Balances[len(history.Balances)-1]; !last.At.Equal(to) || last.RBT != history.Balance.RBT {
+			t.Errorf("to=%s: series ends with %+v, want the range end balance", to.Format(time.RFC3339), last)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"explorer-server/util"
+	"log"
+)
+
+// ErrInvalidInterval is returned for an unsupported volume bucket size
+var ErrInvalidInterval = errors.New("interval must be one of hour, day, week, month")
+
+var volumeIntervals = map[string]bool{"hour": true, "day": true, "week": true, "month": true}
+
+// QuorumStats summarizes the participation of one quorum DID
+type QuorumStats = repository.QuorumStats
+
+// QuorumVolumePoint is the pledged volume of one time bucket
+type QuorumVolumePoint = repository.QuorumVolumePoint
+
+// QuorumTransaction is one block a quorum pledged tokens for
+type QuorumTransaction = repository.QuorumTransaction
+
+// pledgedTokenValues looks up the RBT value of every pledged token; unknown tokens are absent
+func pledgedTokenValues(r repository.Repositories, details util.PledgeDetails) map[string]decimal.Decimal {
+	var ids []string
+	for _, pledges := range details {
+		for _, p := range pledges {
+			ids = append(ids, p.Token)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	values, err := r.Tokens.RBTValues(ids)
+	if err != nil {
+		log.Printf("⚠️ Failed to look up pledged token values: %v", err)
+		return nil
+	}
+	return values
+}
+
+// storeQuorumPledges records one row per pledged token of a block's pledge details
+func storeQuorumPledges(r repository.Repositories, block *util.TokenChainBlock) error {
+	if len(block.PledgeDetails) == 0 {
+		return nil
+	}
+
+	values := pledgedTokenValues(r, block.PledgeDetails)
+
+	var rows []models.QuorumPledge
+	for quorumDID, pledges := range block.PledgeDetails {
+		for _, p := range pledges {
+			row := models.QuorumPledge{
+				BlockHash:           block.BlockHash,
+				TxnID:               optionalString(block.TransInfo.TID),
+				TxnType:             block.TransType,
+				QuorumDID:           quorumDID,
+				PledgedToken:        p.Token,
+				PledgedTokenType:    p.TokenType,
+				PledgedTokenBlockID: p.TokenBlockID,
+				Epoch:               blockTime(block).Epoch,
+			}
+			if v, ok := values[p.Token]; ok {
+				row.PledgedValue = decimal.Ptr(v)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := r.Analytics.SaveQuorumPledges(rows); err != nil {
+		log.Printf("❌ Failed to store quorum pledges for %s: %v", block.BlockHash, err)
+		return err
+	}
+	return nil
+}
+
+// BackfillQuorumPledges parses the ValidatorPledgeMap of transfer blocks stored
+// before pledges were normalized and fills QuorumPledges from it.
+func BackfillQuorumPledges() {
+	filled, skipped := 0, 0
+
+	err := repos.Analytics.EachTransferWithoutPledges(500, func(batch []models.TransferBlocks) error {
+		for _, tb := range batch {
+			var raw interface{}
+			if err := json.Unmarshal(tb.ValidatorPledgeMap, &raw); err != nil {
+				skipped++
+				continue
+			}
+			details, err := util.DecodePledgeDetails(raw)
+			if err != nil {
+				log.Printf("⚠️ Skipping pledge map of %s: %v", tb.BlockHash, err)
+				skipped++
+				continue
+			}
+
+			block := &util.TokenChainBlock{
+				BlockHash:     tb.BlockHash,
+				TransType:     deref(tb.TxnType),
+				Epoch:         unixEpoch(tb.Epoch),
+				PledgeDetails: details,
+			}
+			block.TransInfo.TID = deref(tb.TxnID)
+
+			if err := storeQuorumPledges(repos, block); err != nil {
+				return err
+			}
+			filled++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Quorum pledge backfill stopped: %v", err)
+		return
+	}
+
+	log.Printf("✅ Quorum pledge backfill done: %d blocks filled, %d skipped", filled, skipped)
+}
+
+// GetQuorumStats returns per-quorum validation counts, most active first
+func GetQuorumStats(limit, page int) ([]QuorumStats, int64, error) {
+	return repos.Analytics.QuorumStats(repository.NewPage(limit, page))
+}
+
+// GetQuorumPledgeVolume returns pledged volume per time bucket within the time range,
+// for one quorum or all of them
+func GetQuorumPledgeVolume(quorumDID, interval string, timeRange TimeRange) ([]QuorumVolumePoint, error) {
+	if interval == "" {
+		interval = "day"
+	}
+	if !volumeIntervals[interval] {
+		return nil, ErrInvalidInterval
+	}
+
+	return repos.Analytics.QuorumVolume(quorumDID, interval, timeRange)
+}
+
+// GetQuorumTransactions lists the blocks a quorum pledged tokens for, newest first
+func GetQuorumTransactions(quorumDID string, limit, page int) ([]QuorumTransaction, int64, error) {
+	return repos.Analytics.QuorumTransactions(quorumDID, repository.NewPage(limit, page))
+}
@@ -0,0 +1,11 @@
+package services
+
+import "explorer-server/repository"
+
+// repos is the storage the services read and write; main installs the Postgres repositories
+var repos repository.Repositories
+
+// SetRepositories installs the repositories the services run on
+func SetRepositories(r repository.Repositories) {
+	repos = r
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"testing"
+
+	"explorer-server/repository"
+)
+
+// useMemoryRepos runs the services of one test on fresh in-memory repositories
+func useMemoryRepos(t *testing.T) repository.Repositories {
+	t.Helper()
+	prev := repos
+	mem := repository.NewMemory()
+	SetRepositories(mem)
+	t.Cleanup(func() { SetRepositories(prev) })
+	return mem
+}
@@ -1,45 +1,28 @@
 package services
 
 import (
-	"explorer-server/database"
 	"explorer-server/database/models"
 	"explorer-server/model"
+	"explorer-server/repository"
 )
 
 func GetSCCount() (int64, error) {
-	var count int64
-	if err := database.DB.Model(&models.SmartContract{}).Count(&count).Error; err != nil {
-		return 0, err
-	}
-	return count, nil
+	return repos.SmartContracts.CountSmartContracts()
 }
 
 func GetSCInfoFromSCID(scID string) (*models.SmartContract, error) {
-	var scInfo models.SmartContract
-	if err := database.DB.First(&scInfo, "contract_id = ?", scID).Error; err != nil {
-		return nil, err
-	}
-	return &scInfo, nil
+	return repos.SmartContracts.GetSmartContract(scID)
 }
 
-func GetSCBlockList(limit, page int) (interface{}, error) {
-	var blocks []models.SC_Block
-
+func GetSCBlockList(limit, page int, timeRange TimeRange) (interface{}, error) {
 	offset := (page - 1) * limit
 
 	// Fetch all blocks with pagination
-	if err := database.DB.
-		Limit(int(limit)).
-		Offset(int(offset)).
-		Find(&blocks).Error; err != nil {
+	blocks, count, err := repos.SmartContracts.ListSCBlocks(timeRange, repository.Page{Limit: limit, Offset: offset})
+	if err != nil {
 		return nil, err
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.SC_Block{}).Count(&count).Error; err != nil {
-		return model.SCBlocksListResponse{}, err
-	}
-
 	// Wrap in response struct
 	response := model.SCBlocksListResponse{
 		SC_Blocks: blocks,
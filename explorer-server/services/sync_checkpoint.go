@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"explorer-server/database/models"
+	"explorer-server/repository"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Checkpoint statuses
+const (
+	CheckpointSynced = "synced"
+	CheckpointEmpty  = "empty"
+	CheckpointFailed = "failed"
+)
+
+// tokenChainResyncInterval is how long a checkpoint stays fresh for tokens
+// whose current head block is unknown (missing in the asset tables).
+const tokenChainResyncInterval = 24 * time.Hour
+
+// loadSyncCheckpoints returns all persisted token-chain checkpoints keyed by token ID
+func loadSyncCheckpoints() (map[string]models.TokenSyncCheckpoint, error) {
+	checkpoints, err := repos.Sync.ListCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	byToken := make(map[string]models.TokenSyncCheckpoint, len(checkpoints))
+	for _, cp := range checkpoints {
+		byToken[cp.TokenID] = cp
+	}
+	return byToken, nil
+}
+
+// saveSyncCheckpoint upserts the checkpoint for a single token
+func saveSyncCheckpoint(cp models.TokenSyncCheckpoint) {
+	if err := repos.Sync.SaveCheckpoint(&cp); err != nil {
+		log.Printf("⚠️ Failed to save sync checkpoint for %s: %v", cp.TokenID, err)
+	}
+}
+
+// markSyncCheckpointFailed records a failed sync, keeping the last good block position
+func markSyncCheckpointFailed(token models.TokenType, prev *models.TokenSyncCheckpoint, syncErr error) {
+	cp := models.TokenSyncCheckpoint{
+		TokenID:   token.TokenID,
+		TokenType: token.TokenType,
+		Status:    CheckpointFailed,
+		LastError: syncErr.Error(),
+	}
+	if prev != nil {
+		cp.LastBlockHeight = prev.LastBlockHeight
+		cp.LastBlockHash = prev.LastBlockHash
+		cp.LastSyncedAt = prev.LastSyncedAt
+	}
+	saveSyncCheckpoint(cp)
+}
+
+// tokenChainHead returns the latest block hash the asset tables know for a token.
+// An empty hash means the head is unknown.
+func tokenChainHead(token models.TokenType) string {
+	var head string
+	var err error
+
+	switch token.TokenType {
+	case RBTType, PartType:
+		var rbt *models.RBT
+		if rbt, err = repos.Tokens.GetRBT(token.TokenID); err == nil {
+			head = rbt.BlockID
+		}
+	case FTType:
+		var ft *models.FT
+		if ft, err = repos.Tokens.GetFT(token.TokenID); err == nil {
+			head = ft.BlockID
+		}
+	case NFTType:
+		var nft *models.NFT
+		if nft, err = repos.Tokens.GetNFT(token.TokenID); err == nil {
+			head = nft.BlockHash
+		}
+	case SCType, "SmartContract":
+		var sc *models.SmartContract
+		if sc, err = repos.SmartContracts.GetSmartContract(token.TokenID); err == nil {
+			head = sc.BlockHash
+		}
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return ""
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to read chain head for %s: %v", token.TokenID, err)
+		return ""
+	}
+	return head
+}
+
+// needsChainSync decides whether a token's chain moved since its last checkpoint
+func needsChainSync(token models.TokenType, cp *models.TokenSyncCheckpoint) bool {
+	if cp == nil || cp.Status == CheckpointFailed {
+		return true
+	}
+
+	if head := tokenChainHead(token); head != "" {
+		return head != cp.LastBlockHash
+	}
+
+	return time.Since(cp.LastSyncedAt) > tokenChainResyncInterval
+}
+
+// chainBlockHash extracts the block hash from a block in numeric or named key format
+func chainBlockHash(block map[string]interface{}) string {
+	if hash, ok := getValue(block, "98", "TCBlockHashKey").(string); ok {
+		return hash
+	}
+	return ""
+}
+
+// chainBlockHeight extracts the block number of tokenID from a block in numeric or named key format
+func chainBlockHeight(block map[string]interface{}, tokenID string) int64 {
+	transInfo := getMap(block, "5", "TCTransInfoKey")
+	tokens := getMap(transInfo, "6", "TITokensKey")
+
+	tokenInfo, ok := tokens[tokenID].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+
+	switch v := getValue(tokenInfo, "4", "TTBlockNumberKey").(type) {
+	case string:
+		if h, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return h
+		}
+	case json.Number:
+		if h, err := v.Int64(); err == nil {
+			return h
+		}
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenChainSyncProgress is a snapshot of the current (or last) token-chain sync run
+type TokenChainSyncProgress struct {
+	Running      bool       `json:"running"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	Total        int        `json:"total"`
+	Done         int        `json:"done"`
+	Failed       int        `json:"failed"`
+	Unchanged    int        `json:"unchanged"`
+	InFlight     int        `json:"in_flight"`
+	Concurrency  int        `json:"concurrency"`
+	CurrentToken string     `json:"current_token"`
+	ETASeconds   int64      `json:"eta_seconds"`
+	ETA          *time.Time `json:"eta"`
+}
+
+type syncProgressTracker struct {
+	mu sync.Mutex
+	p  TokenChainSyncProgress
+}
+
+var chainSyncProgress = &syncProgressTracker{}
+
+// start resets the tracker for a new run; it returns false if a run is already active
+func (t *syncProgressTracker) start(total, concurrency int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.p.Running {
+		return false
+	}
+
+	now := time.Now()
+	t.p = TokenChainSyncProgress{
+		Running:     true,
+		StartedAt:   &now,
+		Total:       total,
+		Concurrency: concurrency,
+	}
+	return true
+}
+
+func (t *syncProgressTracker) begin(tokenID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.p.InFlight++
+	t.p.CurrentToken = tokenID
+}
+
+// finish records the outcome of one token; changed is false when the chain did not move
+func (t *syncProgressTracker) finish(changed bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.p.InFlight--
+	switch {
+	case err != nil:
+		t.p.Failed++
+	case !changed:
+		t.p.Unchanged++
+	default:
+		t.p.Done++
+	}
+}
+
+func (t *syncProgressTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.p.Running = false
+	t.p.FinishedAt = &now
+	t.p.InFlight = 0
+	t.p.CurrentToken = ""
+}
+
+// snapshot returns a copy of the progress with a fresh ETA estimate
+func (t *syncProgressTracker) snapshot() TokenChainSyncProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.p
+	processed := p.Done + p.Failed + p.Unchanged
+	if p.Running && p.StartedAt != nil && processed > 0 {
+		elapsed := time.Since(*p.StartedAt)
+		remaining := p.Total - processed
+		eta := time.Duration(int64(elapsed) / int64(processed) * int64(remaining))
+		etaAt := time.Now().Add(eta)
+
+		p.ETASeconds = int64(eta.Seconds())
+		p.ETA = &etaAt
+	}
+	return p
+}
+
+// GetTokenChainSyncProgress returns the progress of the current or last token-chain sync
+func GetTokenChainSyncProgress() TokenChainSyncProgress {
+	return chainSyncProgress.snapshot()
+}
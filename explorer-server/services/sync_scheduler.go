@@ -22,24 +22,7 @@ func StartContinuousSync(maxParallel int) {
 			start := time.Now()
 			log.Println("🔄 [SYNC] Initial full asset + token-chain sync STARTED")
 
-			// 1) Asset lists (RBT / FT / NFT / SC)
-			if err := FetchAndStoreAllRBTsFromFullNodeDB(); err != nil {
-				log.Printf("⚠️ [SYNC] FetchAndStoreAllRBTsFromFullNodeDB error: %v", err)
-			}
-			if err := FetchAndStoreAllFTsFromFullNodeDB(); err != nil {
-				log.Printf("⚠️ [SYNC] FetchAndStoreAllFTsFromFullNodeDB error: %v", err)
-			}
-			if err := FetchAndStoreAllNFTsFromFullNodeDB(); err != nil {
-				log.Printf("⚠️ [SYNC] FetchAndStoreAllNFTsFromFullNodeDB error: %v", err)
-			}
-			if err := FetchAndStoreAllSCsFromFullNodeDB(); err != nil {
-				log.Printf("⚠️ [SYNC] FetchAndStoreAllSCsFromFullNodeDB error: %v", err)
-			}
-
-			// 2) Heavy get-token-chain sync
-			if err := FetchAllTokenChainFromFullNode(); err != nil {
-				log.Printf("⚠️ [SYNC] FetchAllTokenChainFromFullNode error: %v", err)
-			}
+			RunFullSync()
 
 			log.Printf("✅ [SYNC] Initial full sync COMPLETED in %s", time.Since(start).Round(time.Second))
 		})
@@ -76,18 +59,7 @@ func enqueueAssetListSync() {
 		start := time.Now()
 		log.Println("🔄 [SYNC] Periodic asset-list sync STARTED")
 
-		if err := FetchAndStoreAllRBTsFromFullNodeDB(); err != nil {
-			log.Printf("⚠️ [SYNC] FetchAndStoreAllRBTsFromFullNodeDB error: %v", err)
-		}
-		if err := FetchAndStoreAllFTsFromFullNodeDB(); err != nil {
-			log.Printf("⚠️ [SYNC] FetchAndStoreAllFTsFromFullNodeDB error: %v", err)
-		}
-		if err := FetchAndStoreAllNFTsFromFullNodeDB(); err != nil {
-			log.Printf("⚠️ [SYNC] FetchAndStoreAllNFTsFromFullNodeDB error: %v", err)
-		}
-		if err := FetchAndStoreAllSCsFromFullNodeDB(); err != nil {
-			log.Printf("⚠️ [SYNC] FetchAndStoreAllSCsFromFullNodeDB error: %v", err)
-		}
+		syncAssetLists()
 
 		log.Printf("✅ [SYNC] Periodic asset-list sync COMPLETED in %s", time.Since(start).Round(time.Second))
 	})
@@ -115,3 +87,28 @@ func enqueueTokenChainSync() {
 		log.Println("⚠️ [SYNC] Token-chain sync task DROPPED (sync queue full)")
 	}
 }
+
+// RunFullSync fetches the asset lists and then every token chain, on the calling goroutine
+func RunFullSync() {
+	syncAssetLists()
+
+	if err := FetchAllTokenChainFromFullNode(); err != nil {
+		log.Printf("⚠️ [SYNC] FetchAllTokenChainFromFullNode error: %v", err)
+	}
+}
+
+// syncAssetLists refreshes the RBT / FT / NFT / SC lists from the fullnode
+func syncAssetLists() {
+	if err := FetchAndStoreAllRBTsFromFullNodeDB(); err != nil {
+		log.Printf("⚠️ [SYNC] FetchAndStoreAllRBTsFromFullNodeDB error: %v", err)
+	}
+	if err := FetchAndStoreAllFTsFromFullNodeDB(); err != nil {
+		log.Printf("⚠️ [SYNC] FetchAndStoreAllFTsFromFullNodeDB error: %v", err)
+	}
+	if err := FetchAndStoreAllNFTsFromFullNodeDB(); err != nil {
+		log.Printf("⚠️ [SYNC] FetchAndStoreAllNFTsFromFullNodeDB error: %v", err)
+	}
+	if err := FetchAndStoreAllSCsFromFullNodeDB(); err != nil {
+		log.Printf("⚠️ [SYNC] FetchAndStoreAllSCsFromFullNodeDB error: %v", err)
+	}
+}
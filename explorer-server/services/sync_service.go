@@ -4,21 +4,18 @@ import (
 	"encoding/json"
 	"errors"
 	"explorer-server/config"
-	"explorer-server/database"
 	"explorer-server/database/models"
+	"explorer-server/decimal"
+	"explorer-server/repository"
+	"explorer-server/util"
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
-	"reflect"
-	"regexp"
-	"strconv"
+	"sync"
 	"time"
 
 	"gorm.io/datatypes"
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 const (
@@ -29,47 +26,50 @@ const (
 	SCType   = "SC"
 )
 
+// BlockSourcePush marks blocks pushed by a fullnode through /api/block-update
+const BlockSourcePush = "push"
+
 // These structs are ONLY for receiving API responses
 
 // RBT - All PascalCase
 type RBT struct {
-	TokenID       string  `json:"TokenID"`
-	TokenValue    float64 `json:"TokenValue"`
-	OwnerDID      string  `json:"OwnerDID"`
-	PublisherDID  string  `json:"PublisherDID"`
-	TransactionID string  `json:"TransactionID"`
-	BlockHash     string  `json:"BlockHash"`
-	BlockHeight   uint64  `json:"BlockHeight"`
-	SyncStaus     int     `json:"SyncStaus"` // Note: typo in API
-	TokenStatus   int     `json:"TokenStatus"`
+	TokenID       string          `json:"TokenID"`
+	TokenValue    decimal.Decimal `json:"TokenValue"`
+	OwnerDID      string          `json:"OwnerDID"`
+	PublisherDID  string          `json:"PublisherDID"`
+	TransactionID string          `json:"TransactionID"`
+	BlockHash     string          `json:"BlockHash"`
+	BlockHeight   uint64          `json:"BlockHeight"`
+	SyncStaus     int             `json:"SyncStaus"` // Note: typo in API
+	TokenStatus   int             `json:"TokenStatus"`
 }
 
 // FT - All PascalCase
 type FT struct {
-	TokenID       string  `json:"TokenID"`
-	FTName        string  `json:"FTName"`
-	OwnerDID      string  `json:"OwnerDID"`
-	CreatorDID    string  `json:"CreatorDID"`
-	PublisherDID  string  `json:"PublisherDID"`
-	TokenValue    float64 `json:"TokenValue"`
-	TransactionID string  `json:"TransactionID"`
-	BlockHash     string  `json:"BlockHash"`
-	BlockHeight   uint64  `json:"BlockHeight"`
-	SyncStatus    int     `json:"SyncStatus"`
-	TokenStatus   int     `json:"TokenStatus"`
+	TokenID       string          `json:"TokenID"`
+	FTName        string          `json:"FTName"`
+	OwnerDID      string          `json:"OwnerDID"`
+	CreatorDID    string          `json:"CreatorDID"`
+	PublisherDID  string          `json:"PublisherDID"`
+	TokenValue    decimal.Decimal `json:"TokenValue"`
+	TransactionID string          `json:"TransactionID"`
+	BlockHash     string          `json:"BlockHash"`
+	BlockHeight   uint64          `json:"BlockHeight"`
+	SyncStatus    int             `json:"SyncStatus"`
+	TokenStatus   int             `json:"TokenStatus"`
 }
 
 // NFT - Mix of snake_case and PascalCase
 type NFT struct {
-	TokenID       string  `json:"token_id"`
-	TokenValue    float64 `json:"token_value"`
-	OwnerDID      string  `json:"OwnerDID"`
-	PublisherDID  string  `json:"PublisherDID"`
-	TransactionID string  `json:"TransactionID"`
-	BlockHash     string  `json:"BlockHash"`
-	BlockHeight   uint64  `json:"BlockHeight"`
-	SyncStatus    int     `json:"SyncStatus"`
-	TokenStatus   int     `json:"TokenStatus"`
+	TokenID       string          `json:"token_id"`
+	TokenValue    decimal.Decimal `json:"token_value"`
+	OwnerDID      string          `json:"OwnerDID"`
+	PublisherDID  string          `json:"PublisherDID"`
+	TransactionID string          `json:"TransactionID"`
+	BlockHash     string          `json:"BlockHash"`
+	BlockHeight   uint64          `json:"BlockHeight"`
+	SyncStatus    int             `json:"SyncStatus"`
+	TokenStatus   int             `json:"TokenStatus"`
 }
 
 // SC - Mix of snake_case and lowercase/PascalCase
@@ -111,15 +111,14 @@ type GetSCListResponse struct {
 
 // FetchAndStoreAllRBTsFromFullNodeDB fetches RBTs from full node API and stores them
 func FetchAndStoreAllRBTsFromFullNodeDB() error {
-	apiURL := config.RubixNodeURL + "/api/de-exp/get-rbt-list"
+	apiPath := "/api/de-exp/get-rbt-list"
 
-	log.Println("📡 Fetching RBT list from:", apiURL)
+	log.Println("📡 Fetching RBT list from:", apiPath)
 
-	client := GetNodeHTTPClient()
 	release := acquireNodeSlot()
 	defer release()
 
-	resp, err := client.Get(apiURL)
+	resp, nodeURL, err := nodeGet(apiPath)
 	if err != nil {
 		return fmt.Errorf("failed to call get-rbt-list API: %w", err)
 	}
@@ -148,20 +147,19 @@ func FetchAndStoreAllRBTsFromFullNodeDB() error {
 		return fmt.Errorf("failed to store RBTs: %w", err)
 	}
 
-	log.Printf("✅ Successfully fetched and stored %d RBTs\n", len(apiResp.Result))
+	log.Printf("✅ Successfully fetched and stored %d RBTs from %s\n", len(apiResp.Result), nodeURL)
 	return nil
 }
 
 func FetchAndStoreAllFTsFromFullNodeDB() error {
-	apiURL := config.RubixNodeURL + "/api/de-exp/get-ft-list"
+	apiPath := "/api/de-exp/get-ft-list"
 
-	log.Println("📡 Fetching FT list from:", apiURL)
+	log.Println("📡 Fetching FT list from:", apiPath)
 
-	client := GetNodeHTTPClient()
 	release := acquireNodeSlot()
 	defer release()
 
-	resp, err := client.Get(apiURL)
+	resp, nodeURL, err := nodeGet(apiPath)
 	if err != nil {
 		return fmt.Errorf("failed to call get-ft-list API: %w", err)
 	}
@@ -190,20 +188,19 @@ func FetchAndStoreAllFTsFromFullNodeDB() error {
 		return fmt.Errorf("failed to store FTs: %w", err)
 	}
 
-	log.Printf("✅ Successfully fetched and stored %d FTs\n", len(apiResp.Result))
+	log.Printf("✅ Successfully fetched and stored %d FTs from %s\n", len(apiResp.Result), nodeURL)
 	return nil
 }
 
 func FetchAndStoreAllNFTsFromFullNodeDB() error {
-	apiURL := config.RubixNodeURL + "/api/de-exp/get-nft-list"
+	apiPath := "/api/de-exp/get-nft-list"
 
-	log.Println("📡 Fetching NFT list from:", apiURL)
+	log.Println("📡 Fetching NFT list from:", apiPath)
 
-	client := GetNodeHTTPClient()
 	release := acquireNodeSlot()
 	defer release()
 
-	resp, err := client.Get(apiURL)
+	resp, nodeURL, err := nodeGet(apiPath)
 	if err != nil {
 		return fmt.Errorf("failed to call get-nft-list API: %w", err)
 	}
@@ -232,20 +229,19 @@ func FetchAndStoreAllNFTsFromFullNodeDB() error {
 		return fmt.Errorf("failed to store NFTs: %w", err)
 	}
 
-	log.Printf("✅ Successfully fetched and stored %d NFTs\n", len(apiResp.Result))
+	log.Printf("✅ Successfully fetched and stored %d NFTs from %s\n", len(apiResp.Result), nodeURL)
 	return nil
 }
 
 func FetchAndStoreAllSCsFromFullNodeDB() error {
-	apiURL := config.RubixNodeURL + "/api/de-exp/get-smart-contract-list"
+	apiPath := "/api/de-exp/get-smart-contract-list"
 
-	log.Println("📡 Fetching SC list from:", apiURL)
+	log.Println("📡 Fetching SC list from:", apiPath)
 
-	client := GetNodeHTTPClient()
 	release := acquireNodeSlot()
 	defer release()
 
-	resp, err := client.Get(apiURL)
+	resp, nodeURL, err := nodeGet(apiPath)
 	if err != nil {
 		return fmt.Errorf("failed to call get-smart-contract-list API: %w", err)
 	}
@@ -274,13 +270,13 @@ func FetchAndStoreAllSCsFromFullNodeDB() error {
 		return fmt.Errorf("failed to store SCs: %w", err)
 	}
 
-	log.Printf("✅ Successfully fetched and stored %d SCs\n", len(apiResp.Result))
+	log.Printf("✅ Successfully fetched and stored %d SCs from %s\n", len(apiResp.Result), nodeURL)
 	return nil
 }
 
 // StoreRBTInfoInDB inserts RBTs into DB and ensures a corresponding token_type entry exists
 func StoreRBTInfoInDB(RBTs []RBT) error {
-	didValueSum := make(map[string]float64)
+	var owners []string
 	for _, rbt := range RBTs {
 		rbtModel := models.RBT{
 			TokenID:     rbt.TokenID,
@@ -291,20 +287,16 @@ func StoreRBTInfoInDB(RBTs []RBT) error {
 			TokenStatus: rbt.TokenStatus,
 		}
 
-		var existingRBT models.RBT
-		err := database.DB.Where("rbt_id = ?", rbt.TokenID).First(&existingRBT).Error
+		_, err := repos.Tokens.GetRBT(rbt.TokenID)
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if err := database.DB.Create(&rbtModel).Error; err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			if err := repos.Tokens.CreateRBT(&rbtModel); err != nil {
 				log.Printf("⚠️ Failed to insert RBT %s: %v", rbt.TokenID, err)
 				continue
 			}
 			log.Printf("✅ RBT inserted: %s", rbt.TokenID)
 
-			// Only add to sum if TokenStatus is 0 (free)
-			if rbt.TokenStatus == 0 {
-				didValueSum[rbt.OwnerDID] += rbt.TokenValue
-			}
+			owners = append(owners, rbt.OwnerDID)
 		} else if err != nil {
 			log.Printf("⚠️ Error checking RBT %s: %v", rbt.TokenID, err)
 			continue
@@ -318,40 +310,20 @@ func StoreRBTInfoInDB(RBTs []RBT) error {
 			LastUpdated: time.Now(),
 		}
 
-		if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: rbt.TokenID}).Error; err != nil {
+		if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 			log.Printf("⚠️ Failed to insert token_type for %s: %v", rbt.TokenID, err)
 		}
 	}
 
-	for did, valueSum := range didValueSum {
-		roundedValue := math.Round(valueSum*1000) / 1000
-
-		var existing models.DIDs
-		if err := database.DB.First(&existing, "did = ?", did).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				newDID := models.DIDs{
-					DID:       did,
-					CreatedAt: time.Now(),
-					TotalRBTs: roundedValue,
-				}
-				if err := database.DB.Create(&newDID).Error; err != nil {
-					log.Printf("⚠️ Failed to create DID %s: %v", did, err)
-				}
-			}
-		} else {
-			existing.TotalRBTs += roundedValue
-			existing.TotalRBTs = math.Round(existing.TotalRBTs*1000) / 1000
-			if err := database.DB.Save(&existing).Error; err != nil {
-				log.Printf("⚠️ Failed to update TotalRBTs for DID %s: %v", did, err)
-			}
-		}
+	if err := recomputeDIDTotals(owners...); err != nil {
+		log.Printf("⚠️ Failed to update DID totals after storing RBTs: %v", err)
 	}
 
 	return nil
 }
 
 func StoreFTInfoInDB(FTs []FT) error {
-	didCount := make(map[string]int)
+	var owners []string
 	for _, ft := range FTs {
 		ftmodel := models.FT{
 			FtID:        ft.TokenID,
@@ -364,20 +336,16 @@ func StoreFTInfoInDB(FTs []FT) error {
 			TokenStatus: ft.TokenStatus,
 		}
 
-		var existingFT models.FT
-		err := database.DB.Where("ft_id = ?", ft.TokenID).First(&existingFT).Error
+		_, err := repos.Tokens.GetFT(ft.TokenID)
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if err := database.DB.Create(&ftmodel).Error; err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			if err := repos.Tokens.CreateFT(&ftmodel); err != nil {
 				log.Printf("⚠️ Failed to insert FT %s: %v", ft.TokenID, err)
 				continue
 			}
 			log.Printf("✅ FT inserted: %s", ft.TokenID)
 
-			// Only increment count if TokenStatus is 0 (free)
-			if ft.TokenStatus == 0 {
-				didCount[ft.OwnerDID]++
-			}
+			owners = append(owners, ft.OwnerDID)
 		} else if err != nil {
 			log.Printf("⚠️ Error checking FT %s: %v", ft.TokenID, err)
 			continue
@@ -391,41 +359,24 @@ func StoreFTInfoInDB(FTs []FT) error {
 			LastUpdated: time.Now(),
 		}
 
-		if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: ft.TokenID}).Error; err != nil {
+		if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 			log.Printf("⚠️ Failed to insert token_type for %s: %v", ft.TokenID, err)
 		}
 	}
 
-	for did, count := range didCount {
-		var existing models.DIDs
-		if err := database.DB.First(&existing, "did = ?", did).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				newDID := models.DIDs{
-					DID:       did,
-					CreatedAt: time.Now(),
-					TotalFTs:  float64(count),
-				}
-				if err := database.DB.Create(&newDID).Error; err != nil {
-					log.Printf("⚠️ Failed to create DID %s: %v", did, err)
-				}
-			}
-		} else {
-			existing.TotalFTs += float64(count)
-			if err := database.DB.Save(&existing).Error; err != nil {
-				log.Printf("⚠️ Failed to update TotalFTs for DID %s: %v", did, err)
-			}
-		}
+	if err := recomputeDIDTotals(owners...); err != nil {
+		log.Printf("⚠️ Failed to update DID totals after storing FTs: %v", err)
 	}
 
 	return nil
 }
 
 func StoreNFTInfoInDB(NFTs []NFT) error {
-	didCount := make(map[string]int)
+	var owners []string
 	for _, nft := range NFTs {
 		nftmodel := models.NFT{
 			TokenID:     nft.TokenID,
-			TokenValue:  fmt.Sprintf("%f", nft.TokenValue),
+			TokenValue:  nft.TokenValue,
 			OwnerDID:    nft.OwnerDID,
 			BlockHash:   nft.BlockHash,
 			Txn_ID:      nft.TransactionID,
@@ -433,17 +384,16 @@ func StoreNFTInfoInDB(NFTs []NFT) error {
 			TokenStatus: nft.TokenStatus,
 		}
 
-		var existingNFT models.NFT
-		err := database.DB.Where("nft_id = ?", nft.TokenID).First(&existingNFT).Error
+		_, err := repos.Tokens.GetNFT(nft.TokenID)
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if err := database.DB.Create(&nftmodel).Error; err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			if err := repos.Tokens.CreateNFT(&nftmodel); err != nil {
 				log.Printf("⚠️ Failed to insert NFT %s: %v", nft.TokenID, err)
 				continue
 			}
 			log.Printf("✅ NFT inserted: %s", nft.TokenID)
 
-			didCount[nft.OwnerDID]++
+			owners = append(owners, nft.OwnerDID)
 		} else if err != nil {
 			log.Printf("⚠️ Error checking NFT %s: %v", nft.TokenID, err)
 			continue
@@ -457,37 +407,20 @@ func StoreNFTInfoInDB(NFTs []NFT) error {
 			LastUpdated: time.Now(),
 		}
 
-		if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: nft.TokenID}).Error; err != nil {
+		if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 			log.Printf("⚠️ Failed to insert token_type for %s: %v", nft.TokenID, err)
 		}
 	}
 
-	for did, count := range didCount {
-		var existing models.DIDs
-		if err := database.DB.First(&existing, "did = ?", did).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				newDID := models.DIDs{
-					DID:       did,
-					CreatedAt: time.Now(),
-					TotalNFTs: int64(count),
-				}
-				if err := database.DB.Create(&newDID).Error; err != nil {
-					log.Printf("⚠️ Failed to create DID %s: %v", did, err)
-				}
-			}
-		} else {
-			existing.TotalNFTs += int64(count)
-			if err := database.DB.Save(&existing).Error; err != nil {
-				log.Printf("⚠️ Failed to update TotalNFTs for DID %s: %v", did, err)
-			}
-		}
+	if err := recomputeDIDTotals(owners...); err != nil {
+		log.Printf("⚠️ Failed to update DID totals after storing NFTs: %v", err)
 	}
 
 	return nil
 }
 
 func StoreSCInfoInDB(SCs []SC) error {
-	didCount := make(map[string]int)
+	var deployers []string
 	for _, sc := range SCs {
 		scmodel := models.SmartContract{
 			ContractID:  sc.SmartContractHash,
@@ -498,17 +431,16 @@ func StoreSCInfoInDB(SCs []SC) error {
 			TokenStatus: sc.TokenStatus,
 		}
 
-		var existingSC models.SmartContract
-		err := database.DB.Where("contract_id = ?", sc.SmartContractHash).First(&existingSC).Error
+		_, err := repos.SmartContracts.GetSmartContract(sc.SmartContractHash)
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if err := database.DB.Create(&scmodel).Error; err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			if err := repos.SmartContracts.CreateSmartContract(&scmodel); err != nil {
 				log.Printf("⚠️ Failed to insert SC %s: %v", sc.SmartContractHash, err)
 				continue
 			}
 			log.Printf("✅ SC inserted: %s", sc.SmartContractHash)
 
-			didCount[sc.Deployer]++
+			deployers = append(deployers, sc.Deployer)
 		} else if err != nil {
 			log.Printf("⚠️ Error checking SC %s: %v", sc.SmartContractHash, err)
 			continue
@@ -521,359 +453,282 @@ func StoreSCInfoInDB(SCs []SC) error {
 			TokenType:   SCType,
 			LastUpdated: time.Now(),
 		}
-		if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: sc.SmartContractHash}).Error; err != nil {
+		if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 			log.Printf("⚠️ Failed to insert token_type for SC %s: %v", sc.SmartContractHash, err)
 		}
 	}
 
-	for did, count := range didCount {
-		var existing models.DIDs
-		if err := database.DB.First(&existing, "did = ?", did).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				newDID := models.DIDs{
-					DID:       did,
-					CreatedAt: time.Now(),
-					TotalSC:   int64(count),
-				}
-				if err := database.DB.Create(&newDID).Error; err != nil {
-					log.Printf("⚠️ Failed to create DID %s: %v", did, err)
-				}
-			}
-		} else {
-			existing.TotalSC += int64(count)
-			if err := database.DB.Save(&existing).Error; err != nil {
-				log.Printf("⚠️ Failed to update TotalSC for DID %s: %v", did, err)
-			}
-		}
+	if err := recomputeDIDTotals(deployers...); err != nil {
+		log.Printf("⚠️ Failed to update DID totals after storing SCs: %v", err)
 	}
 
 	return nil
 }
 
 // StoreTransferBlock handles inserting a single transfer-type block into DB
-func StoreTransferBlock(blockMap map[string]interface{}) {
-	transInfo, _ := blockMap["TCTransInfoKey"].(map[string]interface{})
-	tokensKey, _ := transInfo["TITokensKey"].(map[string]interface{})
-
-	tokensJSON, _ := json.Marshal(tokensKey)
-	pledgeMapJSON, _ := json.Marshal(blockMap["TCPledgeDetailsKey"])
-
-	amount := float64Ptr(blockMap["TCTokenValueKey"])
-	epoch := int64Ptr(blockMap["TCEpoch"])
+func StoreTransferBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	tokensJSON, _ := json.Marshal(block.TransInfo.Tokens)
+	pledgeMapJSON, _ := json.Marshal(block.PledgeDetails)
 
 	tb := models.TransferBlocks{
-		BlockHash: fmt.Sprintf("%v", blockMap["TCBlockHashKey"]),
-
-		PrevBlockID:        stringPtr(getNested(transInfo, "TTPreviousBlockIDKey")),
-		SenderDID:          stringPtr(getNested(transInfo, "TISenderDIDKey")),
-		ReceiverDID:        stringPtr(getNested(transInfo, "TIReceiverDIDKey")),
-		TxnType:            stringPtr(getNested(blockMap, "TCTransTypeKey")),
-		TxnID:              stringPtr(getNested(transInfo, "TITIDKey")),
-		Amount:             amount,
-		Epoch:              epoch,
+		BlockHash: block.BlockHash,
+
+		SenderDID:          optionalString(block.TransInfo.SenderDID),
+		ReceiverDID:        optionalString(block.TransInfo.ReceiverDID),
+		TxnType:            optionalString(block.TransType),
+		TxnID:              optionalString(block.TransInfo.TID),
+		Amount:             block.TokenValue,
+		BlockTime:          blockTime(block),
 		Tokens:             datatypes.JSON(tokensJSON),
 		ValidatorPledgeMap: datatypes.JSON(pledgeMapJSON),
 	}
+	if _, info, ok := block.FirstToken(); ok {
+		tb.PrevBlockID = optionalString(info.PreviousBlockID)
+	}
 
-	if err := database.DB.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&tb).Error; err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+	if err := r.Blocks.SaveTransferBlock(&tb); err != nil {
 		log.Printf("❌ Failed to store transfer block %v: %v", tb.BlockHash, err)
+		return err
 	}
 
 	log.Println("Transfer block stored")
+	return nil
 }
 
-// StoreBurntBlock handles inserting a single burnt-type block into DB
-func StoreBurntBlock(blockMap map[string]interface{}) {
-	transInfo, _ := blockMap["TCTransInfoKey"].(map[string]interface{})
-	tokensKey, _ := transInfo["TITokensKey"].(map[string]interface{})
-
-	tokensJSON, _ := json.Marshal(tokensKey)
-	childTokensJSON, _ := json.Marshal(blockMap["TCChildTokensKey"])
-
-	// Extract epoch from comment (e.g. "Token burnt at : 2025-10-09 15:31:14")
-	var epoch *int64
-	if comment, ok := transInfo["TICommentKey"].(string); ok {
-		re := regexp.MustCompile(`\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`)
-		if match := re.FindString(comment); match != "" {
-			// Load IST timezone (UTC+5:30)
-			ist, err := time.LoadLocation("Asia/Kolkata")
-			if err != nil {
-				log.Printf("⚠️ Failed to load IST timezone: %v", err)
-				ist = time.FixedZone("IST", 5*3600+30*60)
-			}
+// Transaction types stored in BurntBlocks.TxnType
+const (
+	BurntTxnTypeBurnt      = "Burnt"
+	BurntTxnTypeBurntForFT = "Burnt for FT"
+)
 
-			if t, err := time.ParseInLocation("2006-01-02 15:04:05", match, ist); err == nil {
-				val := t.Unix()
-				epoch = &val
-			}
-		}
-	}
+// StoreBurntBlock handles inserting a single burnt-type block into DB
+func StoreBurntBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	tokensJSON, _ := json.Marshal(block.TransInfo.Tokens)
+	childTokensJSON, _ := json.Marshal(block.ChildTokens)
 
 	// Normalize transaction type
-	txnType := fmt.Sprintf("%v", blockMap["TCTransTypeKey"])
 	var txnTypeStr string
-	switch txnType {
-	case "13":
-		txnTypeStr = "Burnt for FT"
-	case "08":
-		txnTypeStr = "Burnt"
+	switch block.TransType {
+	case util.TransTypeBurntForFT:
+		txnTypeStr = BurntTxnTypeBurntForFT
+	case util.TransTypeBurnt:
+		txnTypeStr = BurntTxnTypeBurnt
 	default:
 		txnTypeStr = "Unknown"
 	}
 
 	bb := models.BurntBlocks{
-		BlockHash: fmt.Sprintf("%v", blockMap["TCBlockHashKey"]),
+		BlockHash: block.BlockHash,
 
 		ChildTokens: datatypes.JSON(childTokensJSON),
 		TxnType:     &txnTypeStr,
-		OwnerDID:    fmt.Sprintf("%v", blockMap["TCTokenOwnerKey"]),
-		Epoch:       epoch,
+		OwnerDID:    block.TokenOwner,
+		BlockTime:   blockTime(block),
 		Tokens:      datatypes.JSON(tokensJSON),
 	}
 
-	if err := database.DB.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&bb).Error; err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+	if err := r.Blocks.SaveBurntBlock(&bb); err != nil {
 		log.Printf("❌ Failed to store burnt block %v: %v", bb.BlockHash, err)
+		return err
 	}
 
 	log.Println("✅ Burnt block stored:", bb.BlockHash)
+	return nil
 }
 
 // StoreSCDeployBlock handles inserting a smart contract deploy block into DB
-func StoreSCDeployBlock(blockMap map[string]interface{}) {
-	transInfo, _ := blockMap["TCTransInfoKey"].(map[string]interface{})
-	tokensKey, _ := transInfo["TITokensKey"].(map[string]interface{})
-
-	blockID := fmt.Sprintf("%v", blockMap["TCBlockHashKey"])
-
-	var contractID string
-	var blockHeight int64
-	for k, v := range tokensKey {
-		contractID = k
-		if tk, ok := v.(map[string]interface{}); ok {
-			if bh, ok := tk["TTBlockNumberKey"].(string); ok {
-				if vbh, err := strconv.ParseInt(bh, 10, 64); err == nil {
-					blockHeight = vbh
-				}
-			}
-		}
-		break
-	}
-
-	var epoch time.Time
-	if e, ok := blockMap["TCEpoch"].(float64); ok {
-		epoch = time.Unix(int64(e), 0)
-	}
-
-	ownerDID := fmt.Sprintf("%v", getNested(transInfo, "TIDeployerDIDKey"))
+func StoreSCDeployBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	contractID, info, _ := block.FirstToken()
 
 	scBlock := models.SC_Block{
-		Block_ID:     blockID,
+		Block_ID:     block.BlockHash,
 		Contract_ID:  contractID,
-		Block_Height: blockHeight,
-		Epoch:        epoch,
-		Owner_DID:    ownerDID,
+		Block_Height: info.Height(),
+		BlockTime:    blockTime(block),
+		Owner_DID:    block.TransInfo.DeployerDID,
 	}
 
-	if err := database.DB.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "block_id"}},
-		UpdateAll: true,
-	}).Create(&scBlock).Error; err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+	if err := r.SmartContracts.SaveSCBlock(&scBlock); err != nil {
 		log.Printf("❌ Failed to store SC deploy block %v: %v", scBlock.Contract_ID, err)
-		return
+		return err
 	}
 
 	log.Println("SC Deploy block stored:", scBlock.Block_ID)
+	return nil
 }
 
 // StoreSCExecuteBlock handles inserting a smart contract execute block into DB
-func StoreSCExecuteBlock(blockMap map[string]interface{}) {
-	transInfo, _ := blockMap["TCTransInfoKey"].(map[string]interface{})
-	tokensKey, _ := transInfo["TITokensKey"].(map[string]interface{})
-
-	blockID := fmt.Sprintf("%v", blockMap["TCBlockHashKey"])
-
-	var contractID string
-	var blockHeight int64
-	for k, v := range tokensKey {
-		contractID = k
-		if tk, ok := v.(map[string]interface{}); ok {
-			if bh, ok := tk["TTBlockNumberKey"].(string); ok {
-				if vbh, err := strconv.ParseInt(bh, 10, 64); err == nil {
-					blockHeight = vbh
-				}
-			}
-		}
-		break
-	}
-
-	var epoch time.Time
-	if e, ok := blockMap["TCEpoch"].(float64); ok {
-		epoch = time.Unix(int64(e), 0)
-	}
-
-	execDidStr := getNested(transInfo, "TIExecutorDIDKey")
-	execDidPtr := stringPtr(execDidStr)
+func StoreSCExecuteBlock(r repository.Repositories, block *util.TokenChainBlock) error {
+	contractID, info, _ := block.FirstToken()
 
 	scBlock := models.SC_Block{
-		Block_ID:     blockID,
+		Block_ID:     block.BlockHash,
 		Contract_ID:  contractID,
-		Executor_DID: execDidPtr,
-		Block_Height: blockHeight,
-		Epoch:        epoch,
+		Executor_DID: optionalString(block.TransInfo.ExecutorDID),
+		Block_Height: info.Height(),
+		BlockTime:    blockTime(block),
 	}
 
-	if err := database.DB.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "block_id"}},
-		UpdateAll: true,
-	}).Create(&scBlock).Error; err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+	if err := r.SmartContracts.SaveSCBlock(&scBlock); err != nil {
 		log.Printf("❌ Failed to store SC execute block %v: %v", scBlock.Contract_ID, err)
-		return
+		return err
 	}
 
 	log.Println("SC Execute block stored:", scBlock.Block_ID)
+	return nil
 }
 
-// StoreBlockInAllBlocks inserts a block entry into the AllBlocks table
-func StoreBlockInAllBlocks(blockMap map[string]interface{}) {
-	transInfo, _ := blockMap["TCTransInfoKey"].(map[string]interface{})
-
-	blockHash := fmt.Sprintf("%v", blockMap["TCBlockHashKey"])
-	txnID := fmt.Sprintf("%v", transInfo["TITIDKey"])
-
-	var blockType string
-	switch fmt.Sprintf("%v", blockMap["TCTransTypeKey"]) {
-	case "02", "2":
-		blockType = "transfer"
-	case "08":
-		blockType = "burnt"
-	case "13":
-		blockType = "burnt_for_ft"
-	case "09", "9":
-		blockType = "deploy"
-	case "10":
-		blockType = "execute"
-	case "05", "5":
-		blockType = "mint"
+// allBlocksType maps a transaction type to the AllBlocks block_type
+func allBlocksType(transType string) string {
+	switch transType {
+	case util.TransTypeTransfer:
+		return "transfer"
+	case util.TransTypeBurnt:
+		return "burnt"
+	case util.TransTypeBurntForFT:
+		return "burnt_for_ft"
+	case util.TransTypeDeployed:
+		return "deploy"
+	case util.TransTypeExecuted:
+		return "execute"
+	case util.TransTypeGenerated:
+		return "mint"
+	case util.TransTypeMint:
+		return "minted"
+	case util.TransTypeMigrated:
+		return "migrated"
+	case util.TransTypePledged:
+		return "pledged"
+	case util.TransTypeUnpledged:
+		return "unpledged"
+	case util.TransTypeCommitted:
+		return "committed"
+	case util.TransTypeContractCommitted:
+		return "contract_committed"
+	case util.TransTypePinned:
+		return "pinned"
 	default:
-		blockType = "unknown"
+		return "unknown"
 	}
+}
 
-	var epochTime time.Time
-	switch v := blockMap["TCEpoch"].(type) {
-	case string:
-		t, err := time.Parse(time.RFC3339, v)
-		if err == nil {
-			epochTime = t
-		} else {
-			epochTime = time.Now()
-		}
-	case float64:
-		epochTime = time.Unix(int64(v), 0)
-	case int64:
-		epochTime = time.Unix(v, 0)
-	default:
-		epochTime = time.Now()
-	}
+// StoreBlockInAllBlocks inserts a block entry into the AllBlocks table.
+// sourceNode records where the block came from: a fullnode URL or BlockSourcePush.
+func StoreBlockInAllBlocks(r repository.Repositories, block *util.TokenChainBlock, sourceNode string) error {
+	blockType := allBlocksType(block.TransType)
 
 	record := models.AllBlocks{
-		BlockHash: blockHash,
-		BlockType: blockType,
-		Epoch:     epochTime,
-		TxnID:     txnID,
+		BlockHash:  block.BlockHash,
+		BlockType:  blockType,
+		BlockTime:  blockTime(block),
+		TxnID:      block.TransInfo.TID,
+		SourceNode: sourceNode,
 	}
 
-	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
-		log.Printf("❌ Failed to insert block into AllBlocks (%v): %v", blockHash, err)
-		return
+	if err := r.Blocks.IndexBlock(&record); err != nil {
+		log.Printf("❌ Failed to insert block into AllBlocks (%v): %v", block.BlockHash, err)
+		return err
 	}
 
-	log.Printf("Stored block in AllBlocks: %v (type=%s)", blockHash, blockType)
+	log.Printf("Stored block in AllBlocks: %v (type=%s)", block.BlockHash, blockType)
+	return nil
 }
 
-// Safe string pointer
-func stringPtr(v interface{}) *string {
-	if v == nil {
+// blockTypeStore returns the function that stores a block in the table of its transaction
+// type, or nil when the type has no table of its own
+func blockTypeStore(transType string) func(repository.Repositories, *util.TokenChainBlock) error {
+	switch transType {
+	case util.TransTypeDeployed:
+		return StoreSCDeployBlock
+	case util.TransTypeExecuted:
+		return StoreSCExecuteBlock
+	case util.TransTypeContractCommitted, util.TransTypeCommitted:
+		return StoreCommitBlock
+	case util.TransTypeTransfer:
+		return StoreTransferBlock
+	case util.TransTypeBurnt, util.TransTypeBurntForFT:
+		return StoreBurntBlock
+	case util.TransTypeMint, util.TransTypeMigrated, util.TransTypeGenerated:
+		return StoreMintBlock
+	case util.TransTypePledged, util.TransTypeUnpledged:
+		return StorePledgeBlock
+	case util.TransTypePinned:
+		return StorePinBlock
+	default:
 		return nil
 	}
-	str := fmt.Sprintf("%v", v)
-	return &str
 }
 
-// Safe float64 pointer
-func float64Ptr(v interface{}) *float64 {
-	if v == nil {
-		return nil
-	}
-	switch val := v.(type) {
-	case float64:
-		return &val
-	case int:
-		f := float64(val)
-		return &f
-	case string:
-		var f float64
-		fmt.Sscanf(val, "%f", &f)
-		return &f
-	default:
-		return nil
+// isSCBlockType reports whether a transaction type belongs to a smart-contract chain
+func isSCBlockType(transType string) bool {
+	switch transType {
+	case util.TransTypeDeployed, util.TransTypeExecuted, util.TransTypeContractCommitted:
+		return true
 	}
+	return false
 }
 
-// Safe int64 pointer
-func int64Ptr(v interface{}) *int64 {
-	if v == nil {
+// storeTokenChainBlock stores a block in AllBlocks, its derived rows and the table of its
+// transaction type in one transaction, then records its verification.
+// onlySC skips blocks that are not smart-contract blocks (used when syncing SC chains).
+func storeTokenChainBlock(block *util.TokenChainBlock, sourceNode string, onlySC bool) error {
+	if onlySC && !isSCBlockType(block.TransType) {
+		log.Printf("⚠️ Ignoring non-SC block type %s (%s)", block.TransType, block.BlockHash)
 		return nil
 	}
-	switch val := v.(type) {
-	case float64:
-		i := int64(val)
-		return &i
-	case int64:
-		return &val
-	case string:
-		var i int64
-		fmt.Sscanf(val, "%d", &i)
-		return &i
-	default:
-		return nil
+
+	storeTyped := blockTypeStore(block.TransType)
+	if storeTyped == nil {
+		log.Printf("⚠️ Unknown block type %s (%s)", block.TransType, block.BlockHash)
 	}
-}
 
-// getNested safely fetches a nested map key
-func getNested(m map[string]interface{}, key string) interface{} {
-	if m == nil {
-		return nil
+	err := repos.Transaction(func(tx repository.Repositories) error {
+		if err := StoreBlockInAllBlocks(tx, block, sourceNode); err != nil {
+			return err
+		}
+		if err := storeQuorumPledges(tx, block); err != nil {
+			return err
+		}
+		if err := storeTokenLineage(tx, block); err != nil {
+			return err
+		}
+		if err := storeOwnershipEvents(tx, block); err != nil {
+			return err
+		}
+		if err := storeLedgerEntries(tx, block); err != nil {
+			return err
+		}
+		if storeTyped == nil {
+			return nil
+		}
+		return storeTyped(tx, block)
+	})
+	if err != nil {
+		return err
 	}
-	return m[key]
+
+	verifyAndStoreBlock(block, sourceNode)
+	return nil
 }
 
-func sliceStringPtr(v interface{}) *[]string {
-	if v == nil {
+// optionalString returns nil for empty strings so missing fields are stored as NULL
+func optionalString(s string) *string {
+	if s == "" {
 		return nil
 	}
-	arr, ok := v.([]interface{})
-	if !ok {
-		return nil
-	}
-	strs := make([]string, 0, len(arr))
-	for _, val := range arr {
-		if s, ok := val.(string); ok {
-			strs = append(strs, s)
-		}
-	}
-	return &strs
+	return &s
 }
 
-// FetchAllTokenChainFromFullNode syncs token chains sequentially
-func FetchAllTokenChainFromFullNode() error {
-	var tokens []models.TokenType
+// ErrSyncRunning is returned when a token-chain sync is started while one is running
+var ErrSyncRunning = errors.New("token-chain sync already running")
 
-	if err := database.DB.Find(&tokens).Error; err != nil {
-		log.Fatalf("❌ Failed to fetch tokens from DB: %v", err)
+// FetchAllTokenChainFromFullNode syncs token chains in parallel on the sync worker pool.
+// Each token keeps a persisted checkpoint, so tokens whose chain did not move
+// are skipped and an interrupted run resumes where it stopped.
+func FetchAllTokenChainFromFullNode() error {
+	tokens, err := repos.Tokens.ListTokenTypes()
+	if err != nil {
+		log.Printf("❌ Failed to fetch tokens from DB: %v", err)
 		return err
 	}
 
@@ -882,199 +737,249 @@ func FetchAllTokenChainFromFullNode() error {
 		return nil
 	}
 
-	log.Printf("ℹ️ Starting sync for %d tokens", len(tokens))
+	checkpoints, err := loadSyncCheckpoints()
+	if err != nil {
+		log.Printf("❌ Failed to load sync checkpoints: %v", err)
+		return err
+	}
 
-	successCount := 0
-	failureCount := 0
-	var errs []error
+	concurrency := tokenChainSyncConcurrency()
+	if !chainSyncProgress.start(len(tokens), concurrency) {
+		return ErrSyncRunning
+	}
+	defer chainSyncProgress.stop()
 
-	for idx, token := range tokens {
-		fmt.Printf("🔄 Syncing token %d/%d: ID=%s, Type=%s\n",
-			idx+1, len(tokens), token.TokenID, token.TokenType)
+	log.Printf("ℹ️ Starting sync for %d tokens (%d checkpoints, concurrency %d)",
+		len(tokens), len(checkpoints), concurrency)
 
-		if err := fetchAndStoreTokenChain(token); err != nil {
-			errs = append(errs, err)
-			failureCount++
-		} else {
-			successCount++
+	// The coordinator may itself run on a sync worker, so make sure
+	// enough workers exist to drain the fan-out.
+	if syncPool == nil {
+		InitWorkerPools(0)
+	}
+	syncPool.ensureWorkers(concurrency + 1)
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, token := range tokens {
+		var cp *models.TokenSyncCheckpoint
+		if existing, ok := checkpoints[token.TokenID]; ok {
+			cp = &existing
 		}
 
-		if (idx+1)%1000 == 0 {
-			log.Printf("📈 Progress: %d/%d tokens processed (Success: %d, Failures: %d)",
-				idx+1, len(tokens), successCount, failureCount)
+		sem <- struct{}{}
+		wg.Add(1)
+
+		token := token
+		job := func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			syncTokenChain(token, cp)
 		}
 
-		time.Sleep(5 * time.Millisecond)
+		if !EnqueueBackgroundSyncTask(job) {
+			// sync queue full → run on the coordinator
+			job()
+		}
 	}
 
-	if len(errs) > 0 {
-		log.Printf("⚠️ Completed with %d errors out of %d tokens", len(errs), len(tokens))
+	wg.Wait()
+
+	p := chainSyncProgress.snapshot()
+	if p.Failed > 0 {
+		log.Printf("⚠️ Completed with %d errors out of %d tokens", p.Failed, p.Total)
 	}
 
-	log.Printf("✅ Finished fetching all token chains and storing block data (Success: %d, Failures: %d)",
-		successCount, failureCount)
+	log.Printf("✅ Finished fetching all token chains and storing block data (Success: %d, Failures: %d, Unchanged: %d)",
+		p.Done, p.Failed, p.Unchanged)
 
 	return nil
 }
 
-// fetchAndStoreTokenChain handles individual token chain syncing with retry logic
-func fetchAndStoreTokenChain(token models.TokenType) error {
-	fmt.Println("tokenInfo for getting tokenchain is:", token)
-	apiURL := fmt.Sprintf("%s/api/de-exp/get-token-chain?tokenID=%s&tokenType=%s",
-		config.RubixNodeURL, token.TokenID, token.TokenType)
-
-	maxRetries := 3
-	var resp *http.Response
-	var err error
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		release := acquireNodeSlot()
-		client := GetNodeHTTPClient()
-
-		resp, err = client.Get(apiURL)
-		release()
+// tokenChainSyncConcurrency bounds parallel chain fetches by the sync pool size,
+// leaving half of the fullnode request slots free for live API traffic.
+func tokenChainSyncConcurrency() int {
+	n := maxNodeConcurrentRequests / 2
+	if syncPool != nil && syncPool.maxWorkers-1 < n {
+		n = syncPool.maxWorkers - 1
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
 
-		fmt.Print("testing-02:", resp)
+// syncTokenChain syncs one token if its chain moved and records progress
+func syncTokenChain(token models.TokenType, cp *models.TokenSyncCheckpoint) {
+	chainSyncProgress.begin(token.TokenID)
 
-		if err == nil && resp != nil && resp.StatusCode == http.StatusOK {
-			fmt.Printf("✅ Successfully fetched chain for %s on attempt %d\n",
-				token.TokenID, attempt+1)
-			break
-		}
+	if !needsChainSync(token, cp) {
+		chainSyncProgress.finish(false, nil)
+		return
+	}
 
-		if err != nil {
-			log.Printf("❌ Attempt %d: Error fetching chain for %s: %v",
-				attempt+1, token.TokenID, err)
-		} else if resp != nil {
-			log.Printf("❌ Attempt %d: Bad status %d for token %s",
-				attempt+1, resp.StatusCode, token.TokenID)
-			io.Copy(io.Discard, resp.Body)
-			resp.Body.Close()
-		}
+	err := verifyBeforeSync(token)
+	if err == nil {
+		err = fetchAndStoreTokenChain(token, cp)
+	}
+	if err != nil {
+		log.Printf("❌ Token chain sync failed for %s: %v", token.TokenID, err)
+		markSyncCheckpointFailed(token, cp, err)
+		RecordFailedTokenSync(token, FailedSyncSourceSync, err)
+	} else {
+		resolveFailedTokenSync(token.TokenID)
+	}
+	chainSyncProgress.finish(true, err)
+}
 
-		if attempt < maxRetries-1 {
-			backoff := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
-			time.Sleep(backoff)
-		}
+// verifyBeforeSync cross-checks a chain across fullnodes when SYNC_VERIFY_NODES is enabled,
+// so diverging chains never reach the block tables.
+func verifyBeforeSync(token models.TokenType) error {
+	if !config.VerifyAcrossNodes() {
+		return nil
 	}
 
+	divergences, err := VerifyTokenChainAcrossNodes(token)
+	if errors.Is(err, ErrNotEnoughNodes) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	if resp == nil {
-		return fmt.Errorf("nil response received from node for token %s", token.TokenID)
+	if len(divergences) > 0 {
+		return fmt.Errorf("chain diverges across fullnodes (%d findings)", len(divergences))
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		io.Copy(io.Discard, resp.Body)
-		return fmt.Errorf("bad status code: %d", resp.StatusCode)
-	}
+// errCheckpointNotInChain means the checkpointed block was not found in the fetched chain
+var errCheckpointNotInChain = errors.New("checkpointed block not found in chain")
+
+// fetchAndStoreTokenChain handles individual token chain syncing with retry logic.
+// Only blocks after the checkpointed block are stored; the checkpoint is advanced on success.
+// If the checkpointed block disappeared from the chain, the whole chain is stored again.
+func fetchAndStoreTokenChain(token models.TokenType, cp *models.TokenSyncCheckpoint) error {
+	err := streamAndStoreTokenChain(token, cp)
+	if errors.Is(err, errCheckpointNotInChain) {
+		log.Printf("⚠️ Checkpoint %s not found in chain of %s — resyncing full chain",
+			cp.LastBlockHash, token.TokenID)
+		clearStoredChain(token.TokenID)
+		return streamAndStoreTokenChain(token, nil)
+	}
+	return err
+}
 
-	body, err := io.ReadAll(resp.Body)
+// streamAndStoreTokenChain reads the chain block by block and stores the blocks after cp
+func streamAndStoreTokenChain(token models.TokenType, cp *models.TokenSyncCheckpoint) error {
+	resp, nodeURL, release, err := openTokenChain(token, 3)
 	if err != nil {
-		log.Printf("❌ Error reading response for %s: %v", token.TokenID, err)
 		return err
 	}
+	defer release()
+	defer resp.Body.Close()
+
+	// Until the checkpointed block is seen, blocks are already stored and only skipped
+	pastCheckpoint := cp == nil || cp.LastBlockHash == ""
+	stored := 0
+	var index int64
+
+	next := models.TokenSyncCheckpoint{
+		TokenID:        token.TokenID,
+		TokenType:      token.TokenType,
+		LastSourceNode: nodeURL,
+		Status:         CheckpointSynced,
+	}
+
+	stream, err := decodeTokenChainStream(resp.Body, func(block map[string]interface{}) error {
+		hash := chainBlockHash(block)
+		height := chainBlockHeight(block, token.TokenID)
+		if height == 0 {
+			height = index
+		}
+		index++
 
-	var chainData map[string]interface{}
-	if err := json.Unmarshal(body, &chainData); err != nil {
-		log.Printf("❌ Error decoding JSON for %s: %v", token.TokenID, err)
+		// every block goes to the local chain store, so read APIs can serve the chain
+		if err := storeChainBlock(token.TokenID, height, block); err != nil {
+			return err
+		}
+
+		if pastCheckpoint {
+			if err := processAndStoreBlocks(token, []interface{}{block}, nodeURL); err != nil {
+				return err
+			}
+			stored++
+		} else if hash == cp.LastBlockHash {
+			pastCheckpoint = true
+		}
+
+		next.LastBlockHash = hash
+		next.LastBlockHeight = height
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Error decoding chain for %s: %v", token.TokenID, err)
 		return err
 	}
 
-	if status, ok := chainData["status"].(bool); ok && !status {
+	if stream.Failed() {
 		log.Printf("❌ API returned error for token %s: %v",
-			token.TokenID, chainData["message"])
-		return nil
+			token.TokenID, stream.Fields["message"])
+		return fmt.Errorf("fullnode refused the chain of %s: %v", token.TokenID, stream.Fields["message"])
 	}
 
-	var blocks []interface{}
-	if b, ok := chainData["TokenChainData"].([]interface{}); ok {
-		blocks = b
-	} else if b, ok := chainData["blocks"].([]interface{}); ok {
-		blocks = b
-	} else {
-		log.Printf("⚠️ No block array found for token %s (keys: %v)",
-			token.TokenID, reflect.ValueOf(chainData).MapKeys())
-		return nil
+	if stream.BlocksKey == "" {
+		log.Printf("❌ No block array found for token %s (keys: %v)",
+			token.TokenID, stream.FieldKeys())
+		return fmt.Errorf("fullnode returned no block array for %s", token.TokenID)
 	}
 
-	if blocks == nil || len(blocks) == 0 {
+	if stream.BlockCount == 0 {
 		log.Printf("⚠️ Empty or nil block list for token %s", token.TokenID)
+		saveSyncCheckpoint(models.TokenSyncCheckpoint{
+			TokenID:        token.TokenID,
+			TokenType:      token.TokenType,
+			LastSyncedAt:   time.Now(),
+			LastSourceNode: nodeURL,
+			Status:         CheckpointEmpty,
+		})
 		return nil
 	}
 
-	fmt.Printf("tested %d blocks for token %s\n", len(blocks), token.TokenID)
-
-	if err := processAndStoreBlocks(token, blocks); err != nil {
-		return err
+	if !pastCheckpoint {
+		return errCheckpointNotInChain
 	}
 
+	log.Printf("✅ Token %s: %d blocks in chain, %d new since checkpoint",
+		token.TokenID, stream.BlockCount, stored)
+
+	next.LastSyncedAt = time.Now()
+	saveSyncCheckpoint(next)
+
 	return nil
 }
 
-// processAndStoreBlocks handles block classification and storage
-func processAndStoreBlocks(token models.TokenType, blocks []interface{}) error {
+// processAndStoreBlocks handles block classification and storage.
+// sourceNode is the fullnode upstream the blocks were fetched from.
+func processAndStoreBlocks(token models.TokenType, blocks []interface{}, sourceNode string) error {
 	for _, blk := range blocks {
 		blockMap, ok := blk.(map[string]interface{})
 		if !ok {
 			continue
 		}
 
-		StoreBlockInAllBlocks(blockMap)
-
-		transType, _ := blockMap["TCTransTypeKey"].(string)
-
-		if token.TokenType == "SC" {
-			switch transType {
-			case "09", "9":
-				StoreSCDeployBlock(blockMap)
-			case "10":
-				StoreSCExecuteBlock(blockMap)
-			default:
-				log.Printf("⚠️ Ignoring non-SC block type %s for token %s", transType, token.TokenID)
-			}
-			continue
+		block, err := util.ParseTokenChainBlock(blockMap)
+		if err != nil {
+			log.Printf("❌ Rejecting block of token %s: %v", token.TokenID, err)
+			return err
 		}
 
-		switch transType {
-		case "02", "2":
-			StoreTransferBlock(blockMap)
-		case "08", "13":
-			StoreBurntBlock(blockMap)
-		default:
-			log.Printf("⚠️ Unknown block type %s for token %s", transType, token.TokenID)
+		if err := storeTokenChainBlock(block, sourceNode, token.TokenType == SCType); err != nil {
+			return err
 		}
-
-		time.Sleep(2 * time.Millisecond)
 	}
 
 	return nil
 }
-
-// ProcessSingleBlock is used by the live /api/block-update path.
-// It stores the block in AllBlocks and then routes it to the right table.
-func ProcessSingleBlock(blockMap map[string]interface{}) {
-	if blockMap == nil {
-		return
-	}
-
-	// Always store in AllBlocks table
-	StoreBlockInAllBlocks(blockMap)
-
-	transType, _ := blockMap["TCTransTypeKey"].(string)
-
-	switch transType {
-	case "02", "2":
-		StoreTransferBlock(blockMap)
-	case "08", "13":
-		StoreBurntBlock(blockMap)
-	case "09", "9":
-		StoreSCDeployBlock(blockMap)
-	case "10":
-		StoreSCExecuteBlock(blockMap)
-	default:
-		log.Printf("⚠️ Unknown block type in live update: %s", transType)
-	}
-}
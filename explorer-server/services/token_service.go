@@ -3,42 +3,40 @@ package services
 import (
 	"encoding/json"
 	"errors"
-	"explorer-server/database"
 	"explorer-server/database/models"
 	"explorer-server/model"
+	"explorer-server/repository"
 	"fmt"
 	"log"
-	"math"
 	"time"
-
-	"gorm.io/gorm"
 )
 
 // UpdateTokens routes token updates to the appropriate handler
-func UpdateTokens(tableName string, tokenData interface{}, operation string) {
+func UpdateTokens(tableName string, tokenData interface{}, operation string) error {
 	switch tableName {
 	case "FullnodeRBTtable":
 		log.Printf("Processing RBT token %s", operation)
-		UpdateRBTToken(tokenData, operation)
+		return UpdateRBTToken(tokenData, operation)
 
 	case "FullnodeFTtable":
 		log.Printf("Processing FT token %s", operation)
-		UpdateFTToken(tokenData, operation)
+		return UpdateFTToken(tokenData, operation)
 
 	case "FullnodeNFTtable":
 		log.Printf("Processing NFT token %s", operation)
-		UpdateNFTToken(tokenData, operation)
+		return UpdateNFTToken(tokenData, operation)
 
 	case "FullnodeSCtable":
 		log.Printf("Processing SmartContract %s", operation)
-		UpdateSCToken(tokenData, operation)
+		return UpdateSCToken(tokenData, operation)
 
-	// case "FullnodeFailedToSyncTokens":
-	// 	log.Printf("Processing Failed Token %s", operation)
-	// 	UpdateFailedTokens(tokenData, operation)
+	case "FullnodeFailedToSyncTokens":
+		log.Printf("Processing Failed Token %s", operation)
+		return UpdateFailedTokens(tokenData, operation)
 
 	default:
 		log.Printf("⚠️ Unknown token table: %s\n", tableName)
+		return nil
 	}
 }
 
@@ -71,23 +69,22 @@ func UpdateRBTToken(tokenData interface{}, operation string) error {
 		TokenStatus: rbt.TokenStatus,
 	}
 
-	var existingRBT models.RBT
-	result := database.DB.Where("rbt_id = ?", rbt.TokenID).First(&existingRBT)
+	previous, lookupErr := repos.Tokens.GetRBT(rbt.TokenID)
 
-	isNewToken := errors.Is(result.Error, gorm.ErrRecordNotFound)
+	isNewToken := errors.Is(lookupErr, repository.ErrNotFound)
 
 	// Upsert RBT token
 	if isNewToken {
-		if err := database.DB.Create(&updateData).Error; err != nil {
+		if err := repos.Tokens.CreateRBT(&updateData); err != nil {
 			log.Printf("❌ Failed to create RBT %s: %v", rbt.TokenID, err)
 			return err
 		}
 		log.Printf("✅ RBT token created: %s", rbt.TokenID)
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying RBT %s: %v", rbt.TokenID, result.Error)
-		return result.Error
+	} else if lookupErr != nil {
+		log.Printf("❌ Error querying RBT %s: %v", rbt.TokenID, lookupErr)
+		return lookupErr
 	} else {
-		if err := database.DB.Where("rbt_id = ?", rbt.TokenID).Updates(updateData).Error; err != nil {
+		if err := repos.Tokens.SaveRBT(&updateData); err != nil {
 			log.Printf("❌ Failed to update RBT %s: %v", rbt.TokenID, err)
 			return err
 		}
@@ -100,15 +97,17 @@ func UpdateRBTToken(tokenData interface{}, operation string) error {
 		TokenType:   "RBT",
 		LastUpdated: time.Now(),
 	}
-	if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: rbt.TokenID}).Error; err != nil {
+	if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 		log.Printf("⚠️ Failed to ensure token_type for %s: %v", rbt.TokenID, err)
 	}
 
-	// Update DID table if token is free (TokenStatus == 0)
-	if rbt.TokenStatus == 0 {
-		if err := updateDIDForRBT(rbt.OwnerDID, rbt.TokenValue, isNewToken); err != nil {
-			log.Printf("⚠️ Failed to update DID %s: %v", rbt.OwnerDID, err)
-		}
+	// Rederive the totals of the owner, and of the previous owner after a transfer
+	var previousOwner string
+	if previous != nil {
+		previousOwner = previous.OwnerDID
+	}
+	if err := recomputeDIDTotals(rbt.OwnerDID, previousOwner); err != nil {
+		log.Printf("⚠️ Failed to update DID %s: %v", rbt.OwnerDID, err)
 	}
 
 	return nil
@@ -119,30 +118,29 @@ func deleteRBTToken(tokenData interface{}) error {
 	deletePayload := tokenData.(map[string]interface{})
 	tokenID := deletePayload["token_id"].(string)
 
-	var rbt models.RBT
-	result := database.DB.Where("rbt_id = ?", tokenID).First(&rbt)
+	rbt, err := repos.Tokens.GetRBT(tokenID)
 
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repository.ErrNotFound) {
 		log.Printf("⚠️ RBT token not found for deletion: %s", tokenID)
 		return nil
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying RBT %s: %v", tokenID, result.Error)
-		return result.Error
+	} else if err != nil {
+		log.Printf("❌ Error querying RBT %s: %v", tokenID, err)
+		return err
 	}
 
 	// Delete the RBT token
-	if err := database.DB.Delete(&rbt).Error; err != nil {
+	if err := repos.Tokens.DeleteRBT(tokenID); err != nil {
 		log.Printf("❌ Failed to delete RBT %s: %v", tokenID, err)
 		return err
 	}
 
-	// Update DID table - subtract the token value
-	if err := decrementDIDForRBT(rbt.OwnerDID, rbt.TokenValue); err != nil {
-		log.Printf("⚠️ Failed to decrement DID %s: %v", rbt.OwnerDID, err)
+	// Rederive the totals of the last owner
+	if err := recomputeDIDTotals(rbt.OwnerDID); err != nil {
+		log.Printf("⚠️ Failed to update DID %s: %v", rbt.OwnerDID, err)
 	}
 
 	// Delete token_type entry
-	if err := database.DB.Where("token_id = ?", tokenID).Delete(&models.TokenType{}).Error; err != nil {
+	if err := repos.Tokens.DeleteTokenType(tokenID); err != nil {
 		log.Printf("⚠️ Failed to delete token_type for %s: %v", tokenID, err)
 	}
 
@@ -181,22 +179,21 @@ func UpdateFTToken(tokenData interface{}, operation string) error {
 		TokenStatus: ft.TokenStatus,
 	}
 
-	var existingFT models.FT
-	result := database.DB.Where("ft_id = ?", ft.TokenID).First(&existingFT)
+	previous, lookupErr := repos.Tokens.GetFT(ft.TokenID)
 
-	isNewToken := errors.Is(result.Error, gorm.ErrRecordNotFound)
+	isNewToken := errors.Is(lookupErr, repository.ErrNotFound)
 
 	if isNewToken {
-		if err := database.DB.Create(&updateData).Error; err != nil {
+		if err := repos.Tokens.CreateFT(&updateData); err != nil {
 			log.Printf("❌ Failed to create FT %s: %v", ft.TokenID, err)
 			return err
 		}
 		log.Printf("✅ FT token created: %s", ft.TokenID)
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying FT %s: %v", ft.TokenID, result.Error)
-		return result.Error
+	} else if lookupErr != nil {
+		log.Printf("❌ Error querying FT %s: %v", ft.TokenID, lookupErr)
+		return lookupErr
 	} else {
-		if err := database.DB.Where("ft_id = ?", ft.TokenID).Updates(updateData).Error; err != nil {
+		if err := repos.Tokens.SaveFT(&updateData); err != nil {
 			log.Printf("❌ Failed to update FT %s: %v", ft.TokenID, err)
 			return err
 		}
@@ -209,15 +206,17 @@ func UpdateFTToken(tokenData interface{}, operation string) error {
 		TokenType:   "FT",
 		LastUpdated: time.Now(),
 	}
-	if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: ft.TokenID}).Error; err != nil {
+	if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 		log.Printf("⚠️ Failed to ensure token_type for %s: %v", ft.TokenID, err)
 	}
 
-	// Update DID table if token is free (TokenStatus == 0)
-	if ft.TokenStatus == 0 {
-		if err := updateDIDForFT(ft.OwnerDID, isNewToken); err != nil {
-			log.Printf("⚠️ Failed to update DID %s: %v", ft.OwnerDID, err)
-		}
+	// Rederive the totals of the owner, and of the previous owner after a transfer
+	var previousOwner string
+	if previous != nil {
+		previousOwner = previous.OwnerDID
+	}
+	if err := recomputeDIDTotals(ft.OwnerDID, previousOwner); err != nil {
+		log.Printf("⚠️ Failed to update DID %s: %v", ft.OwnerDID, err)
 	}
 
 	return nil
@@ -228,30 +227,29 @@ func deleteFTToken(tokenData interface{}) error {
 	deletePayload := tokenData.(map[string]interface{})
 	tokenID := deletePayload["token_id"].(string)
 
-	var ft models.FT
-	result := database.DB.Where("ft_id = ?", tokenID).First(&ft)
+	ft, err := repos.Tokens.GetFT(tokenID)
 
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repository.ErrNotFound) {
 		log.Printf("⚠️ FT token not found for deletion: %s", tokenID)
 		return nil
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying FT %s: %v", tokenID, result.Error)
-		return result.Error
+	} else if err != nil {
+		log.Printf("❌ Error querying FT %s: %v", tokenID, err)
+		return err
 	}
 
 	// Delete the FT token
-	if err := database.DB.Delete(&ft).Error; err != nil {
+	if err := repos.Tokens.DeleteFT(tokenID); err != nil {
 		log.Printf("❌ Failed to delete FT %s: %v", tokenID, err)
 		return err
 	}
 
-	// Update DID table - decrement count
-	if err := decrementDIDForFT(ft.OwnerDID); err != nil {
-		log.Printf("⚠️ Failed to decrement DID %s: %v", ft.OwnerDID, err)
+	// Rederive the totals of the last owner
+	if err := recomputeDIDTotals(ft.OwnerDID); err != nil {
+		log.Printf("⚠️ Failed to update DID %s: %v", ft.OwnerDID, err)
 	}
 
 	// Delete token_type entry
-	if err := database.DB.Where("token_id = ?", tokenID).Delete(&models.TokenType{}).Error; err != nil {
+	if err := repos.Tokens.DeleteTokenType(tokenID); err != nil {
 		log.Printf("⚠️ Failed to delete token_type for %s: %v", tokenID, err)
 	}
 
@@ -281,7 +279,7 @@ func UpdateNFTToken(tokenData interface{}, operation string) error {
 
 	updateData := models.NFT{
 		TokenID:     nft.TokenID,
-		TokenValue:  fmt.Sprintf("%f", nft.TokenValue),
+		TokenValue:  nft.TokenValue,
 		OwnerDID:    nft.OwnerDID,
 		BlockHash:   nft.BlockHash,
 		Txn_ID:      nft.TransactionID,
@@ -289,22 +287,21 @@ func UpdateNFTToken(tokenData interface{}, operation string) error {
 		TokenStatus: nft.TokenStatus,
 	}
 
-	var existingNFT models.NFT
-	result := database.DB.Where("nft_id = ?", nft.TokenID).First(&existingNFT)
+	previous, lookupErr := repos.Tokens.GetNFT(nft.TokenID)
 
-	isNewToken := errors.Is(result.Error, gorm.ErrRecordNotFound)
+	isNewToken := errors.Is(lookupErr, repository.ErrNotFound)
 
 	if isNewToken {
-		if err := database.DB.Create(&updateData).Error; err != nil {
+		if err := repos.Tokens.CreateNFT(&updateData); err != nil {
 			log.Printf("❌ Failed to create NFT %s: %v", nft.TokenID, err)
 			return err
 		}
 		log.Printf("✅ NFT token created: %s", nft.TokenID)
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying NFT %s: %v", nft.TokenID, result.Error)
-		return result.Error
+	} else if lookupErr != nil {
+		log.Printf("❌ Error querying NFT %s: %v", nft.TokenID, lookupErr)
+		return lookupErr
 	} else {
-		if err := database.DB.Where("nft_id = ?", nft.TokenID).Updates(updateData).Error; err != nil {
+		if err := repos.Tokens.SaveNFT(&updateData); err != nil {
 			log.Printf("❌ Failed to update NFT %s: %v", nft.TokenID, err)
 			return err
 		}
@@ -317,12 +314,16 @@ func UpdateNFTToken(tokenData interface{}, operation string) error {
 		TokenType:   "NFT",
 		LastUpdated: time.Now(),
 	}
-	if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: nft.TokenID}).Error; err != nil {
+	if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 		log.Printf("⚠️ Failed to ensure token_type for %s: %v", nft.TokenID, err)
 	}
 
-	// Update DID table for all NFTs (no TokenStatus check for NFTs)
-	if err := updateDIDForNFT(nft.OwnerDID, isNewToken); err != nil {
+	// Rederive the totals of the owner, and of the previous owner after a transfer
+	var previousOwner string
+	if previous != nil {
+		previousOwner = previous.OwnerDID
+	}
+	if err := recomputeDIDTotals(nft.OwnerDID, previousOwner); err != nil {
 		log.Printf("⚠️ Failed to update DID %s: %v", nft.OwnerDID, err)
 	}
 
@@ -334,30 +335,29 @@ func deleteNFTToken(tokenData interface{}) error {
 	deletePayload := tokenData.(map[string]interface{})
 	tokenID := deletePayload["token_id"].(string)
 
-	var nft models.NFT
-	result := database.DB.Where("nft_id = ?", tokenID).First(&nft)
+	nft, err := repos.Tokens.GetNFT(tokenID)
 
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repository.ErrNotFound) {
 		log.Printf("⚠️ NFT token not found for deletion: %s", tokenID)
 		return nil
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying NFT %s: %v", tokenID, result.Error)
-		return result.Error
+	} else if err != nil {
+		log.Printf("❌ Error querying NFT %s: %v", tokenID, err)
+		return err
 	}
 
 	// Delete the NFT token
-	if err := database.DB.Delete(&nft).Error; err != nil {
+	if err := repos.Tokens.DeleteNFT(tokenID); err != nil {
 		log.Printf("❌ Failed to delete NFT %s: %v", tokenID, err)
 		return err
 	}
 
-	// Update DID table - decrement count
-	if err := decrementDIDForNFT(nft.OwnerDID); err != nil {
-		log.Printf("⚠️ Failed to decrement DID %s: %v", nft.OwnerDID, err)
+	// Rederive the totals of the last owner
+	if err := recomputeDIDTotals(nft.OwnerDID); err != nil {
+		log.Printf("⚠️ Failed to update DID %s: %v", nft.OwnerDID, err)
 	}
 
 	// Delete token_type entry
-	if err := database.DB.Where("token_id = ?", tokenID).Delete(&models.TokenType{}).Error; err != nil {
+	if err := repos.Tokens.DeleteTokenType(tokenID); err != nil {
 		log.Printf("⚠️ Failed to delete token_type for %s: %v", tokenID, err)
 	}
 
@@ -394,22 +394,21 @@ func UpdateSCToken(tokenData interface{}, operation string) error {
 		TokenStatus: sc.TokenStatus,
 	}
 
-	var existingSC models.SmartContract
-	result := database.DB.Where("contract_id = ?", sc.SmartContractHash).First(&existingSC)
+	previous, lookupErr := repos.SmartContracts.GetSmartContract(sc.SmartContractHash)
 
-	isNewToken := errors.Is(result.Error, gorm.ErrRecordNotFound)
+	isNewToken := errors.Is(lookupErr, repository.ErrNotFound)
 
 	if isNewToken {
-		if err := database.DB.Create(&updateData).Error; err != nil {
+		if err := repos.SmartContracts.CreateSmartContract(&updateData); err != nil {
 			log.Printf("❌ Failed to create SC %s: %v", sc.SmartContractHash, err)
 			return err
 		}
 		log.Printf("✅ Smart Contract created: %s", sc.SmartContractHash)
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying SC %s: %v", sc.SmartContractHash, result.Error)
-		return result.Error
+	} else if lookupErr != nil {
+		log.Printf("❌ Error querying SC %s: %v", sc.SmartContractHash, lookupErr)
+		return lookupErr
 	} else {
-		if err := database.DB.Where("contract_id = ?", sc.SmartContractHash).Updates(updateData).Error; err != nil {
+		if err := repos.SmartContracts.SaveSmartContract(&updateData); err != nil {
 			log.Printf("❌ Failed to update SC %s: %v", sc.SmartContractHash, err)
 			return err
 		}
@@ -422,12 +421,16 @@ func UpdateSCToken(tokenData interface{}, operation string) error {
 		TokenType:   "SC",
 		LastUpdated: time.Now(),
 	}
-	if err := database.DB.FirstOrCreate(&tokenType, models.TokenType{TokenID: sc.SmartContractHash}).Error; err != nil {
+	if err := repos.Tokens.EnsureTokenType(&tokenType); err != nil {
 		log.Printf("⚠️ Failed to ensure token_type for SC %s: %v", sc.SmartContractHash, err)
 	}
 
-	// Update DID table for smart contracts
-	if err := updateDIDForSC(sc.Deployer, isNewToken); err != nil {
+	// Rederive the totals of the deployer, and of the previously recorded deployer
+	var previousDeployer string
+	if previous != nil {
+		previousDeployer = previous.DeployerDID
+	}
+	if err := recomputeDIDTotals(sc.Deployer, previousDeployer); err != nil {
 		log.Printf("⚠️ Failed to update DID %s: %v", sc.Deployer, err)
 	}
 
@@ -439,30 +442,29 @@ func deleteSCToken(tokenData interface{}) error {
 	deletePayload := tokenData.(map[string]interface{})
 	contractHash := deletePayload["smart_contract_hash"].(string)
 
-	var sc models.SmartContract
-	result := database.DB.Where("contract_id = ?", contractHash).First(&sc)
+	sc, err := repos.SmartContracts.GetSmartContract(contractHash)
 
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repository.ErrNotFound) {
 		log.Printf("⚠️ Smart Contract not found for deletion: %s", contractHash)
 		return nil
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying SC %s: %v", contractHash, result.Error)
-		return result.Error
+	} else if err != nil {
+		log.Printf("❌ Error querying SC %s: %v", contractHash, err)
+		return err
 	}
 
 	// Delete the Smart Contract
-	if err := database.DB.Delete(&sc).Error; err != nil {
+	if err := repos.SmartContracts.DeleteSmartContract(contractHash); err != nil {
 		log.Printf("❌ Failed to delete SC %s: %v", contractHash, err)
 		return err
 	}
 
-	// Update DID table - decrement count
-	if err := decrementDIDForSC(sc.DeployerDID); err != nil {
-		log.Printf("⚠️ Failed to decrement DID %s: %v", sc.DeployerDID, err)
+	// Rederive the totals of the deployer
+	if err := recomputeDIDTotals(sc.DeployerDID); err != nil {
+		log.Printf("⚠️ Failed to update DID %s: %v", sc.DeployerDID, err)
 	}
 
 	// Delete token_type entry
-	if err := database.DB.Where("token_id = ?", contractHash).Delete(&models.TokenType{}).Error; err != nil {
+	if err := repos.Tokens.DeleteTokenType(contractHash); err != nil {
 		log.Printf("⚠️ Failed to delete token_type for SC %s: %v", contractHash, err)
 	}
 
@@ -491,6 +493,11 @@ func UpdateFailedTokens(tokenData interface{}, operation string) error {
 		return err
 	}
 
+	if err := recordNodeFailedToken(failedToken); err != nil {
+		log.Printf("❌ Failed to record failed token %s: %v", failedToken.TokenID, err)
+		return err
+	}
+
 	log.Printf("✅ Failed token recorded: %s", failedToken.TokenID)
 	return nil
 }
@@ -500,242 +507,21 @@ func deleteFailedToken(tokenData interface{}) error {
 	deletePayload := tokenData.(map[string]interface{})
 	tokenID := deletePayload["token_id"].(string)
 
-	var failedToken model.FailedToSyncTokenDetailsInfo
-
-	result := database.DB.Where("token_id = ?", tokenID).First(&failedToken)
-
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	_, err := repos.Sync.GetFailedSync(tokenID)
+	if errors.Is(err, repository.ErrNotFound) {
 		log.Printf("⚠️ Failed token not found for deletion: %s", tokenID)
 		return nil
-	} else if result.Error != nil {
-		log.Printf("❌ Error querying failed token %s: %v", tokenID, result.Error)
-		return result.Error
-	}
-
-	// Delete the failed token entry
-	if err := database.DB.Delete(&failedToken).Error; err != nil {
-		log.Printf("❌ Failed to delete failed token %s: %v", tokenID, err)
-		return err
-	}
-
-	log.Printf("✅ Failed token deleted: %s", tokenID)
-	return nil
-}
-
-// ========== DID Update Helpers (Increment) ==========
-
-func updateDIDForRBT(ownerDID string, tokenValue float64, isNewToken bool) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", ownerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		newDID := models.DIDs{
-			DID:       ownerDID,
-			CreatedAt: time.Now(),
-			TotalRBTs: tokenValue,
-		}
-		if err := database.DB.Create(&newDID).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Created DID entry for %s with RBT value: %f", ownerDID, tokenValue)
-	} else if err != nil {
-		return err
-	} else {
-		if isNewToken {
-			existing.TotalRBTs += tokenValue
-		}
-		existing.TotalRBTs = math.Round(existing.TotalRBTs*1000) / 1000
-		if err := database.DB.Save(&existing).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Updated DID entry for %s, new total RBTs: %f", ownerDID, existing.TotalRBTs)
-	}
-
-	return nil
-}
-
-func updateDIDForFT(ownerDID string, isNewToken bool) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", ownerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		newDID := models.DIDs{
-			DID:       ownerDID,
-			CreatedAt: time.Now(),
-			TotalFTs:  1,
-		}
-		if err := database.DB.Create(&newDID).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Created DID entry for %s with FT count: 1", ownerDID)
-	} else if err != nil {
-		return err
-	} else {
-		if isNewToken {
-			existing.TotalFTs += 1
-		}
-		if err := database.DB.Save(&existing).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Updated DID entry for %s, new total FTs: %d", ownerDID, existing.TotalFTs)
-	}
-
-	return nil
-}
-
-func updateDIDForNFT(ownerDID string, isNewToken bool) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", ownerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		newDID := models.DIDs{
-			DID:       ownerDID,
-			CreatedAt: time.Now(),
-			TotalNFTs: 1,
-		}
-		if err := database.DB.Create(&newDID).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Created DID entry for %s with NFT count: 1", ownerDID)
 	} else if err != nil {
+		log.Printf("❌ Error querying failed token %s: %v", tokenID, err)
 		return err
-	} else {
-		if isNewToken {
-			existing.TotalNFTs += 1
-		}
-		if err := database.DB.Save(&existing).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Updated DID entry for %s, new total NFTs: %d", ownerDID, existing.TotalNFTs)
 	}
 
-	return nil
-}
-
-func updateDIDForSC(deployerDID string, isNewToken bool) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", deployerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		newDID := models.DIDs{
-			DID:       deployerDID,
-			CreatedAt: time.Now(),
-			TotalSC:   1,
-		}
-		if err := database.DB.Create(&newDID).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Created DID entry for %s with SC count: 1", deployerDID)
-	} else if err != nil {
-		return err
-	} else {
-		if isNewToken {
-			existing.TotalSC += 1
-		}
-		if err := database.DB.Save(&existing).Error; err != nil {
-			return err
-		}
-		log.Printf("✅ Updated DID entry for %s, new total SCs: %d", deployerDID, existing.TotalSC)
-	}
-
-	return nil
-}
-
-// ========== DID Update Helpers (Decrement for Deletions) ==========
-
-func decrementDIDForRBT(ownerDID string, tokenValue float64) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", ownerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Printf("⚠️ DID entry not found for %s", ownerDID)
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	existing.TotalRBTs -= tokenValue
-	existing.TotalRBTs = math.Round(existing.TotalRBTs*1000) / 1000
-	if existing.TotalRBTs < 0 {
-		existing.TotalRBTs = 0
-	}
-
-	if err := database.DB.Save(&existing).Error; err != nil {
-		return err
-	}
-	log.Printf("✅ Decremented DID entry for %s, new total RBTs: %f", ownerDID, existing.TotalRBTs)
-
-	return nil
-}
-
-func decrementDIDForFT(ownerDID string) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", ownerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Printf("⚠️ DID entry not found for %s", ownerDID)
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	existing.TotalFTs -= 1
-	if existing.TotalFTs < 0 {
-		existing.TotalFTs = 0
-	}
-
-	if err := database.DB.Save(&existing).Error; err != nil {
-		return err
-	}
-	log.Printf("✅ Decremented DID entry for %s, new total FTs: %d", ownerDID, existing.TotalFTs)
-
-	return nil
-}
-
-func decrementDIDForNFT(ownerDID string) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", ownerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Printf("⚠️ DID entry not found for %s", ownerDID)
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	existing.TotalNFTs -= 1
-	if existing.TotalNFTs < 0 {
-		existing.TotalNFTs = 0
-	}
-
-	if err := database.DB.Save(&existing).Error; err != nil {
-		return err
-	}
-	log.Printf("✅ Decremented DID entry for %s, new total NFTs: %d", ownerDID, existing.TotalNFTs)
-
-	return nil
-}
-
-func decrementDIDForSC(deployerDID string) error {
-	var existing models.DIDs
-	err := database.DB.First(&existing, "did = ?", deployerDID).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Printf("⚠️ DID entry not found for %s", deployerDID)
-		return nil
-	} else if err != nil {
-		return err
-	}
-
-	existing.TotalSC -= 1
-	if existing.TotalSC < 0 {
-		existing.TotalSC = 0
-	}
-
-	if err := database.DB.Save(&existing).Error; err != nil {
+	// The fullnode resolved it; drop the dead-letter entry
+	if err := repos.Sync.DeleteFailedSync(tokenID); err != nil {
+		log.Printf("❌ Failed to delete failed token %s: %v", tokenID, err)
 		return err
 	}
-	log.Printf("✅ Decremented DID entry for %s, new total SCs: %d", deployerDID, existing.TotalSC)
 
+	log.Printf("✅ Failed token deleted: %s", tokenID)
 	return nil
 }
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"explorer-server/database/models"
+	"explorer-server/decimal"
+)
+
+func rbtPayload(tokenID, owner string, value float64, status int) map[string]interface{} {
+	return map[string]interface{}{
+		"TokenID":     tokenID,
+		"TokenValue":  value,
+		"OwnerDID":    owner,
+		"BlockHash":   "block-" + tokenID,
+		"BlockHeight": 1,
+		"TokenStatus": status,
+	}
+}
+
+func totalRBTs(t *testing.T, did string) string {
+	t.Helper()
+	info, err := GetDIDInfoFromDID(did)
+	if err != nil {
+		t.Fatalf("GetDIDInfoFromDID(%s): %v", did, err)
+	}
+	return info.TotalRBTs.String()
+}
+
+func TestUpdateRBTTokenMaintainsDIDTotals(t *testing.T) {
+	useMemoryRepos(t)
+
+	steps := []struct {
+		name      string
+		data      map[string]interface{}
+		operation string
+		want      string
+	}{
+		{"create first token", rbtPayload("rbt1", "did-a", 1.5, 0), "CREATE", "1.5"},
+		{"create second token", rbtPayload("rbt2", "did-a", 2, 0), "CREATE", "3.5"},
+		{"update does not count twice", rbtPayload("rbt2", "did-a", 2, 0), "UPDATE", "3.5"},
+		{"locked token is not counted", rbtPayload("rbt3", "did-a", 4, 1), "CREATE", "3.5"},
+		{"delete subtracts the value", map[string]interface{}{"token_id": "rbt1"}, "DELETE", "2"},
+	}
+
+	for _, step := range steps {
+		if err := UpdateTokens("FullnodeRBTtable", step.data, step.operation); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := totalRBTs(t, "did-a"); got != step.want {
+			t.Fatalf("%s: TotalRBTs = %s, want %s", step.name, got, step.want)
+		}
+	}
+
+	if tokenType, err := GetAssetType("rbt2"); err != nil || tokenType != RBTType {
+		t.Fatalf("GetAssetType(rbt2) = %q, %v", tokenType, err)
+	}
+}
+
+func TestStoreRBTInfoInDBSkipsKnownTokens(t *testing.T) {
+	useMemoryRepos(t)
+
+	list := []RBT{
+		{TokenID: "rbt1", TokenValue: decimal.FromInt(1), OwnerDID: "did-a"},
+		{TokenID: "rbt2", TokenValue: decimal.MustParse("0.25"), OwnerDID: "did-a"},
+	}
+	if err := StoreRBTInfoInDB(list); err != nil {
+		t.Fatal(err)
+	}
+	// a second initial sync over the same list must not count the tokens again
+	if err := StoreRBTInfoInDB(list); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := totalRBTs(t, "did-a"); got != "1.25" {
+		t.Fatalf("TotalRBTs = %s, want 1.25", got)
+	}
+	if count, _ := repos.Tokens.CountRBTs(); count != 2 {
+		t.Fatalf("stored %d RBTs, want 2", count)
+	}
+}
+
+func TestPartTokenTotalsAreExact(t *testing.T) {
+	useMemoryRepos(t)
+
+	for _, data := range []map[string]interface{}{
+		rbtPayload("part1", "did-a", 0.1, 0),
+		rbtPayload("part2", "did-a", 0.2, 0),
+	} {
+		if err := UpdateTokens("FullnodeRBTtable", data, "CREATE"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// float64 sums these to 0.30000000000000004
+	if got := totalRBTs(t, "did-a"); got != "0.3" {
+		t.Fatalf("TotalRBTs = %s, want 0.3", got)
+	}
+}
+
+func TestRBTTransferMovesDIDTotals(t *testing.T) {
+	useMemoryRepos(t)
+
+	for _, data := range []map[string]interface{}{
+		rbtPayload("rbt1", "did-a", 1, 0),
+		rbtPayload("rbt2", "did-a", 2, 0),
+		// the same update replayed, then rbt1 moving to did-b
+		rbtPayload("rbt2", "did-a", 2, 0),
+		rbtPayload("rbt1", "did-b", 1, 0),
+	} {
+		if err := UpdateTokens("FullnodeRBTtable", data, "UPDATE"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := totalRBTs(t, "did-a"); got != "2" {
+		t.Fatalf("did-a TotalRBTs = %s, want 2", got)
+	}
+	if got := totalRBTs(t, "did-b"); got != "1" {
+		t.Fatalf("did-b TotalRBTs = %s, want 1", got)
+	}
+}
+
+func TestAuditDIDTotalsFindsAndRepairsDrift(t *testing.T) {
+	mem := useMemoryRepos(t)
+
+	if err := StoreRBTInfoInDB([]RBT{
+		{TokenID: "rbt1", TokenValue: decimal.FromInt(1), OwnerDID: "did-a"},
+		{TokenID: "rbt2", TokenValue: decimal.MustParse("0.5"), OwnerDID: "did-b"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreNFTInfoInDB([]NFT{{TokenID: "nft1", OwnerDID: "did-a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := AuditDIDTotals(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 2 || len(report.Mismatches) != 0 {
+		t.Fatalf("clean audit = %+v, want 2 DIDs checked and no mismatches", report)
+	}
+
+	// drift: a replayed increment on did-a and a token whose owner has no row
+	driftedA, _ := mem.DIDs.GetDID("did-a")
+	driftedA.TotalRBTs, driftedA.TotalNFTs = decimal.FromInt(2), 3
+	mem.DIDs.SaveDID(driftedA)
+	mem.Tokens.CreateRBT(&models.RBT{TokenID: "rbt3", TokenValue: decimal.FromInt(4), OwnerDID: "did-c"})
+
+	report, err = AuditDIDTotals(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Mismatches) != 2 || report.Repaired {
+		t.Fatalf("audit = %+v, want 2 unrepaired mismatches", report)
+	}
+	if m := report.Mismatches[0]; m.DID != "did-a" || strings.Join(m.Fields, ",") != "total_rbts,total_nfts" {
+		t.Fatalf("first mismatch = %+v", m)
+	}
+	if m := report.Mismatches[1]; m.DID != "did-c" || m.Stored != nil || m.Derived.TotalRBTs != decimal.FromInt(4) {
+		t.Fatalf("second mismatch = %+v", m)
+	}
+
+	if report, err = AuditDIDTotals(true); err != nil || !report.Repaired {
+		t.Fatalf("repair = %+v, %v", report, err)
+	}
+	if report, _ = AuditDIDTotals(false); len(report.Mismatches) != 0 {
+		t.Fatalf("mismatches left after repair: %+v", report.Mismatches)
+	}
+	if got := totalRBTs(t, "did-c"); got != "4" {
+		t.Fatalf("did-c TotalRBTs = %s, want 4", got)
+	}
+}
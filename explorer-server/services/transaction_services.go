@@ -2,22 +2,18 @@ package services
 
 import (
 	"encoding/json"
+	"explorer-server/decimal"
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net/http"
-
-	"explorer-server/config"
-	"explorer-server/database"
-	"explorer-server/database/models"
 )
 
 // SyncMissingTxnAmounts checks TransferBlocks for missing amounts,
 // fetches from fullnode API, and updates DB.
 func SyncMissingTxnAmounts() {
 
-	var blocks []models.TransferBlocks
-	if err := database.DB.Where("amount IS NULL OR amount = 0").Find(&blocks).Error; err != nil {
+	blocks, err := repos.Blocks.ListTransferBlocksWithoutAmount()
+	if err != nil {
 		log.Printf("❌ Failed to query TransferBlocks: %v", err)
 		return
 	}
@@ -32,24 +28,26 @@ func SyncMissingTxnAmounts() {
 			continue
 		}
 
-		// url := fmt.Sprintf(apiURL, *b.TxnID)
-	     url := fmt.Sprintf("%s/api/de-exp/get-txn-amount-by-txnID?txnID=%s", config.RubixNodeURL, *b.TxnID)
+		apiPath := fmt.Sprintf("/api/de-exp/get-txn-amount-by-txnID?txnID=%s", *b.TxnID)
 
-		resp, err := http.Get(url)
+		release := acquireNodeSlot()
+		resp, nodeURL, err := nodeGet(apiPath)
+		release()
 		if err != nil {
 			log.Printf("⚠️ Failed to fetch txn amount for %s: %v", *b.TxnID, err)
 			continue
 		}
-		defer resp.Body.Close()
 
 		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
 		var result struct {
 			Status  bool   `json:"status"`
 			Message string `json:"message"`
 			Result  struct {
-				TransactionID    string  `json:"TransactionID"`
-				TransactionValue float64 `json:"TransactionValue"`
-				BlockHash        string  `json:"BlockHash"`
+				TransactionID    string          `json:"TransactionID"`
+				TransactionValue decimal.Decimal `json:"TransactionValue"`
+				BlockHash        string          `json:"BlockHash"`
 			} `json:"result"`
 		}
 
@@ -63,15 +61,13 @@ func SyncMissingTxnAmounts() {
 		}
 
 		// Update the amount in DB
-		if err := database.DB.Model(&models.TransferBlocks{}).
-			Where("txn_id = ?", *b.TxnID).
-			Update("amount", result.Result.TransactionValue).Error; err != nil {
+		if err := repos.Blocks.UpdateTransferAmount(b.BlockHash, result.Result.TransactionValue); err != nil {
 			log.Printf("❌ Failed to update amount for txn_id %s: %v", *b.TxnID, err)
 			continue
 		}
 
-		log.Printf("✅ Updated txn_id=%s with amount=%.6f (block_hash=%s)",
-			*b.TxnID, result.Result.TransactionValue, result.Result.BlockHash)
+		log.Printf("✅ Updated txn_id=%s with amount=%s (block_hash=%s, node=%s)",
+			*b.TxnID, result.Result.TransactionValue, result.Result.BlockHash, nodeURL)
 	}
 
 	log.Println("🎯 Sync completed.")
@@ -75,9 +75,14 @@ func newWorkerPool(name string, queueSize, minWorkers, maxWorkers int) *workerPo
 
 func (p *workerPool) startWorker() {
 	p.mu.Lock()
+	p.startWorkerLocked()
+	p.mu.Unlock()
+}
+
+// startWorkerLocked spawns one worker goroutine; p.mu must be held by the caller
+func (p *workerPool) startWorkerLocked() {
 	p.running++
 	id := p.running
-	p.mu.Unlock()
 
 	go func() {
 		idleTimeout := 30 * time.Second
@@ -111,11 +116,24 @@ func (p *workerPool) maybeScaleUp() {
 	qLen := len(p.queue)
 	if qLen > p.running && p.running < p.maxWorkers {
 		// simple heuristic: if queue longer than workers, spawn one more
-		p.startWorker()
+		p.startWorkerLocked()
 		log.Printf("📈 %s pool scaled up: %d workers (queue=%d)", p.name, p.running, qLen)
 	}
 }
 
+// ensureWorkers starts workers until at least n are running (capped at maxWorkers)
+func (p *workerPool) ensureWorkers(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n > p.maxWorkers {
+		n = p.maxWorkers
+	}
+	for p.running < n {
+		p.startWorkerLocked()
+	}
+}
+
 func (p *workerPool) enqueue(job func()) bool {
 	select {
 	case p.queue <- job:
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"explorer-server/services"
+	"explorer-server/test/fakenode"
+)
+
+// TestErrorsUseTheEnvelope checks that failures answer with a JSON error of the right
+// status and code instead of a plaintext 500
+func TestErrorsUseTheEnvelope(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		want   map[string]string
+	}{
+		{"/api/rbt?rbtid=QmUnknown", http.StatusNotFound, map[string]string{"error.code": "not_found"}},
+		{"/api/search?id=QmUnknown", http.StatusNotFound, map[string]string{"error.code": "not_found"}},
+		{"/api/search?id=bafyUnknown", http.StatusNotFound, map[string]string{"error.code": "not_found"}},
+		{"/api/getdidinfo?did=bafyUnknown", http.StatusNotFound, map[string]string{"error.code": "not_found"}},
+		{"/api/block?id=no-such-block", http.StatusNotFound, map[string]string{"error.code": "not_found"}},
+		{"/api/token-owners", http.StatusBadRequest, map[string]string{
+			"error.code": "bad_request", "error.details.parameter": "tokenID"}},
+		{"/api/quorum/volume?interval=fortnight", http.StatusBadRequest, map[string]string{"error.code": "bad_request"}},
+		{"/api/no-such-route", http.StatusNotFound, map[string]string{"error.code": "not_found"}},
+		{"/api/block-update", http.StatusMethodNotAllowed, map[string]string{"error.code": "method_not_allowed"}},
+	} {
+		e.expect(tc.path, tc.status, tc.want)
+	}
+}
+
+// TestUnreachableFullnodeIsUnavailable checks that a chain the fullnode cannot serve is a
+// 503, so clients can tell a backend outage from a bad request
+func TestUnreachableFullnodeIsUnavailable(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	// a token the explorer knows but whose chain it has not stored yet
+	if err := services.UpdateTokens("FullnodeRBTtable", map[string]interface{}{
+		"TokenID": "QmRBT9", "TokenValue": 1, "OwnerDID": didBob,
+	}, "CREATE"); err != nil {
+		t.Fatal(err)
+	}
+	e.node.Inject(fakenode.PathTokenChain, fakenode.Fault{Status: http.StatusBadGateway})
+
+	e.expect("/api/token-chain?token_id=QmRBT9", http.StatusServiceUnavailable, map[string]string{"error.code": "unavailable"})
+}
+
+func TestErrorCarriesTheRequestID(t *testing.T) {
+	e := newExplorer(t)
+
+	req, err := http.NewRequest(http.MethodGet, e.api.URL+"/api/rbt?rbtid=QmUnknown", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Request-ID", "req-42")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("error body is not JSON: %v", err)
+	}
+	if got := str(field(body, "error.request_id")); got != "req-42" || resp.Header.Get("X-Request-ID") != "req-42" {
+		t.Fatalf("request ID = %s (header %q), want req-42", got, resp.Header.Get("X-Request-ID"))
+	}
+
+	// without one the explorer makes one up
+	_, generated := e.get("/api/rbt?rbtid=QmUnknown")
+	if str(field(generated, "error.request_id")) == "<nil>" {
+		t.Fatalf("no request ID in %v", generated)
+	}
+}
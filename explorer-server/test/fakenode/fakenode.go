@@ -0,0 +1,372 @@
+// Package fakenode is an in-process stand-in for a Rubix fullnode. It serves the de-exp
+// endpoints the explorer syncs from, out of fixture data, and can inject latency, error
+// statuses and malformed payloads per endpoint.
+package fakenode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fullnode endpoints served by the fake
+const (
+	PathHealth     = "/"
+	PathRBTList    = "/api/de-exp/get-rbt-list"
+	PathFTList     = "/api/de-exp/get-ft-list"
+	PathNFTList    = "/api/de-exp/get-nft-list"
+	PathSCList     = "/api/de-exp/get-smart-contract-list"
+	PathTokenChain = "/api/de-exp/get-token-chain"
+	PathTxnAmount  = "/api/de-exp/get-txn-amount-by-txnID"
+)
+
+// MalformedJSON is a truncated payload for Fault.Body
+const MalformedJSON = `{"status":true,"result":[{"TokenID":`
+
+// RBT is an entry of get-rbt-list (PascalCase, including the node's SyncStaus typo)
+type RBT struct {
+	TokenID       string  `json:"TokenID"`
+	TokenValue    float64 `json:"TokenValue"`
+	OwnerDID      string  `json:"OwnerDID"`
+	PublisherDID  string  `json:"PublisherDID"`
+	TransactionID string  `json:"TransactionID"`
+	BlockHash     string  `json:"BlockHash"`
+	BlockHeight   uint64  `json:"BlockHeight"`
+	SyncStaus     int     `json:"SyncStaus"`
+	TokenStatus   int     `json:"TokenStatus"`
+}
+
+// FT is an entry of get-ft-list
+type FT struct {
+	TokenID       string  `json:"TokenID"`
+	FTName        string  `json:"FTName"`
+	OwnerDID      string  `json:"OwnerDID"`
+	CreatorDID    string  `json:"CreatorDID"`
+	PublisherDID  string  `json:"PublisherDID"`
+	TokenValue    float64 `json:"TokenValue"`
+	TransactionID string  `json:"TransactionID"`
+	BlockHash     string  `json:"BlockHash"`
+	BlockHeight   uint64  `json:"BlockHeight"`
+	SyncStatus    int     `json:"SyncStatus"`
+	TokenStatus   int     `json:"TokenStatus"`
+}
+
+// NFT is an entry of get-nft-list (snake_case ID and value)
+type NFT struct {
+	TokenID       string  `json:"token_id"`
+	TokenValue    float64 `json:"token_value"`
+	OwnerDID      string  `json:"OwnerDID"`
+	PublisherDID  string  `json:"PublisherDID"`
+	TransactionID string  `json:"TransactionID"`
+	BlockHash     string  `json:"BlockHash"`
+	BlockHeight   uint64  `json:"BlockHeight"`
+	SyncStatus    int     `json:"SyncStatus"`
+	TokenStatus   int     `json:"TokenStatus"`
+}
+
+// SC is an entry of get-smart-contract-list
+type SC struct {
+	SmartContractHash string `json:"smart_contract_hash"`
+	Deployer          string `json:"deployer"`
+	PublisherDID      string `json:"PublisherDID"`
+	TransactionID     string `json:"TransactionID"`
+	BlockHash         string `json:"BlockHash"`
+	BlockHeight       uint64 `json:"BlockHeight"`
+	SyncStatus        int    `json:"SyncStatus"`
+	TokenStatus       int    `json:"TokenStatus"`
+}
+
+// TxnAmount is the result of get-txn-amount-by-txnID
+type TxnAmount struct {
+	TransactionID    string  `json:"TransactionID"`
+	TransactionValue float64 `json:"TransactionValue"`
+	BlockHash        string  `json:"BlockHash"`
+}
+
+// Fault changes how one endpoint answers. Latency delays the answer; a non-zero Status
+// replaces the status code and a non-empty Body replaces the payload. Times limits the
+// fault to that many requests; zero keeps it until Clear.
+type Fault struct {
+	Latency time.Duration
+	Status  int
+	Body    string
+	Times   int
+}
+
+// Node is a fake fullnode listening on URL
+type Node struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu      sync.Mutex
+	rbts    []RBT
+	fts     []FT
+	nfts    []NFT
+	scs     []SC
+	chains  map[string][]map[string]interface{}
+	amounts map[string]TxnAmount
+	faults  map[string]*Fault
+	hits    map[string]int
+}
+
+// New starts a fake fullnode that is shut down when the test ends
+func New(t testing.TB) *Node {
+	n := &Node{
+		chains:  map[string][]map[string]interface{}{},
+		amounts: map[string]TxnAmount{},
+		faults:  map[string]*Fault{},
+		hits:    map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathHealth, n.serve(n.health))
+	mux.HandleFunc(PathRBTList, n.serve(n.rbtList))
+	mux.HandleFunc(PathFTList, n.serve(n.ftList))
+	mux.HandleFunc(PathNFTList, n.serve(n.nftList))
+	mux.HandleFunc(PathSCList, n.serve(n.scList))
+	mux.HandleFunc(PathTokenChain, n.serve(n.tokenChain))
+	mux.HandleFunc(PathTxnAmount, n.serve(n.txnAmount))
+
+	n.srv = httptest.NewServer(mux)
+	n.URL = n.srv.URL
+	t.Cleanup(n.srv.Close)
+	return n
+}
+
+// ========================= Fixtures =========================
+
+func (n *Node) AddRBTs(rbts ...RBT) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.rbts = append(n.rbts, rbts...)
+}
+
+func (n *Node) AddFTs(fts ...FT) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fts = append(n.fts, fts...)
+}
+
+func (n *Node) AddNFTs(nfts ...NFT) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nfts = append(n.nfts, nfts...)
+}
+
+func (n *Node) AddSCs(scs ...SC) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.scs = append(n.scs, scs...)
+}
+
+// SetChain replaces the token chain served for tokenID
+func (n *Node) SetChain(tokenID string, blocks []map[string]interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.chains[tokenID] = append([]map[string]interface{}(nil), blocks...)
+}
+
+// Chain returns a copy of the token chain served for tokenID
+func (n *Node) Chain(tokenID string) []map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]map[string]interface{}(nil), n.chains[tokenID]...)
+}
+
+// AppendBlock adds a block to the end of a token chain and moves the head of the
+// token's list entry to it, as the fullnode does when it commits a block
+func (n *Node) AppendBlock(tokenID string, block map[string]interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.chains[tokenID] = append(n.chains[tokenID], block)
+
+	hash, height := Hash(block), uint64(len(n.chains[tokenID])-1)
+	for i := range n.rbts {
+		if n.rbts[i].TokenID == tokenID {
+			n.rbts[i].BlockHash, n.rbts[i].BlockHeight = hash, height
+		}
+	}
+	for i := range n.fts {
+		if n.fts[i].TokenID == tokenID {
+			n.fts[i].BlockHash, n.fts[i].BlockHeight = hash, height
+		}
+	}
+	for i := range n.nfts {
+		if n.nfts[i].TokenID == tokenID {
+			n.nfts[i].BlockHash, n.nfts[i].BlockHeight = hash, height
+		}
+	}
+	for i := range n.scs {
+		if n.scs[i].SmartContractHash == tokenID {
+			n.scs[i].BlockHash, n.scs[i].BlockHeight = hash, height
+		}
+	}
+}
+
+// SetTxnAmount sets the answer of get-txn-amount-by-txnID for txnID
+func (n *Node) SetTxnAmount(txnID string, value float64, blockHash string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.amounts[txnID] = TxnAmount{TransactionID: txnID, TransactionValue: value, BlockHash: blockHash}
+}
+
+// ========================= Faults =========================
+
+// Inject makes path answer with f until Clear (or until f.Times requests were served)
+func (n *Node) Inject(path string, f Fault) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.faults[path] = &f
+}
+
+// Clear removes the fault of path
+func (n *Node) Clear(path string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.faults, path)
+}
+
+// Hits returns how many requests path received
+func (n *Node) Hits(path string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.hits[path]
+}
+
+// takeFault counts a request to path and returns the fault to apply to it, if any
+func (n *Node) takeFault(path string) *Fault {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.hits[path]++
+	f, ok := n.faults[path]
+	if !ok {
+		return nil
+	}
+	applied := *f
+	if f.Times > 0 {
+		f.Times--
+		if f.Times == 0 {
+			delete(n.faults, path)
+		}
+	}
+	return &applied
+}
+
+// ========================= Handlers =========================
+
+// serve wraps a handler with fault injection. The handler returns the payload to encode.
+func (n *Node) serve(h func(r *http.Request) (int, interface{})) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, payload := h(r)
+		body, err := json.Marshal(payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if f := n.takeFault(r.URL.Path); f != nil {
+			if f.Latency > 0 {
+				select {
+				case <-time.After(f.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if f.Status != 0 {
+				status = f.Status
+			}
+			if f.Body != "" {
+				body = []byte(f.Body)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+}
+
+func (n *Node) health(r *http.Request) (int, interface{}) {
+	if r.URL.Path != PathHealth {
+		return http.StatusNotFound, map[string]interface{}{"status": false, "message": "not found"}
+	}
+	return http.StatusOK, map[string]interface{}{"status": true, "message": "fullnode is up"}
+}
+
+func (n *Node) rbtList(*http.Request) (int, interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return http.StatusOK, map[string]interface{}{"Status": true, "Message": "RBT list", "Result": append([]RBT{}, n.rbts...)}
+}
+
+func (n *Node) ftList(*http.Request) (int, interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return http.StatusOK, map[string]interface{}{"Status": true, "Message": "FT list", "Result": append([]FT{}, n.fts...)}
+}
+
+func (n *Node) nftList(*http.Request) (int, interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return http.StatusOK, map[string]interface{}{"Status": true, "Message": "NFT list", "Result": append([]NFT{}, n.nfts...)}
+}
+
+func (n *Node) scList(*http.Request) (int, interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return http.StatusOK, map[string]interface{}{"status": true, "message": "Smart contract list", "result": append([]SC{}, n.scs...)}
+}
+
+func (n *Node) tokenChain(r *http.Request) (int, interface{}) {
+	tokenID := r.URL.Query().Get("tokenID")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	chain, ok := n.chains[tokenID]
+	if !ok {
+		return http.StatusOK, map[string]interface{}{
+			"status":  false,
+			"message": fmt.Sprintf("token chain of %s not found", tokenID),
+		}
+	}
+	return http.StatusOK, map[string]interface{}{
+		"status":         true,
+		"message":        "Token chain data",
+		"TokenChainData": append([]map[string]interface{}{}, chain...),
+	}
+}
+
+func (n *Node) txnAmount(r *http.Request) (int, interface{}) {
+	txnID := r.URL.Query().Get("txnID")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	amount, ok := n.amounts[txnID]
+	if !ok {
+		return http.StatusOK, map[string]interface{}{"status": false, "message": "transaction not found"}
+	}
+	return http.StatusOK, map[string]interface{}{"status": true, "message": "Transaction amount", "result": amount}
+}
+
+// ========================= Pushes =========================
+
+// PushBlock appends block to the chain of tokenID and posts it to the explorer's
+// /api/block-update, as a fullnode does after a transaction
+func (n *Node) PushBlock(explorerURL, tokenID string, block map[string]interface{}) (*http.Response, error) {
+	n.AppendBlock(tokenID, block)
+
+	body, err := json.Marshal(block)
+	if err != nil {
+		return nil, err
+	}
+	return http.Post(explorerURL+"/api/block-update", "application/json", bytes.NewReader(body))
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"explorer-server/test/fakenode"
+)
+
+func TestSyncFailsOverFromBrokenFullnode(t *testing.T) {
+	broken := fakenode.New(t)
+	for _, path := range []string{
+		fakenode.PathRBTList, fakenode.PathFTList, fakenode.PathNFTList,
+		fakenode.PathSCList, fakenode.PathTokenChain,
+	} {
+		broken.Inject(path, fakenode.Fault{Status: http.StatusBadGateway})
+	}
+
+	e := newExplorer(t, broken)
+	e.sync()
+
+	e.expect("/api/allrbtcount", http.StatusOK, map[string]string{"all_rbt_count": "3"})
+	e.expect("/api/sync-status", http.StatusOK, map[string]string{"token_chain.done": "6", "token_chain.failed": "0"})
+	e.expect("/api/token-blocks?tokenID=QmRBT2", http.StatusOK, map[string]string{"total_blocks": "4"})
+
+	_, body := e.get("/api/node-status")
+	for _, u := range field(body, "upstreams").([]interface{}) {
+		if field(u, "url") == broken.URL && str(field(u, "consecutive_failures")) == "0" {
+			t.Errorf("failures of the broken fullnode were not recorded: %v", u)
+		}
+	}
+}
+
+func TestTransientChainErrorIsRetried(t *testing.T) {
+	e := newExplorer(t)
+	e.node.Inject(fakenode.PathTokenChain, fakenode.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+	e.sync()
+
+	e.expect("/api/sync-status", http.StatusOK, map[string]string{"token_chain.done": "6", "token_chain.failed": "0"})
+	e.expect("/api/admin/failed-syncs", http.StatusOK, map[string]string{"count": "0"})
+	if hits := e.node.Hits(fakenode.PathTokenChain); hits != 7 {
+		t.Errorf("get-token-chain hits = %d, want 7 (6 chains + 1 retry)", hits)
+	}
+}
+
+func TestSlowFullnodeStillSyncs(t *testing.T) {
+	e := newExplorer(t)
+	e.node.Inject(fakenode.PathRBTList, fakenode.Fault{Latency: 200 * time.Millisecond})
+	e.node.Inject(fakenode.PathTokenChain, fakenode.Fault{Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	e.sync()
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("sync took %s, injected latency was not applied", elapsed)
+	}
+
+	e.expect("/api/allrbtcount", http.StatusOK, map[string]string{"all_rbt_count": "3"})
+	e.expect("/api/sync-status", http.StatusOK, map[string]string{"token_chain.done": "6"})
+}
+
+func TestMalformedFullnodePayloads(t *testing.T) {
+	e := newExplorer(t)
+	e.node.Inject(fakenode.PathRBTList, fakenode.Fault{Body: fakenode.MalformedJSON, Times: 1})
+	e.node.Inject(fakenode.PathTokenChain, fakenode.Fault{Body: fakenode.MalformedJSON})
+	e.sync()
+
+	// the other asset lists still sync; the RBT list and every chain fail
+	e.expect("/api/allrbtcount", http.StatusOK, map[string]string{"all_rbt_count": "0"})
+	e.expect("/api/allftcount", http.StatusOK, map[string]string{"all_ft_count": "1"})
+	e.expect("/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "0"})
+	e.expect("/api/sync-status", http.StatusOK, map[string]string{"token_chain.failed": "3"})
+	e.expect("/api/admin/failed-syncs", http.StatusOK, map[string]string{"count": "3", "failed_syncs.0.status": "pending"})
+
+	// once the fullnode answers properly the next sync catches up and clears the failures
+	e.node.Clear(fakenode.PathTokenChain)
+	e.sync()
+
+	e.expect("/api/allrbtcount", http.StatusOK, map[string]string{"all_rbt_count": "3"})
+	e.expect("/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "1"})
+	e.expect("/api/admin/failed-syncs", http.StatusOK, map[string]string{"count": "0"})
+}
+
+// TestRefusedChainIsAFailedSync checks that a fullnode answering "status": false is a
+// failed sync that stays queued, not an empty chain that counts as done
+func TestRefusedChainIsAFailedSync(t *testing.T) {
+	e := newExplorer(t)
+	e.node.Inject(fakenode.PathTokenChain, fakenode.Fault{Body: `{"status":false,"message":"chain not found"}`})
+	e.sync()
+
+	e.expect("/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "0"})
+	e.expect("/api/sync-status", http.StatusOK, map[string]string{"token_chain.done": "0", "token_chain.failed": "6"})
+	e.expect("/api/admin/failed-syncs", http.StatusOK, map[string]string{"count": "6", "failed_syncs.0.status": "pending"})
+}
+
+// TestChainWithoutBlockArrayIsAFailedSync checks that a response carrying no block array
+// is queued for retry rather than counted as a synced chain
+func TestChainWithoutBlockArrayIsAFailedSync(t *testing.T) {
+	e := newExplorer(t)
+	e.node.Inject(fakenode.PathTokenChain, fakenode.Fault{Body: `{"status":true,"message":"ok"}`})
+	e.sync()
+
+	e.expect("/api/sync-status", http.StatusOK, map[string]string{"token_chain.done": "0", "token_chain.failed": "6"})
+	e.expect("/api/admin/failed-syncs", http.StatusOK, map[string]string{"count": "6", "failed_syncs.0.status": "pending"})
+}
+
+func TestChainVerificationAcrossFullnodes(t *testing.T) {
+	forked := fakenode.New(t)
+	e := newExplorer(t, forked)
+
+	// the second fullnode serves the same ledger except for the second block of QmRBT1
+	e.fixture.load(forked)
+	chain := e.node.Chain("QmRBT1")
+	fork := fakenode.Next("QmRBT1", chain[:1], fakenode.Block{
+		TransType: "02", Owner: didQuorum, Sender: didAlice, Receiver: didQuorum,
+		TxnID: "txn-fork", Value: 1, Epoch: t0 + 3600,
+	})
+	forked.SetChain("QmRBT1", append(chain[:1:1], fork))
+	e.sync()
+
+	status, body := e.do(http.MethodPost, "/api/verify-token-chain?token_id=QmRBT1", nil)
+	if status != http.StatusOK {
+		t.Fatalf("verify-token-chain: status %d (%v)", status, body)
+	}
+	if str(field(body, "consistent")) != "false" || str(field(body, "divergences.0.block_index")) != "1" {
+		t.Fatalf("verify-token-chain = %v, want a divergence at block 1", body)
+	}
+	e.expect("/api/divergences?token_id=QmRBT1", http.StatusOK, map[string]string{"count": "1"})
+
+	// verifying again refreshes the known divergence instead of recording it twice
+	if status, body := e.do(http.MethodPost, "/api/verify-token-chain?token_id=QmRBT1", nil); status != http.StatusOK {
+		t.Fatalf("second verify-token-chain: status %d (%v)", status, body)
+	}
+	e.expect("/api/divergences?token_id=QmRBT1", http.StatusOK, map[string]string{"count": "1"})
+
+	// a fullnode refusing the chain is left out, not compared as an empty chain
+	forked.Inject(fakenode.PathTokenChain, fakenode.Fault{Body: `{"status":false,"message":"chain not found"}`})
+	if status, body := e.do(http.MethodPost, "/api/verify-token-chain?token_id=QmRBT1", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("verify-token-chain with a refusing fullnode: status %d (%v), want 503", status, body)
+	}
+	e.expect("/api/divergences?token_id=QmRBT1", http.StatusOK, map[string]string{"count": "1"})
+}
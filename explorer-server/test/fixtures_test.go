@@ -0,0 +1,108 @@
+package test
+
+import (
+	"explorer-server/test/fakenode"
+)
+
+const (
+	didAlice  = "bafyAlice"
+	didBob    = "bafyBob"
+	didQuorum = "bafyQuorum1"
+
+	// t0 is the epoch of the first fixture block (2023-11-14 22:13:20 UTC)
+	t0 = 1700000000
+)
+
+// fixture is the ledger the fake fullnode serves in the end-to-end tests:
+//
+//	QmRBT1  minted by alice, transferred to bob with a quorum pledge (txn-rbt1)
+//	QmRBT2  minted by alice, then pledged, committed and pinned
+//	QmRBT3  minted by alice and burnt into QmPart1 and QmPart2
+//	QmFT1   "Gold" FT created by alice, held by bob
+//	QmNFT1  NFT held by alice
+//	QmSC1   smart contract deployed by alice and executed by bob
+type fixture struct {
+	chains map[string][]map[string]interface{}
+}
+
+func newFixture() *fixture {
+	pledge := map[string]interface{}{
+		"8": map[string]interface{}{
+			didQuorum: []interface{}{
+				map[string]interface{}{"1": "QmRBT2", "2": 0, "3": "pledge-block-1"},
+			},
+		},
+	}
+
+	return &fixture{chains: map[string][]map[string]interface{}{
+		"QmRBT1": fakenode.Chain("QmRBT1",
+			fakenode.Block{TransType: "01", Owner: didAlice, Value: 1, Epoch: t0},
+			fakenode.Block{TransType: "02", Owner: didBob, Sender: didAlice, Receiver: didBob,
+				TxnID: "txn-rbt1", Value: 1, Epoch: t0 + 3600, Extra: pledge},
+		),
+		"QmRBT2": fakenode.Chain("QmRBT2",
+			fakenode.Block{TransType: "01", Owner: didAlice, Value: 2, Epoch: t0},
+			fakenode.Block{TransType: "04", Owner: didAlice, TxnID: "txn-pledge", Value: 2, Epoch: t0 + 3600},
+			fakenode.Block{TransType: "07", Owner: didAlice, TxnID: "txn-commit", Value: 2, Epoch: t0 + 7200},
+			fakenode.Block{TransType: "12", Owner: didAlice, TxnID: "txn-pin", Value: 2, Epoch: t0 + 10800},
+		),
+		"QmRBT3": fakenode.Chain("QmRBT3",
+			fakenode.Block{TransType: "01", Owner: didAlice, Value: 1, Epoch: t0},
+			fakenode.Block{TransType: "08", Owner: didAlice, Value: 1, Epoch: t0 + 3600,
+				Extra: map[string]interface{}{"11": []string{"QmPart1", "QmPart2"}}},
+		),
+		"QmFT1": fakenode.Chain("QmFT1",
+			fakenode.Block{TransType: "01", TokenType: 10, Owner: didBob, Value: 1, Epoch: t0},
+		),
+		"QmNFT1": fakenode.Chain("QmNFT1",
+			fakenode.Block{TransType: "01", TokenType: 11, Owner: didAlice, Value: 1, Epoch: t0},
+		),
+		"QmSC1": fakenode.Chain("QmSC1",
+			fakenode.Block{TransType: "09", Owner: didAlice, Deployer: didAlice, Epoch: t0 + 100},
+			fakenode.Block{TransType: "10", Owner: didAlice, Executor: didBob, Epoch: t0 + 200},
+		),
+	}}
+}
+
+// head returns the hash and height of the last block of a fixture chain
+func (f *fixture) head(tokenID string) (string, uint64) {
+	chain := f.chains[tokenID]
+	return fakenode.Hash(chain[len(chain)-1]), uint64(len(chain) - 1)
+}
+
+// hash returns the hash of block i of a fixture chain
+func (f *fixture) hash(tokenID string, i int) string {
+	return fakenode.Hash(f.chains[tokenID][i])
+}
+
+// load puts the fixture ledger on a fake fullnode
+func (f *fixture) load(node *fakenode.Node) {
+	for tokenID, chain := range f.chains {
+		node.SetChain(tokenID, chain)
+	}
+
+	rbt := func(id, owner string, value float64, status int) fakenode.RBT {
+		hash, height := f.head(id)
+		return fakenode.RBT{TokenID: id, TokenValue: value, OwnerDID: owner, PublisherDID: didAlice,
+			BlockHash: hash, BlockHeight: height, TokenStatus: status}
+	}
+	node.AddRBTs(
+		rbt("QmRBT1", didBob, 1, 0),
+		rbt("QmRBT2", didAlice, 2, 0),
+		rbt("QmRBT3", didAlice, 1, 1),
+	)
+
+	hash, height := f.head("QmFT1")
+	node.AddFTs(fakenode.FT{TokenID: "QmFT1", FTName: "Gold", OwnerDID: didBob, CreatorDID: didAlice,
+		TokenValue: 1, BlockHash: hash, BlockHeight: height})
+
+	hash, height = f.head("QmNFT1")
+	node.AddNFTs(fakenode.NFT{TokenID: "QmNFT1", TokenValue: 1, OwnerDID: didAlice,
+		BlockHash: hash, BlockHeight: height})
+
+	hash, height = f.head("QmSC1")
+	node.AddSCs(fakenode.SC{SmartContractHash: "QmSC1", Deployer: didAlice,
+		BlockHash: hash, BlockHeight: height})
+
+	node.SetTxnAmount("txn-rbt1", 1, f.hash("QmRBT1", 1))
+}
@@ -0,0 +1,167 @@
+// Package test runs the explorer end to end: the services sync from a fake fullnode into
+// in-memory repositories and the tests read everything back through the HTTP router.
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"explorer-server/repository"
+	"explorer-server/router"
+	"explorer-server/services"
+	"explorer-server/test/fakenode"
+)
+
+func TestMain(m *testing.M) {
+	// the services log every block; keep the output readable unless -v is set
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	services.InitWorkerPools(0)
+	os.Exit(m.Run())
+}
+
+// explorer is one explorer instance on fresh in-memory storage, synced from fake fullnodes
+type explorer struct {
+	t       *testing.T
+	fixture *fixture
+	node    *fakenode.Node
+	api     *httptest.Server
+}
+
+// newExplorer starts a fake fullnode serving the fixture ledger and an explorer API in
+// front of it. extra nodes are added as further upstreams after the fixture node.
+func newExplorer(t *testing.T, extra ...*fakenode.Node) *explorer {
+	t.Helper()
+
+	e := &explorer{t: t, fixture: newFixture(), node: fakenode.New(t)}
+	e.fixture.load(e.node)
+
+	services.SetRepositories(repository.NewMemory())
+	upstreams := []string{e.node.URL}
+	for _, n := range extra {
+		upstreams = append(upstreams, n.URL)
+	}
+	services.SetNodeUpstreams(upstreams)
+
+	e.api = httptest.NewServer(router.NewRouter())
+	t.Cleanup(e.api.Close)
+	return e
+}
+
+// sync runs the initial asset-list and token-chain sync
+func (e *explorer) sync() {
+	services.RunFullSync()
+}
+
+// push posts a block to /api/block-update the way the fullnode does and applies it
+func (e *explorer) push(tokenID string, block map[string]interface{}) {
+	e.t.Helper()
+
+	resp, err := e.node.PushBlock(e.api.URL, tokenID, block)
+	if err != nil {
+		e.t.Fatalf("push to %s: %v", tokenID, err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		e.t.Fatalf("push to %s: status %d", tokenID, resp.StatusCode)
+	}
+	services.DrainIngestInbox()
+}
+
+// get requests path from the explorer API and decodes the JSON answer
+func (e *explorer) get(path string) (int, interface{}) {
+	e.t.Helper()
+	return e.do(http.MethodGet, path, nil)
+}
+
+func (e *explorer) do(method, path string, body []byte) (int, interface{}) {
+	e.t.Helper()
+
+	req, err := http.NewRequest(method, e.api.URL+path, bytes.NewReader(body))
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		e.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		// a few answers, such as /health, are not JSON
+		decoded = strings.TrimSpace(string(raw))
+	}
+	return resp.StatusCode, decoded
+}
+
+// field walks a decoded JSON value along a dotted path; numeric segments index arrays
+// and "#" returns the length of the array or object reached so far
+func field(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	for _, seg := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			if seg == "#" {
+				return float64(len(node))
+			}
+			v = node[seg]
+		case []interface{}:
+			if seg == "#" {
+				return float64(len(node))
+			}
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// str renders a decoded JSON value for comparisons; whole numbers print without decimals
+func str(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "<nil>"
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// expect checks that the decoded answer of path has want at each field
+func (e *explorer) expect(path string, wantStatus int, want map[string]string) {
+	e.t.Helper()
+
+	status, body := e.get(path)
+	if status != wantStatus {
+		e.t.Errorf("GET %s: status %d, want %d (body %v)", path, status, wantStatus, body)
+		return
+	}
+	for f, w := range want {
+		if got := str(field(body, f)); got != w {
+			e.t.Errorf("GET %s: %s = %s, want %s", path, f, got, w)
+		}
+	}
+}
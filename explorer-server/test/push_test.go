@@ -0,0 +1,173 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"explorer-server/services"
+	"explorer-server/test/fakenode"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+func TestLivePushExtendsSyncedChain(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	// bob sends QmRBT1 back; the pushed block carries no value, so the amount
+	// has to come from get-txn-amount-by-txnID
+	block := fakenode.Next("QmRBT1", e.node.Chain("QmRBT1"), fakenode.Block{
+		TransType: "02", Owner: didAlice, Sender: didBob, Receiver: didAlice,
+		TxnID: "txn-push-1", Epoch: t0 + 86400,
+	})
+	hash := fakenode.Hash(block)
+	e.node.SetTxnAmount("txn-push-1", 1, hash)
+	e.push("QmRBT1", block)
+
+	e.expect("/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "2"})
+	e.expect("/api/txnblocks", http.StatusOK, map[string]string{
+		"count": "2", "transactions_response.0.txn_hash": "txn-push-1", "transactions_response.0.amount": "1"})
+	if e.node.Hits(fakenode.PathTxnAmount) == 0 {
+		t.Error("missing amount was not fetched from the fullnode")
+	}
+
+	e.expect("/api/txnhash?hash=txn-push-1", http.StatusOK, map[string]string{
+		"block_hash": hash, "sender_did": didBob, "amount": "1"})
+	if v := services.GetBlockVerification("txn-push-1"); v == nil || v.HashStatus != "match" {
+		t.Errorf("pushed block hash status = %v, want match", v)
+	}
+	e.expect("/api/token-chain?token_id=QmRBT1", http.StatusOK, map[string]string{
+		"TokenChainData.#": "3", "TokenChainData.2.98": hash})
+	e.expect("/api/token-blocks?tokenID=QmRBT1", http.StatusOK, map[string]string{"total_blocks": "3"})
+	e.expect("/api/queue-status", http.StatusOK, map[string]string{
+		"inbox.0.kind": "block", "inbox.0.status": "processed", "inbox.0.count": "1"})
+
+	// the push advanced the checkpoint to the new head, so the next scheduled sync has
+	// nothing to fetch even though the fullnode list moved
+	before := e.node.Hits(fakenode.PathTokenChain)
+	e.sync()
+	e.expect("/api/token-chain?token_id=QmRBT1", http.StatusOK, map[string]string{"TokenChainData.#": "3"})
+	e.expect("/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "2"})
+	if hits := e.node.Hits(fakenode.PathTokenChain); hits != before {
+		t.Errorf("resync fetched %d token chains that were already up to date", hits-before)
+	}
+}
+
+func TestMalformedPushesAreRejectedOrParked(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	if status, _ := e.do(http.MethodPost, "/api/block-update", []byte(fakenode.MalformedJSON)); status != http.StatusBadRequest {
+		t.Fatalf("truncated JSON push: status %d, want 400", status)
+	}
+
+	// valid JSON but not a block: accepted into the inbox, then kept there for retries
+	status, _ := e.do(http.MethodPost, "/api/block-update", []byte(`{"2":"02","5":{"4":"txn-bad"}}`))
+	if status != http.StatusOK {
+		t.Fatalf("hashless push: status %d, want 200", status)
+	}
+	e.expect("/api/block?id=txn-bad", http.StatusNotFound, nil)
+	e.expect("/api/queue-status", http.StatusOK, map[string]string{
+		"inbox.0.status": "pending", "inbox.0.count": "1"})
+	e.expect("/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "1"})
+}
+
+func TestBlockWithWrongHashIsFlagged(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	block := fakenode.Next("QmRBT2", e.node.Chain("QmRBT2"), fakenode.Block{
+		TransType: "02", Owner: didBob, Sender: didAlice, Receiver: didBob,
+		TxnID: "txn-tampered", Value: 2, Epoch: t0 + 86400,
+	})
+	block["10"] = 200.0 // value changed after hashing
+	e.push("QmRBT2", block)
+
+	if v := services.GetBlockVerification("txn-tampered"); v == nil || v.HashStatus != "mismatch" {
+		t.Fatalf("tampered block hash status = %v, want mismatch", v)
+	}
+	mismatches, total, err := services.ListHashMismatches(10, 1)
+	if err != nil || total != 1 || mismatches[0].BlockHash != fakenode.Hash(block) {
+		t.Fatalf("hash mismatches = %v (%d, %v), want the tampered block", mismatches, total, err)
+	}
+
+	// the hash check is internal until it is confirmed against real fullnode blocks
+	_, body := e.get("/api/block-verification?id=txn-tampered")
+	if field(body, "hash_status") != nil || field(body, "computed_hash") != nil {
+		t.Errorf("block verification exposes the hash check: %s", body)
+	}
+}
+
+// TestHashMismatchDoesNotRejectBlock checks that a block whose recorded hash does not
+// recompute is still stored, only flagged
+func TestHashMismatchDoesNotRejectBlock(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	block := fakenode.Next("QmRBT1", e.node.Chain("QmRBT1"), fakenode.Block{
+		TransType: "02", Owner: didAlice, Sender: didBob, Receiver: didAlice,
+		TxnID: "txn-push-odd", Epoch: t0 + 86400, Value: 1,
+	})
+	block["98"] = "00" + fakenode.Hash(block)[2:]
+	e.push("QmRBT1", block)
+
+	e.expect("/api/txnhash?hash=txn-push-odd", http.StatusOK, map[string]string{
+		"block_hash": fakenode.Hash(block)})
+	if _, total, err := services.ListHashMismatches(10, 1); err != nil || total != 1 {
+		t.Fatalf("%d hash mismatches (%v), want 1", total, err)
+	}
+}
+
+// TestBlockSignedByUnknownDIDIsUnverified checks that a signature whose key the explorer
+// does not have is reported as unverified, and verifies once the key is registered
+func TestBlockSignedByUnknownDIDIsUnverified(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	key, _ := btcec.PrivKeyFromBytes([]byte("alice's test signing key 32bytes"))
+	const signedHash = "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff00"
+	digest := sha256.Sum256([]byte(signedHash))
+	block := fakenode.Next("QmRBT2", e.node.Chain("QmRBT2"), fakenode.Block{
+		TransType: "02", Owner: didBob, Sender: didAlice, Receiver: didBob,
+		TxnID: "txn-signed", Value: 2, Epoch: t0 + 86400,
+		Extra: map[string]interface{}{"12": map[string]interface{}{
+			"2": hex.EncodeToString(ecdsa.Sign(key, digest[:]).Serialize()),
+			"3": didAlice,
+			"4": signedHash,
+		}},
+	})
+	e.push("QmRBT2", block)
+
+	e.expect("/api/block-verification?id=txn-signed", http.StatusOK, map[string]string{
+		"status": "unverified", "sender_status": "unverified"})
+	e.expect("/api/admin/unverified-blocks", http.StatusOK, map[string]string{
+		"unverified.0.block_hash": fakenode.Hash(block)})
+
+	body, _ := json.Marshal(map[string]string{
+		"did": didAlice, "public_key": hex.EncodeToString(key.PubKey().SerializeCompressed())})
+	if status, resp := e.do(http.MethodPost, "/api/admin/did-keys", body); status != http.StatusOK {
+		t.Fatalf("registering key: status %d (%v)", status, resp)
+	}
+	e.expect("/api/block-verification?id=txn-signed", http.StatusOK, map[string]string{
+		"status": "verified", "sender_status": "verified"})
+}
+
+// TestUnixTimeFormatLeavesOtherStrings checks that time_format=unix converts timestamp
+// fields only, not a comment that happens to hold a timestamp
+func TestUnixTimeFormatLeavesOtherStrings(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	block := fakenode.Next("QmRBT2", e.node.Chain("QmRBT2"), fakenode.Block{
+		TransType: "12", Owner: didAlice, TxnID: "txn-pin-comment", Value: 2, Epoch: t0 + 86400,
+	})
+	block["5"].(map[string]interface{})["3"] = "2023-11-14T22:13:20Z"
+	e.push("QmRBT2", block)
+
+	e.expect("/api/pin-blocks?time_format=unix", http.StatusOK, map[string]string{
+		"count": "2", "pinblocks.0.comment": "2023-11-14T22:13:20Z", "pinblocks.0.epoch": "1700086400"})
+}
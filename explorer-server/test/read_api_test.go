@@ -0,0 +1,158 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestReadEndpointsAfterInitialSync syncs the fixture ledger and checks every read endpoint
+func TestReadEndpointsAfterInitialSync(t *testing.T) {
+	e := newExplorer(t)
+	e.sync()
+
+	f := e.fixture
+	transferHash := f.hash("QmRBT1", 1)
+	burntHash := f.hash("QmRBT3", 1)
+	pinHash := f.hash("QmRBT2", 3)
+	deployHash := f.hash("QmSC1", 0)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		want   map[string]string
+	}{
+		{"/health", http.StatusOK, map[string]string{"status": "ok"}},
+
+		// counts
+		{"/api/allrbtcount", http.StatusOK, map[string]string{"all_rbt_count": "3"}},
+		{"/api/allftcount", http.StatusOK, map[string]string{"all_ft_count": "1"}},
+		{"/api/allnftcount", http.StatusOK, map[string]string{"all_nft_count": "1"}},
+		{"/api/allsmartcontractscount", http.StatusOK, map[string]string{"all_sc_count": "1"}},
+		{"/api/alltransactionscount", http.StatusOK, map[string]string{"all_block_count": "1"}},
+		{"/api/alldidcount", http.StatusOK, map[string]string{"all_did_count": "2"}},
+
+		// assets
+		{"/api/rbt?rbtid=QmRBT1", http.StatusOK, map[string]string{
+			"rbt_info.owner_did": didBob, "rbt_info.token_value": "1", "rbt_info.block_id": transferHash}},
+		{"/api/ft?ftid=QmFT1", http.StatusOK, map[string]string{
+			"ft_info.ft_name": "Gold", "ft_info.owner_did": didBob, "ft_info.creator_did": didAlice}},
+		{"/api/nft?nftid=QmNFT1", http.StatusOK, map[string]string{"nft_info.owner_did": didAlice}},
+		{"/api/smartcontract?scid=QmSC1", http.StatusOK, map[string]string{"sc_info.deployer_did": didAlice}},
+		{"/api/getrbtlist?limit=2", http.StatusOK, map[string]string{"count": "3", "tokens.#": "2"}},
+		{"/api/ftholdings?did=" + didBob, http.StatusOK, map[string]string{"ft_info.#": "1", "ft_info.0.ft_id": "QmFT1"}},
+
+		// DIDs
+		{"/api/didwithmostrbts", http.StatusOK, map[string]string{
+			"holders_response.holders_response.0.owner_did":   didAlice,
+			"holders_response.holders_response.0.token_count": "2",
+			"holders_response.holders_response.1.owner_did":   didBob,
+		}},
+		// QmRBT3 is locked, so only QmRBT2 counts as alice's free RBT
+		{"/api/getdidinfo?did=" + didAlice, http.StatusOK, map[string]string{
+			"did.total_rbts": "2", "count": "1", "rbts.0.rbt_id": "QmRBT2"}},
+
+		// transfers
+		{"/api/txnblocks", http.StatusOK, map[string]string{
+			"count": "1", "transactions_response.0.txn_hash": "txn-rbt1",
+			"transactions_response.0.sender_did": didAlice, "transactions_response.0.amount": "1"}},
+		{"/api/txnblocks?from=2023-11-15T00:00:00Z", http.StatusOK, map[string]string{"count": "0"}},
+		{"/api/txnblocks?time_format=unix", http.StatusOK, map[string]string{
+			"transactions_response.0.txn_time": "1700003600"}},
+		{"/api/txnhash?hash=txn-rbt1", http.StatusOK, map[string]string{
+			"block_hash": transferHash, "receiver_did": didBob}},
+		{"/api/blockhash?hash=" + transferHash, http.StatusOK, map[string]string{"txn_id": "txn-rbt1"}},
+
+		// lifecycle blocks
+		{"/api/mint-blocks", http.StatusOK, map[string]string{"count": "5"}},
+		{"/api/pledge-blocks", http.StatusOK, map[string]string{"count": "1", "pledgeblocks.0.txn_id": "txn-pledge"}},
+		{"/api/commit-blocks", http.StatusOK, map[string]string{"count": "1", "commitblocks.0.txn_id": "txn-commit"}},
+		{"/api/pin-blocks", http.StatusOK, map[string]string{"count": "1", "pinblocks.0.block_hash": pinHash}},
+		{"/api/burnt-blocks", http.StatusOK, map[string]string{"count": "1", "burntblocks.0.block_hash": burntHash}},
+		{"/api/burnttxn-info?hash=" + burntHash, http.StatusOK, map[string]string{"owner_did": didAlice, "txn_type": "Burnt"}},
+		{"/api/sc-blocks", http.StatusOK, map[string]string{"count": "2"}},
+		{"/api/sctxn-info?hash=" + deployHash, http.StatusOK, map[string]string{"contract_id": "QmSC1", "owner_did": didAlice}},
+		{"/api/block?id=" + pinHash, http.StatusOK, map[string]string{
+			"block_type": "pinned", "data.txn_id": "txn-pin"}},
+		{"/api/block?id=txn-commit", http.StatusOK, map[string]string{"block_type": "committed"}},
+		{"/api/block?id=no-such-block", http.StatusNotFound, nil},
+
+		// search
+		{"/api/search?id=QmRBT1", http.StatusOK, map[string]string{"type": "RBT", "data.owner_did": didBob}},
+		{"/api/search?id=QmFT1", http.StatusOK, map[string]string{"type": "FT"}},
+		{"/api/search?id=" + didAlice, http.StatusOK, map[string]string{"type": "DID", "data.total_rbts": "2"}},
+		{"/api/search?id=txn-rbt1", http.StatusOK, map[string]string{"type": "TransferBlock", "data.block_hash": transferHash}},
+		{"/api/search?id=txn-pin", http.StatusOK, map[string]string{"type": "pinned"}},
+
+		// token chains
+		{"/api/token-chain?token_id=QmRBT2", http.StatusOK, map[string]string{
+			"TokenChainData.#": "4", "TokenChainData.3.98": pinHash, "status": "true"}},
+		{"/api/token-chain?token_id=QmRBT2&format=named", http.StatusOK, map[string]string{
+			"TokenChainData.3.TCBlockHashKey": pinHash}},
+		{"/api/token-blocks?tokenID=QmRBT2&limit=3&page=2", http.StatusOK, map[string]string{
+			"total_blocks": "4", "total_pages": "2", "data.#": "1"}},
+		{"/api/token-lineage?tokenID=QmPart1", http.StatusOK, map[string]string{"token.parents.0.token_id": "QmRBT3"}},
+		{"/api/token-lineage?tokenID=QmRBT3", http.StatusOK, map[string]string{"token.children.#": "2"}},
+
+		// ownership history
+		{"/api/token-owners?tokenID=QmRBT1", http.StatusOK, map[string]string{
+			"count": "2", "history.0.event_type": "mint", "history.0.to_did": didAlice,
+			"history.1.event_type": "transfer", "history.1.from_did": didAlice, "history.1.to_did": didBob,
+		}},
+		{"/api/token-owners?tokenID=QmRBT3", http.StatusOK, map[string]string{"count": "2", "history.1.event_type": "burn"}},
+		{"/api/token-owners", http.StatusBadRequest, nil},
+		{"/api/did/held-tokens?did=" + didBob, http.StatusOK, map[string]string{
+			"count": "2", "tokens.1.token_id": "QmRBT1", "tokens.1.holding": "true",
+		}},
+		{"/api/did/held-tokens?did=" + didAlice, http.StatusOK, map[string]string{
+			"count": "4", "tokens.1.token_id": "QmRBT1", "tokens.1.holding": "false", "tokens.1.releases": "1",
+		}},
+
+		// balance history: alice gives QmRBT1 to bob and burns QmRBT3 an hour after minting
+		{"/api/did/balance-history?did=" + didAlice + "&to=1700003000", http.StatusOK, map[string]string{
+			"balance.rbt": "4", "balance.nfts": "1", "balance.fts": "0",
+		}},
+		{"/api/did/balance-history?did=" + didAlice + "&to=1700007200&interval=hour", http.StatusOK, map[string]string{
+			"balances.#": "4", "balances.0.rbt": "0", "balances.1.rbt": "4", "balances.2.rbt": "2", "balance.rbt": "2",
+		}},
+		{"/api/did/balance-history?did=" + didBob + "&to=1700007200", http.StatusOK, map[string]string{
+			"balance.rbt": "1", "balance.fts": "1",
+		}},
+		{"/api/did/balance-history?did=" + didBob + "&interval=minute", http.StatusBadRequest, nil},
+		{"/api/did/balance-history", http.StatusBadRequest, nil},
+
+		// ledger: QmRBT2 stays pledged on its chain while the RBT list reports it free
+		{"/api/did/ledger?did=" + didAlice, http.StatusOK, map[string]string{
+			"count": "8", "balances.0.account": "@pledged:" + didAlice, "balances.0.balance": "2",
+			"balances.2.asset": "RBT", "balances.2.debits": "4", "balances.2.balance": "0",
+		}},
+		{"/api/did/ledger", http.StatusBadRequest, nil},
+
+		// quorums
+		{"/api/quorums", http.StatusOK, map[string]string{
+			"count": "1", "quorums.0.quorum_did": didQuorum, "quorums.0.pledged_value": "2"}},
+		{"/api/quorum/volume?did=" + didQuorum + "&interval=day", http.StatusOK, map[string]string{
+			"volume.#": "1", "volume.0.validations": "1"}},
+		{"/api/quorum/volume?interval=fortnight", http.StatusBadRequest, nil},
+		{"/api/quorum/transactions?did=" + didQuorum, http.StatusOK, map[string]string{
+			"count": "1", "transactions.0.txn_id": "txn-rbt1", "transactions.0.receiver_did": didBob}},
+
+		// integrity
+		{"/api/block-verification?id=txn-rbt1", http.StatusOK, map[string]string{"block_hash": transferHash}},
+		{"/api/divergences", http.StatusOK, map[string]string{"count": "0"}},
+		{"/api/admin/did-audit", http.StatusOK, map[string]string{"checked": "2", "mismatches.#": "0"}},
+		{"/api/admin/ledger-check", http.StatusOK, map[string]string{
+			"consistent": "true", "unbalanced.#": "0", "overdrawn.#": "0",
+			"supply.2.asset": "RBT", "supply.2.issued": "4", "supply.2.burnt": "1", "supply.2.held": "1", "supply.2.pledged": "2",
+			"unreconciled.#": "1", "unreconciled.0.did": didAlice, "unreconciled.0.field": "total_rbts",
+		}},
+
+		// sync and monitoring
+		{"/api/sync-status", http.StatusOK, map[string]string{
+			"token_chain.total": "6", "token_chain.done": "6", "token_chain.failed": "0"}},
+		{"/api/node-status", http.StatusOK, map[string]string{"upstreams.0.url": e.node.URL, "upstreams.0.healthy": "true"}},
+		{"/api/queue-status", http.StatusOK, map[string]string{"inbox.#": "0"}},
+		{"/api/admin/failed-syncs", http.StatusOK, map[string]string{"count": "0"}},
+	} {
+		e.expect(tc.path, tc.status, tc.want)
+	}
+}
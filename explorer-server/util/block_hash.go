@@ -0,0 +1,129 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Block hash check outcomes. The recomputation has not been confirmed against blocks
+// captured from a real fullnode, so a mismatch is advisory and never rejects a block.
+const (
+	HashMatch        = "match"
+	HashMismatch     = "mismatch"
+	HashUnverifiable = "unverifiable"
+)
+
+// HashCheck is the result of recomputing a block's hash
+type HashCheck struct {
+	Status       string `json:"hash_status"`
+	ExpectedHash string `json:"expected_hash"`
+	ComputedHash string `json:"computed_hash,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// CanonicalBlockBytes rebuilds the canonical serialization of a block. Blocks with named
+// keys are encoded back to the numeric wire format first. The block hash and owner
+// signatures are excluded, as they are added after hashing.
+func CanonicalBlockBytes(raw map[string]interface{}) ([]byte, error) {
+	if _, ok := raw["98"]; !ok {
+		raw = EncodeNumericKeys(raw).(map[string]interface{})
+	}
+	if _, ok := raw["98"]; !ok {
+		return nil, fmt.Errorf("block has no TCBlockHashKey")
+	}
+
+	body := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		if k == "98" || k == "99" {
+			continue
+		}
+		if k == "10" {
+			// token value is the only float field and keeps its decimals, even when whole
+			if n, ok := v.(json.Number); ok {
+				if f, err := n.Float64(); err == nil {
+					v = f
+				}
+			}
+			body[k] = v
+			continue
+		}
+		body[k] = canonicalNumbers(v)
+	}
+
+	s, err := tcMarshal("", body)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// canonicalNumbers turns whole JSON numbers back into integers, as the chain encodes them
+func canonicalNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, nested := range t {
+			out[k] = canonicalNumbers(nested)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, nested := range t {
+			out[i] = canonicalNumbers(nested)
+		}
+		return out
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return canonicalNumbers(f)
+		}
+		return t
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return int64(t)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// ComputeBlockHash returns the hex SHA3-256 of the block's canonical bytes
+func ComputeBlockHash(raw map[string]interface{}) (string, error) {
+	b, err := CanonicalBlockBytes(raw)
+	if err != nil {
+		return "", err
+	}
+	sum := sha3.Sum256(b)
+	return HexToStr(sum[:]), nil
+}
+
+// CheckBlockHash recomputes a block's hash and compares it with TCBlockHashKey
+func CheckBlockHash(raw map[string]interface{}) HashCheck {
+	expected, _ := lookup(raw, tcBlockHashKeys).(string)
+	check := HashCheck{ExpectedHash: expected}
+
+	if expected == "" {
+		check.Status, check.Reason = HashUnverifiable, "block has no hash"
+		return check
+	}
+
+	computed, err := ComputeBlockHash(raw)
+	if err != nil {
+		check.Status, check.Reason = HashUnverifiable, err.Error()
+		return check
+	}
+
+	check.ComputedHash = computed
+	if computed == expected {
+		check.Status = HashMatch
+	} else {
+		check.Status, check.Reason = HashMismatch, "recomputed hash differs from TCBlockHashKey"
+	}
+	return check
+}
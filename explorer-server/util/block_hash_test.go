@@ -0,0 +1,36 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBlockHashKnownAnswers recomputes the hash of blocks captured from a real fullnode and
+// compares it with the hash the node recorded
+func TestBlockHashKnownAnswers(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "fullnode_blocks", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skip("no captured fullnode blocks in testdata/fullnode_blocks; hash mismatches stay advisory")
+	}
+
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var block map[string]interface{}
+		if err := json.Unmarshal(raw, &block); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		if check := CheckBlockHash(block); check.Status != HashMatch {
+			t.Errorf("%s: hash %s, recorded %s, computed %s (%s)",
+				filepath.Base(file), check.Status, check.ExpectedHash, check.ComputedHash, check.Reason)
+		}
+	}
+}
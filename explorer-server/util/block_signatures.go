@@ -0,0 +1,165 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// Signature roles within a block
+const (
+	SignatureRoleSender = "sender"
+	SignatureRoleQuorum = "quorum"
+	SignatureRoleOwner  = "owner"
+)
+
+// BlockSignature is one signature claimed by a block: DID signed Message with Signature
+type BlockSignature struct {
+	Role      string `json:"role"`
+	DID       string `json:"did"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+	// NLCOnly marks signatures that carry only an image-based NLC signature, which cannot be checked here
+	NLCOnly bool `json:"nlc_only,omitempty"`
+}
+
+// ErrSignatureMismatch is returned when a signature does not match the message and public key
+var ErrSignatureMismatch = errors.New("signature does not match")
+
+// key aliases of the sender (initiator) and quorum (credit) signature objects
+var (
+	sigNLCKeys      = []string{"1", "nlc_signature", "signature"}
+	sigPrivKeys     = []string{"2", "priv_signature"}
+	sigDIDKeys      = []string{"3", "initiator_did", "did"}
+	sigHashKeys     = []string{"4", "hash"}
+	sigSignTypeKeys = []string{"5", "sign_type"}
+)
+
+// Signatures lists the sender, quorum and owner signatures carried by the block.
+// Owner signatures (TCSignatureKey) sign the block hash; sender and quorum signatures
+// sign the hash recorded next to them.
+func (b *TokenChainBlock) Signatures() []BlockSignature {
+	var sigs []BlockSignature
+
+	if m, ok := b.SenderSignature.(map[string]interface{}); ok {
+		if sig, ok := decodeSignatureObject(SignatureRoleSender, m); ok {
+			sigs = append(sigs, sig)
+		}
+	}
+
+	switch q := b.QuorumSignature.(type) {
+	case []interface{}:
+		for _, item := range q {
+			if m, ok := item.(map[string]interface{}); ok {
+				if sig, ok := decodeSignatureObject(SignatureRoleQuorum, m); ok {
+					sigs = append(sigs, sig)
+				}
+			}
+		}
+	case map[string]interface{}:
+		// keyed by quorum DID
+		for _, did := range sortedMapKeys(q) {
+			m, ok := q[did].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if sig, ok := decodeSignatureObject(SignatureRoleQuorum, m); ok {
+				if sig.DID == "" {
+					sig.DID = did
+				}
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+
+	if owners, ok := b.Signature.(map[string]interface{}); ok {
+		for _, did := range sortedMapKeys(owners) {
+			if s, ok := owners[did].(string); ok && s != "" {
+				sigs = append(sigs, BlockSignature{
+					Role:      SignatureRoleOwner,
+					DID:       did,
+					Message:   b.BlockHash,
+					Signature: s,
+				})
+			}
+		}
+	}
+
+	return sigs
+}
+
+func decodeSignatureObject(role string, m map[string]interface{}) (BlockSignature, bool) {
+	var d fieldDecoder
+	sig := BlockSignature{
+		Role:      role,
+		DID:       d.string(m, "did", sigDIDKeys),
+		Message:   d.string(m, "hash", sigHashKeys),
+		Signature: d.string(m, "private signature", sigPrivKeys),
+	}
+	if sig.Signature == "" {
+		if d.string(m, "nlc signature", sigNLCKeys) == "" {
+			return sig, false
+		}
+		sig.NLCOnly = true
+	}
+	_ = d.string(m, "sign type", sigSignTypeKeys)
+	return sig, len(d.problems) == 0
+}
+
+// ParsePublicKey accepts a secp256k1 public key as hex (compressed or uncompressed)
+// or as PEM, where the key may be raw or wrapped in a SubjectPublicKeyInfo.
+func ParsePublicKey(s string) (*btcec.PublicKey, error) {
+	s = strings.TrimSpace(s)
+
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		return parsePublicKeyBytes(block.Bytes)
+	}
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("public key is neither PEM nor hex: %w", err)
+	}
+	return parsePublicKeyBytes(raw)
+}
+
+func parsePublicKeyBytes(raw []byte) (*btcec.PublicKey, error) {
+	if key, err := btcec.ParsePubKey(raw); err == nil {
+		return key, nil
+	}
+	// SubjectPublicKeyInfo ends with the encoded point (65 bytes uncompressed, 33 compressed)
+	for _, n := range []int{65, 33} {
+		if len(raw) > n {
+			if key, err := btcec.ParsePubKey(raw[len(raw)-n:]); err == nil {
+				return key, nil
+			}
+		}
+	}
+	return nil, errors.New("unsupported public key encoding")
+}
+
+// VerifySignature checks a hex-encoded ECDSA signature over message the way Rubix signs:
+// the digest is SHA-256 of the message text (for owner signatures, the hex block hash
+// string) and the signature is ASN.1 DER.
+func VerifySignature(pub *btcec.PublicKey, message, signature string) error {
+	sigBytes, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("signature is not hex: %w", err)
+	}
+
+	sig, err := ecdsa.ParseDERSignature(sigBytes)
+	if err != nil {
+		return fmt.Errorf("signature is not DER: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(message))
+	if !sig.Verify(digest[:], pub) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// spkiPrefix is the DER header of a SubjectPublicKeyInfo holding an uncompressed secp256k1 point
+const spkiPrefix = "3056301006072a8648ce3d020106052b8104000a034200"
+
+// testKey derives a fixed secp256k1 key from seed, so the vectors below are reproducible
+func testKey(seed string) *btcec.PrivateKey {
+	d := sha256.Sum256([]byte(seed))
+	key, _ := btcec.PrivKeyFromBytes(d[:])
+	return key
+}
+
+// rubixSign signs message the way a Rubix node does: DER ECDSA over SHA-256 of the text
+func rubixSign(key *btcec.PrivateKey, message string) string {
+	digest := sha256.Sum256([]byte(message))
+	return hex.EncodeToString(ecdsa.Sign(key, digest[:]).Serialize())
+}
+
+func TestVerifySignature(t *testing.T) {
+	signer, other := testKey("signer"), testKey("other")
+	const blockHash = "4d9a0c1c2b2ff5b7e6f5a0d0e2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5"
+	sig := rubixSign(signer, blockHash)
+
+	// the raw hex digest signed directly is not how Rubix signs
+	rawDigest, _ := hex.DecodeString(blockHash)
+	rawSig := hex.EncodeToString(ecdsa.Sign(signer, rawDigest).Serialize())
+
+	tampered := []byte(sig)
+	tampered[len(tampered)-2] ^= 1
+
+	for _, tc := range []struct {
+		name      string
+		pub       *btcec.PublicKey
+		message   string
+		signature string
+		wantErr   error
+	}{
+		{"valid", signer.PubKey(), blockHash, sig, nil},
+		{"valid with whitespace", signer.PubKey(), blockHash, " " + sig + "\n", nil},
+		{"tampered message", signer.PubKey(), "5" + blockHash[1:], sig, ErrSignatureMismatch},
+		{"tampered signature", signer.PubKey(), blockHash, string(tampered), ErrSignatureMismatch},
+		{"wrong key", other.PubKey(), blockHash, sig, ErrSignatureMismatch},
+		{"raw digest signed", signer.PubKey(), blockHash, rawSig, ErrSignatureMismatch},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := VerifySignature(tc.pub, tc.message, tc.signature); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("VerifySignature = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureRejectsMalformed(t *testing.T) {
+	signer := testKey("signer")
+	digest := sha256.Sum256([]byte("msg"))
+	compact, err := ecdsa.SignCompact(signer, digest[:], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := compact[1:] // drop the recovery byte, leaving r||s
+
+	for name, signature := range map[string]string{
+		"not hex":   "zz",
+		"empty":     "",
+		"raw r||s":  hex.EncodeToString(rs),
+		"truncated": rubixSign(signer, "msg")[:20],
+	} {
+		err := VerifySignature(signer.PubKey(), "msg", signature)
+		if err == nil || errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("%s: VerifySignature = %v, want a decoding error", name, err)
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub := testKey("signer").PubKey()
+	uncompressed := pub.SerializeUncompressed()
+	spki, _ := hex.DecodeString(spkiPrefix + hex.EncodeToString(uncompressed))
+
+	for name, encoded := range map[string]string{
+		"compressed hex":   hex.EncodeToString(pub.SerializeCompressed()),
+		"uncompressed hex": hex.EncodeToString(uncompressed),
+		"0x prefix":        "0x" + hex.EncodeToString(uncompressed),
+		"raw PEM":          string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: uncompressed})),
+		"SPKI PEM":         string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki})),
+	} {
+		got, err := ParsePublicKey(encoded)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !got.IsEqual(pub) {
+			t.Errorf("%s: parsed a different key", name)
+		}
+	}
+
+	for _, bad := range []string{"", "not a key", "0x0102"} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey(%q) accepted", bad)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package util
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // Define a map for key translation
@@ -120,6 +123,8 @@ func isInteger(s string) bool {
 	return err == nil
 }
 
+// tcMarshal appends the canonical serialization of m to str. Map keys are written in
+// sorted order so the output is deterministic.
 func tcMarshal(str string, m interface{}) (string, error) {
 	var err error
 	switch mt := m.(type) {
@@ -140,7 +145,8 @@ func tcMarshal(str string, m interface{}) (string, error) {
 	case map[string]interface{}:
 		str = str + "{"
 		c1 := false
-		for k, v := range mt {
+		for _, k := range sortedMapKeys(mt) {
+			v := mt[k]
 			if c1 {
 				str = str + ","
 			}
@@ -160,7 +166,13 @@ func tcMarshal(str string, m interface{}) (string, error) {
 	case map[string]string:
 		str = str + "{"
 		c1 := false
-		for k, v := range mt {
+		keys := make([]string, 0, len(mt))
+		for k := range mt {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := mt[k]
 			if c1 {
 				str = str + ","
 			}
@@ -173,12 +185,17 @@ func tcMarshal(str string, m interface{}) (string, error) {
 	case map[interface{}]interface{}:
 		str = str + "{"
 		c1 := false
+		keyed := make(map[string]interface{}, len(mt))
 		for k, v := range mt {
+			keyed[fmt.Sprint(k)] = v
+		}
+		for _, k := range sortedMapKeys(keyed) {
+			v := keyed[k]
 			if c1 {
 				str = str + ","
 			}
 			c1 = true
-			str = str + "\"" + k.(string) + "\":"
+			str = str + "\"" + k + "\":"
 			str, err = tcMarshal(str, v)
 			if err != nil {
 				return "", err
@@ -227,15 +244,22 @@ func tcMarshal(str string, m interface{}) (string, error) {
 		str = str + fmt.Sprintf("%d", mt)
 	case int:
 		str = str + fmt.Sprintf("%d", mt)
+	case int64:
+		str = str + fmt.Sprintf("%d", mt)
+	case bool:
+		str = str + strconv.FormatBool(mt)
+	case json.Number:
+		if i, err := mt.Int64(); err == nil {
+			str = str + fmt.Sprintf("%d", i)
+		} else if f, err := mt.Float64(); err == nil {
+			str = str + fmt.Sprintf("%.5f", f)
+		} else {
+			return "", fmt.Errorf("invalid number %q", mt)
+		}
 	case float64:
 		// TokenValue (key: "10") is a float value and needs to have a precision of 5
 		// in the output dump file
 		str = str + fmt.Sprintf("%.5f", mt)
-	case interface{}:
-		str, err = tcMarshal(str, mt)
-		if err != nil {
-			return "", err
-		}
 	case nil:
 		str = str + "\"" + "\""
 	default:
@@ -244,9 +268,119 @@ func tcMarshal(str string, m interface{}) (string, error) {
 	return str, nil
 }
 
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func HexToStr(d []byte) string {
 	dst := make([]byte, hex.EncodedLen(len(d)))
 	hex.Encode(dst, d)
 
 	return string(dst)
 }
+
+// reverseKeyMapping maps each key name to the flattened numeric keys it stands for
+var reverseKeyMapping = func() map[string][]string {
+	rev := make(map[string][]string, len(keyMapping))
+	for flat, name := range keyMapping {
+		rev[name] = append(rev[name], flat)
+	}
+	return rev
+}()
+
+// EncodeNumericKeys is the inverse of ApplyKeyMapping(FlattenKeys("", v)): it turns named
+// keys ("TTBlockNumberKey") and flattened keys ("5-6-4") back into the nested numeric keys
+// of the node's wire format. Keys that are neither (token IDs, DIDs) are kept as they are.
+// The input is not modified.
+func EncodeNumericKeys(value interface{}) interface{} {
+	return encodeNumericKeys("", value)
+}
+
+func encodeNumericKeys(path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		encoded := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			key, childPath := numericKey(path, k)
+			encoded[key] = encodeNumericKeys(childPath, nested)
+		}
+		return encoded
+	case []interface{}:
+		encoded := make([]interface{}, len(v))
+		for i, item := range v {
+			encoded[i] = encodeNumericKeys(path, item)
+		}
+		return encoded
+	default:
+		return value
+	}
+}
+
+// numericKey resolves key k found under the flattened path and returns the wire key
+// together with the path its children are flattened under.
+func numericKey(path, k string) (string, string) {
+	for _, flat := range reverseKeyMapping[k] {
+		parent, last := splitFlatKey(flat)
+		if !isInteger(last) {
+			// non-numeric wire keys (epoch) are mapped wherever they appear
+			return last, path
+		}
+		if parent == path {
+			return last, flat
+		}
+	}
+
+	// numeric key already in wire form
+	if isInteger(k) {
+		if path == "" {
+			return k, k
+		}
+		return k, path + "-" + k
+	}
+	// flattened numeric key without a name
+	if rest := strings.TrimPrefix(k, path+"-"); path != "" && rest != k && isInteger(rest) {
+		return rest, k
+	}
+
+	return k, path
+}
+
+func splitFlatKey(flat string) (string, string) {
+	if i := strings.LastIndex(flat, "-"); i >= 0 {
+		return flat[:i], flat[i+1:]
+	}
+	return "", flat
+}
+
+// Block formats accepted by ConvertBlockFormat
+const (
+	BlockFormatRaw   = "raw"
+	BlockFormatNamed = "named"
+)
+
+// ValidBlockFormat reports whether format is empty, raw or named
+func ValidBlockFormat(format string) bool {
+	return format == "" || format == BlockFormatRaw || format == BlockFormatNamed
+}
+
+// ConvertBlockFormat returns a copy of a block in numeric wire format (raw) or with named keys.
+// An empty format returns the block unchanged.
+func ConvertBlockFormat(block map[string]interface{}, format string) (map[string]interface{}, error) {
+	switch format {
+	case "":
+		return block, nil
+	case BlockFormatRaw:
+		return EncodeNumericKeys(block).(map[string]interface{}), nil
+	case BlockFormatNamed:
+		// flatten the numeric form so blocks already carrying names convert too
+		numeric := EncodeNumericKeys(block)
+		return ApplyKeyMapping(FlattenKeys("", numeric)).(map[string]interface{}), nil
+	default:
+		return nil, fmt.Errorf("unknown block format %q (want %s or %s)", format, BlockFormatRaw, BlockFormatNamed)
+	}
+}
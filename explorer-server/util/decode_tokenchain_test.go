@@ -0,0 +1,173 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// tokenKeyedPaths are the flattened paths whose children are keyed by token ID
+var tokenKeyedPaths = map[string]bool{"4-2": true, "5-6": true, "5-10": true}
+
+// nestedBlock builds the wire-format block that flattens to the given key
+func nestedBlock(flat string, value interface{}) map[string]interface{} {
+	segments := strings.Split(flat, "-")
+	root := map[string]interface{}{}
+	current := root
+	path := ""
+
+	for i, seg := range segments {
+		if i == len(segments)-1 {
+			current[seg] = value
+			break
+		}
+		if path == "" {
+			path = seg
+		} else {
+			path += "-" + seg
+		}
+
+		next := map[string]interface{}{}
+		current[seg] = next
+		current = next
+		if tokenKeyedPaths[path] {
+			token := map[string]interface{}{}
+			current["QmToken"] = token
+			current = token
+		}
+	}
+	return root
+}
+
+// roundTrip decodes a JSON block, maps it to named keys and encodes it back
+func roundTrip(t *testing.T, raw string) (numeric, named, back interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(raw), &numeric); err != nil {
+		t.Fatalf("bad fixture: %v", err)
+	}
+	var scratch interface{}
+	json.Unmarshal([]byte(raw), &scratch) // FlattenKeys rewrites arrays in place
+	named = ApplyKeyMapping(FlattenKeys("", scratch))
+	back = EncodeNumericKeys(named)
+	return numeric, named, back
+}
+
+func TestEncodeNumericKeysEveryMappedKey(t *testing.T) {
+	flats := make([]string, 0, len(keyMapping))
+	for flat := range keyMapping {
+		flats = append(flats, flat)
+	}
+	sort.Strings(flats)
+
+	for _, flat := range flats {
+		flat := flat
+		t.Run(flat, func(t *testing.T) {
+			block := nestedBlock(flat, "value")
+			raw, _ := json.Marshal(block)
+
+			numeric, named, back := roundTrip(t, string(raw))
+
+			namedJSON, _ := json.Marshal(named)
+			if !strings.Contains(string(namedJSON), `"`+keyMapping[flat]+`":"value"`) {
+				t.Fatalf("%s not mapped to %s: %s", flat, keyMapping[flat], namedJSON)
+			}
+			if !reflect.DeepEqual(numeric, back) {
+				backJSON, _ := json.Marshal(back)
+				t.Fatalf("round trip changed block:\n got %s\nwant %s", backJSON, raw)
+			}
+		})
+	}
+}
+
+func TestEncodeNumericKeysBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		block string
+	}{
+		{
+			name: "transfer",
+			block: `{"1":0,"2":"02","3":"bafyOwner","5":{"1":"bafySender","2":"bafyReceiver","3":"","4":"txn1",
+				"6":{"QmToken1":{"1":0,"4":"3","5":"prevBlock"},"QmToken2":{"1":0,"4":"7","5":"prevBlock2"}}},
+				"7":[{"1":"nlc","2":"priv","3":"bafyQuorum","4":"hash","5":0}],
+				"8":{"bafyQuorum":[{"token":"QmPledged","token_type":0,"token_block_id":"blk"}]},
+				"12":{"1":"","2":"sig","3":"bafySender","4":"hash","5":0},
+				"98":"blockHash","99":{"bafyOwner":"ownerSig"},"epoch":1728470000}`,
+		},
+		{
+			name: "genesis",
+			block: `{"1":0,"2":"05","3":"bafyOwner","10":0.5,
+				"4":{"1":1,"2":{"QmPart":{"1":1,"2":3,"4":"QmPrev","5":"QmParent","6":["QmGrand1","QmGrand2"],"8":1.5}}},
+				"5":{"4":"txnGen","6":{"QmPart":{"1":1,"4":"0"}}},"98":"genesisHash"}`,
+		},
+		{
+			name: "commit with committed tokens and unmapped keys",
+			block: `{"1":0,"2":"07","3":"bafyOwner",
+				"5":{"4":"txnCommit","10":{"QmCommitted":{"1":0,"2":"unmapped","4":"2","7":"bafyCommitter"}},"11":"unmapped"},
+				"13":"unmapped","98":"commitHash"}`,
+		},
+		{
+			name:  "burnt with child tokens",
+			block: `{"1":0,"2":"08","3":"bafyOwner","5":{"3":"Token burnt at : 2025-10-09 15:31:14","6":{"QmBurnt":{"1":0,"4":"9"}}},"11":["QmChild1","QmChild2"],"98":"burntHash"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numeric, named, back := roundTrip(t, tt.block)
+			if !reflect.DeepEqual(numeric, back) {
+				backJSON, _ := json.Marshal(back)
+				t.Fatalf("round trip changed block:\n got %s\nwant %s", backJSON, tt.block)
+			}
+
+			// named -> numeric -> named is lossless as well
+			again := ApplyKeyMapping(FlattenKeys("", EncodeNumericKeys(named)))
+			if !reflect.DeepEqual(named, again) {
+				t.Fatalf("named round trip changed block")
+			}
+		})
+	}
+}
+
+func TestConvertBlockFormat(t *testing.T) {
+	var numeric map[string]interface{}
+	json.Unmarshal([]byte(`{"2":"02","5":{"6":{"QmToken":{"4":"1"}}},"98":"h","epoch":1}`), &numeric)
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{"unchanged", "", []string{"2", "5", "98", "epoch"}},
+		{"raw", BlockFormatRaw, []string{"2", "5", "98", "epoch"}},
+		{"named", BlockFormatNamed, []string{"TCBlockHashKey", "TCEpoch", "TCTransInfoKey", "TCTransTypeKey"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertBlockFormat(numeric, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys := make([]string, 0, len(got))
+			for k := range got {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Fatalf("keys = %v, want %v", keys, tt.want)
+			}
+
+			// converting the result to raw always yields the original block
+			raw, _ := ConvertBlockFormat(got, BlockFormatRaw)
+			if !reflect.DeepEqual(raw, numeric) {
+				t.Fatalf("raw form differs from original: %v", raw)
+			}
+		})
+	}
+
+	if _, err := ConvertBlockFormat(numeric, "xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -0,0 +1,616 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"explorer-server/decimal"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transaction type codes carried in TCTransTypeKey
+const (
+	TransTypeMint              = "01"
+	TransTypeTransfer          = "02"
+	TransTypeMigrated          = "03"
+	TransTypePledged           = "04"
+	TransTypeGenerated         = "05"
+	TransTypeUnpledged         = "06"
+	TransTypeCommitted         = "07"
+	TransTypeBurnt             = "08"
+	TransTypeDeployed          = "09"
+	TransTypeExecuted          = "10"
+	TransTypeContractCommitted = "11"
+	TransTypePinned            = "12"
+	TransTypeBurntForFT        = "13"
+)
+
+// TokenChainBlock is a token-chain block decoded from either the numeric-key wire format
+// ("2", "5-6-4", ...) or the named-key format ("TCTransTypeKey", "TTBlockNumberKey", ...).
+type TokenChainBlock struct {
+	TokenType         int                    `json:"TCTokenTypeKey"`
+	TransType         string                 `json:"TCTransTypeKey"`
+	TokenOwner        string                 `json:"TCTokenOwnerKey"`
+	GenesisBlock      *GenesisBlock          `json:"TCGenesisBlockKey,omitempty"`
+	TransInfo         TransInfo              `json:"TCTransInfoKey"`
+	SmartContract     interface{}            `json:"TCSmartContractKey,omitempty"`
+	QuorumSignature   interface{}            `json:"TCQuorumSignatureKey,omitempty"`
+	PledgeDetails     PledgeDetails          `json:"TCPledgeDetailsKey,omitempty"`
+	SmartContractData string                 `json:"TCSmartContractDataKey,omitempty"`
+	TokenValue        *decimal.Decimal       `json:"TCTokenValueKey,omitempty"`
+	ChildTokens       []string               `json:"TCChildTokensKey,omitempty"`
+	SenderSignature   interface{}            `json:"TCSenderSignatureKey,omitempty"`
+	BlockHash         string                 `json:"TCBlockHashKey"`
+	Signature         interface{}            `json:"TCSignatureKey,omitempty"`
+	Epoch             *int64                 `json:"TCEpoch,omitempty"`
+	Raw               map[string]interface{} `json:"-"` // the block as received
+}
+
+// GenesisBlock describes how the tokens of a block came into existence
+type GenesisBlock struct {
+	Type string                      `json:"GBTypeKey"`
+	Info map[string]GenesisTokenInfo `json:"GBInfoKey"`
+}
+
+// GenesisTokenInfo is the per-token genesis information
+type GenesisTokenInfo struct {
+	TokenLevel         int         `json:"GITokenLevelKey"`
+	TokenNumber        int         `json:"GITokenNumberKey"`
+	MigratedBlockID    string      `json:"GIMigratedBlkIDKey,omitempty"`
+	PreviousID         string      `json:"GIPreviousIDKey,omitempty"`
+	ParentID           string      `json:"GIParentIDKey,omitempty"`
+	GrandParentIDs     []string    `json:"GIGrandParentIDKey,omitempty"`
+	CommittedTokens    interface{} `json:"GICommitedTokensKey,omitempty"`
+	SmartContractValue float64     `json:"GISmartContractValueKey,omitempty"`
+}
+
+// TransInfo is the transaction section of a block
+type TransInfo struct {
+	SenderDID       string               `json:"TISenderDIDKey,omitempty"`
+	ReceiverDID     string               `json:"TIReceiverDIDKey,omitempty"`
+	Comment         string               `json:"TICommentKey,omitempty"`
+	TID             string               `json:"TITIDKey,omitempty"`
+	Block           interface{}          `json:"TIBlockKey,omitempty"`
+	Tokens          map[string]TokenInfo `json:"TITokensKey,omitempty"`
+	RefID           string               `json:"TIRefIDKey,omitempty"`
+	DeployerDID     string               `json:"TIDeployerDIDKey,omitempty"`
+	ExecutorDID     string               `json:"TIExecutorDIDKey,omitempty"`
+	CommittedTokens map[string]TokenInfo `json:"TICommitedTokensKey,omitempty"`
+}
+
+// TokenInfo is the per-token state recorded in a block
+type TokenInfo struct {
+	TokenType       int    `json:"TTTokenTypeKey"`
+	PledgedToken    string `json:"TTPledgedTokenKey,omitempty"`
+	PledgedDID      string `json:"TTPledgedDIDKey,omitempty"`
+	BlockNumber     string `json:"TTBlockNumberKey"`
+	PreviousBlockID string `json:"TTPreviousBlockIDKey,omitempty"`
+	UnpledgedID     string `json:"TTUnpledgedIDKey,omitempty"`
+	CommittedDID    string `json:"TTCommitedDIDKey,omitempty"`
+}
+
+// PledgeDetails maps each pledging quorum DID to the tokens it pledged
+type PledgeDetails map[string][]PledgeDetail
+
+// PledgeDetail is one token pledged by a quorum for a transaction
+type PledgeDetail struct {
+	Token        string `json:"token"`
+	TokenType    int    `json:"token_type"`
+	TokenBlockID string `json:"token_block_id"`
+}
+
+// ValidationError lists every problem found while validating a block
+type ValidationError struct {
+	BlockHash string
+	Problems  []string
+}
+
+func (e *ValidationError) Error() string {
+	hash := e.BlockHash
+	if hash == "" {
+		hash = "<unknown>"
+	}
+	return fmt.Sprintf("invalid token chain block %s: %s", hash, strings.Join(e.Problems, "; "))
+}
+
+// key aliases per field: numeric wire key, flattened key, named key
+var (
+	tcTokenTypeKeys       = []string{"1", "TCTokenTypeKey"}
+	tcTransTypeKeys       = []string{"2", "TCTransTypeKey"}
+	tcTokenOwnerKeys      = []string{"3", "TCTokenOwnerKey"}
+	tcGenesisBlockKeys    = []string{"4", "TCGenesisBlockKey"}
+	tcTransInfoKeys       = []string{"5", "TCTransInfoKey"}
+	tcSmartContractKeys   = []string{"6", "TCSmartContractKey"}
+	tcQuorumSigKeys       = []string{"7", "TCQuorumSignatureKey"}
+	tcPledgeDetailsKeys   = []string{"8", "TCPledgeDetailsKey"}
+	tcSCDataKeys          = []string{"9", "TCSmartContractDataKey"}
+	tcTokenValueKeys      = []string{"10", "TCTokenValueKey"}
+	tcChildTokensKeys     = []string{"11", "TCChildTokensKey"}
+	tcSenderSigKeys       = []string{"12", "TCSenderSignatureKey"}
+	tcBlockHashKeys       = []string{"98", "TCBlockHashKey"}
+	tcSignatureKeys       = []string{"99", "TCSignatureKey"}
+	tcEpochKeys           = []string{"epoch", "TCEpoch"}
+	gbTypeKeys            = []string{"1", "4-1", "GBTypeKey"}
+	gbInfoKeys            = []string{"2", "4-2", "GBInfoKey"}
+	giTokenLevelKeys      = []string{"1", "4-2-1", "GITokenLevelKey"}
+	giTokenNumberKeys     = []string{"2", "4-2-2", "GITokenNumberKey"}
+	giMigratedBlkIDKeys   = []string{"3", "4-2-3", "GIMigratedBlkIDKey"}
+	giPreviousIDKeys      = []string{"4", "4-2-4", "GIPreviousIDKey"}
+	giParentIDKeys        = []string{"5", "4-2-5", "GIParentIDKey"}
+	giGrandParentIDKeys   = []string{"6", "4-2-6", "GIGrandParentIDKey"}
+	giCommittedTokensKeys = []string{"7", "4-2-7", "GICommitedTokensKey"}
+	giSCValueKeys         = []string{"8", "4-2-8", "GISmartContractValueKey"}
+	tiSenderDIDKeys       = []string{"1", "5-1", "TISenderDIDKey"}
+	tiReceiverDIDKeys     = []string{"2", "5-2", "TIReceiverDIDKey"}
+	tiCommentKeys         = []string{"3", "5-3", "TICommentKey"}
+	tiTIDKeys             = []string{"4", "5-4", "TITIDKey"}
+	tiBlockKeys           = []string{"5", "5-5", "TIBlockKey"}
+	tiTokensKeys          = []string{"6", "5-6", "TITokensKey"}
+	tiRefIDKeys           = []string{"7", "5-7", "TIRefIDKey"}
+	tiDeployerDIDKeys     = []string{"8", "5-8", "TIDeployerDIDKey"}
+	tiExecutorDIDKeys     = []string{"9", "5-9", "TIExecutorDIDKey"}
+	tiCommittedTokensKeys = []string{"10", "5-10", "TICommitedTokensKey"}
+	ttTokenTypeKeys       = []string{"1", "5-6-1", "5-10-1", "TTTokenTypeKey"}
+	ttPledgedTokenKeys    = []string{"2", "5-6-2", "TTPledgedTokenKey"}
+	ttPledgedDIDKeys      = []string{"3", "5-6-3", "TTPledgedDIDKey"}
+	ttBlockNumberKeys     = []string{"4", "5-6-4", "5-10-4", "TTBlockNumberKey"}
+	ttPreviousBlockKeys   = []string{"5", "5-6-5", "5-10-5", "TTPreviousBlockIDKey"}
+	ttUnpledgedIDKeys     = []string{"6", "5-6-6", "5-10-6", "TTUnpledgedIDKey"}
+	ttCommittedDIDKeys    = []string{"7", "5-6-7", "5-10-7", "TTCommitedDIDKey"}
+	pdTokenKeys           = []string{"1", "8-1", "token", "Token"}
+	pdTokenTypeKeys       = []string{"2", "8-2", "token_type", "TokenType"}
+	pdTokenBlockIDKeys    = []string{"3", "8-3", "token_block_id", "TokenBlockID"}
+)
+
+// DecodeTokenChainBlock decodes a block in numeric-key or named-key format.
+// Structural errors (a section of the wrong JSON type) are returned; missing fields are
+// left empty and reported by Validate.
+func DecodeTokenChainBlock(m map[string]interface{}) (*TokenChainBlock, error) {
+	if m == nil {
+		return nil, fmt.Errorf("token chain block is null")
+	}
+
+	d := &fieldDecoder{}
+	b := &TokenChainBlock{Raw: m}
+
+	b.TokenType = d.int(m, "token type", tcTokenTypeKeys)
+	b.TransType = NormalizeTransType(d.string(m, "trans type", tcTransTypeKeys))
+	b.TokenOwner = d.string(m, "token owner", tcTokenOwnerKeys)
+	b.SmartContract = lookup(m, tcSmartContractKeys)
+	b.QuorumSignature = lookup(m, tcQuorumSigKeys)
+	b.SmartContractData = d.string(m, "smart contract data", tcSCDataKeys)
+	b.TokenValue = d.decimalPtr(m, "token value", tcTokenValueKeys)
+	b.ChildTokens = d.strings(m, "child tokens", tcChildTokensKeys)
+	b.SenderSignature = lookup(m, tcSenderSigKeys)
+	b.BlockHash = d.string(m, "block hash", tcBlockHashKeys)
+	b.Signature = lookup(m, tcSignatureKeys)
+	b.Epoch = d.epoch(m, tcEpochKeys)
+
+	if gm := d.object(m, "genesis block", tcGenesisBlockKeys); gm != nil {
+		b.GenesisBlock = d.genesisBlock(gm)
+	}
+	if tm := d.object(m, "trans info", tcTransInfoKeys); tm != nil {
+		b.TransInfo = d.transInfo(tm)
+	}
+	if pm := d.object(m, "pledge details", tcPledgeDetailsKeys); pm != nil {
+		b.PledgeDetails = d.pledgeDetails(pm)
+	}
+
+	if len(d.problems) > 0 {
+		return b, &ValidationError{BlockHash: b.BlockHash, Problems: d.problems}
+	}
+	return b, nil
+}
+
+// DecodePledgeDetails decodes a TCPledgeDetailsKey value on its own, e.g. one stored as JSON
+func DecodePledgeDetails(v interface{}) (PledgeDetails, error) {
+	if v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("pledge details: expected object, got %T", v)
+	}
+
+	d := &fieldDecoder{}
+	details := d.pledgeDetails(m)
+	if len(d.problems) > 0 {
+		return details, &ValidationError{Problems: d.problems}
+	}
+	return details, nil
+}
+
+// ParseTokenChainBlock decodes and validates a block
+func ParseTokenChainBlock(m map[string]interface{}) (*TokenChainBlock, error) {
+	b, err := DecodeTokenChainBlock(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Validate checks that the fields every stored block depends on are present
+func (b *TokenChainBlock) Validate() error {
+	var problems []string
+
+	if b.BlockHash == "" {
+		problems = append(problems, "missing block hash")
+	}
+	if b.TransType == "" {
+		problems = append(problems, "missing transaction type")
+	}
+
+	switch b.TransType {
+	case TransTypeTransfer:
+		if b.TransInfo.TID == "" {
+			problems = append(problems, "transfer block without transaction id")
+		}
+		if len(b.TransInfo.Tokens) == 0 {
+			problems = append(problems, "transfer block without tokens")
+		}
+	case TransTypeBurnt, TransTypeBurntForFT:
+		if len(b.TransInfo.Tokens) == 0 {
+			problems = append(problems, "burnt block without tokens")
+		}
+	case TransTypeDeployed, TransTypeExecuted:
+		if len(b.TransInfo.Tokens) == 0 {
+			problems = append(problems, "smart contract block without contract token")
+		}
+	}
+
+	for tokenID, info := range b.TransInfo.Tokens {
+		if info.BlockNumber != "" {
+			if _, err := strconv.ParseInt(info.BlockNumber, 10, 64); err != nil {
+				problems = append(problems, fmt.Sprintf("token %s has non-numeric block number %q", tokenID, info.BlockNumber))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{BlockHash: b.BlockHash, Problems: problems}
+	}
+	return nil
+}
+
+// NormalizeTransType pads single-digit transaction types ("2" → "02")
+func NormalizeTransType(t string) string {
+	t = strings.TrimSpace(t)
+	if len(t) == 1 && t[0] >= '0' && t[0] <= '9' {
+		return "0" + t
+	}
+	return t
+}
+
+// TokenIDs returns the IDs of the tokens in the block, sorted
+func (b *TokenChainBlock) TokenIDs() []string {
+	ids := make([]string, 0, len(b.TransInfo.Tokens))
+	for id := range b.TransInfo.Tokens {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// FirstToken returns the first token of the block in ID order
+func (b *TokenChainBlock) FirstToken() (string, TokenInfo, bool) {
+	ids := b.TokenIDs()
+	if len(ids) == 0 {
+		return "", TokenInfo{}, false
+	}
+	return ids[0], b.TransInfo.Tokens[ids[0]], true
+}
+
+// EpochTime returns the block epoch as a time; ok is false when the block carries none
+// (a zero epoch means the node did not stamp the block)
+func (b *TokenChainBlock) EpochTime() (time.Time, bool) {
+	if b.Epoch == nil || *b.Epoch <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(*b.Epoch, 0), true
+}
+
+// Height returns the block number of the token, or 0 when it is missing
+func (t TokenInfo) Height() int64 {
+	h, _ := strconv.ParseInt(t.BlockNumber, 10, 64)
+	return h
+}
+
+// DecodeNodeJSON decodes fullnode JSON keeping numbers as json.Number, so token values
+// and amounts are parsed from their text instead of passing through float64
+func DecodeNodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// lookup returns the first value present under any of the keys
+func lookup(m map[string]interface{}, keys []string) interface{} {
+	for _, k := range keys {
+		if v, ok := m[k]; ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// fieldDecoder converts loosely typed JSON values and collects type problems
+type fieldDecoder struct {
+	problems []string
+}
+
+func (d *fieldDecoder) fail(field string, v interface{}, want string) {
+	d.problems = append(d.problems, fmt.Sprintf("%s: expected %s, got %T", field, want, v))
+}
+
+func (d *fieldDecoder) string(m map[string]interface{}, field string, keys []string) string {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		d.fail(field, v, "string")
+		return ""
+	}
+}
+
+func (d *fieldDecoder) int(m map[string]interface{}, field string, keys []string) int {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return 0
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			d.fail(field, v, "integer")
+		}
+		return int(i)
+	case float64:
+		return int(v)
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			d.fail(field, v, "integer")
+		}
+		return i
+	default:
+		d.fail(field, v, "integer")
+		return 0
+	}
+}
+
+func (d *fieldDecoder) floatPtr(m map[string]interface{}, field string, keys []string) *float64 {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			d.fail(field, v, "number")
+			return nil
+		}
+		return &f
+	case float64:
+		return &v
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			d.fail(field, v, "number")
+			return nil
+		}
+		return &f
+	default:
+		d.fail(field, v, "number")
+		return nil
+	}
+}
+
+func (d *fieldDecoder) decimalPtr(m map[string]interface{}, field string, keys []string) *decimal.Decimal {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return nil
+	case json.Number:
+		amount, err := decimal.Parse(v.String())
+		if err != nil {
+			d.fail(field, v, "number")
+			return nil
+		}
+		return &amount
+	case float64:
+		return decimal.Ptr(decimal.FromFloat(v))
+	case string:
+		amount, err := decimal.Parse(v)
+		if err != nil {
+			d.fail(field, v, "number")
+			return nil
+		}
+		return &amount
+	default:
+		d.fail(field, v, "number")
+		return nil
+	}
+}
+
+func (d *fieldDecoder) strings(m map[string]interface{}, field string, keys []string) []string {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				d.fail(field, item, "string element")
+				continue
+			}
+			out = append(out, s)
+		}
+		return out
+	default:
+		d.fail(field, v, "array")
+		return nil
+	}
+}
+
+func (d *fieldDecoder) object(m map[string]interface{}, field string, keys []string) map[string]interface{} {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return v
+	default:
+		d.fail(field, v, "object")
+		return nil
+	}
+}
+
+// epoch accepts unix seconds as a number or string, or an RFC3339 timestamp
+func (d *fieldDecoder) epoch(m map[string]interface{}, keys []string) *int64 {
+	switch v := lookup(m, keys).(type) {
+	case nil:
+		return nil
+	case json.Number:
+		if e, err := v.Int64(); err == nil {
+			return &e
+		}
+		f, err := v.Float64()
+		if err != nil {
+			d.fail("epoch", v, "unix seconds or RFC3339 time")
+			return nil
+		}
+		e := int64(f)
+		return &e
+	case float64:
+		e := int64(v)
+		return &e
+	case string:
+		if e, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return &e
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			e := t.Unix()
+			return &e
+		}
+		d.fail("epoch", v, "unix seconds or RFC3339 time")
+		return nil
+	default:
+		d.fail("epoch", v, "unix seconds or RFC3339 time")
+		return nil
+	}
+}
+
+func (d *fieldDecoder) genesisBlock(m map[string]interface{}) *GenesisBlock {
+	gb := &GenesisBlock{Type: d.string(m, "genesis type", gbTypeKeys)}
+
+	info := d.object(m, "genesis info", gbInfoKeys)
+	if len(info) > 0 {
+		gb.Info = make(map[string]GenesisTokenInfo, len(info))
+	}
+	for tokenID, raw := range info {
+		im, ok := raw.(map[string]interface{})
+		if !ok {
+			d.fail("genesis info of "+tokenID, raw, "object")
+			continue
+		}
+		gb.Info[tokenID] = GenesisTokenInfo{
+			TokenLevel:         d.int(im, "token level", giTokenLevelKeys),
+			TokenNumber:        d.int(im, "token number", giTokenNumberKeys),
+			MigratedBlockID:    d.string(im, "migrated block id", giMigratedBlkIDKeys),
+			PreviousID:         d.string(im, "previous id", giPreviousIDKeys),
+			ParentID:           d.string(im, "parent id", giParentIDKeys),
+			GrandParentIDs:     d.strings(im, "grand parent ids", giGrandParentIDKeys),
+			CommittedTokens:    lookup(im, giCommittedTokensKeys),
+			SmartContractValue: derefFloat(d.floatPtr(im, "smart contract value", giSCValueKeys)),
+		}
+	}
+	return gb
+}
+
+func (d *fieldDecoder) transInfo(m map[string]interface{}) TransInfo {
+	return TransInfo{
+		SenderDID:       d.string(m, "sender did", tiSenderDIDKeys),
+		ReceiverDID:     d.string(m, "receiver did", tiReceiverDIDKeys),
+		Comment:         d.string(m, "comment", tiCommentKeys),
+		TID:             d.string(m, "transaction id", tiTIDKeys),
+		Block:           lookup(m, tiBlockKeys),
+		Tokens:          d.tokenInfos(d.object(m, "tokens", tiTokensKeys), "tokens"),
+		RefID:           d.string(m, "ref id", tiRefIDKeys),
+		DeployerDID:     d.string(m, "deployer did", tiDeployerDIDKeys),
+		ExecutorDID:     d.string(m, "executor did", tiExecutorDIDKeys),
+		CommittedTokens: d.tokenInfos(d.object(m, "committed tokens", tiCommittedTokensKeys), "committed tokens"),
+	}
+}
+
+func (d *fieldDecoder) tokenInfos(m map[string]interface{}, field string) map[string]TokenInfo {
+	if len(m) == 0 {
+		return nil
+	}
+
+	tokens := make(map[string]TokenInfo, len(m))
+	for tokenID, raw := range m {
+		tm, ok := raw.(map[string]interface{})
+		if !ok {
+			d.fail(field+" "+tokenID, raw, "object")
+			continue
+		}
+		tokens[tokenID] = TokenInfo{
+			TokenType:       d.int(tm, "token type of "+tokenID, ttTokenTypeKeys),
+			PledgedToken:    d.string(tm, "pledged token of "+tokenID, ttPledgedTokenKeys),
+			PledgedDID:      d.string(tm, "pledged did of "+tokenID, ttPledgedDIDKeys),
+			BlockNumber:     d.string(tm, "block number of "+tokenID, ttBlockNumberKeys),
+			PreviousBlockID: d.string(tm, "previous block id of "+tokenID, ttPreviousBlockKeys),
+			UnpledgedID:     d.string(tm, "unpledged id of "+tokenID, ttUnpledgedIDKeys),
+			CommittedDID:    d.string(tm, "committed did of "+tokenID, ttCommittedDIDKeys),
+		}
+	}
+	return tokens
+}
+
+func (d *fieldDecoder) pledgeDetails(m map[string]interface{}) PledgeDetails {
+	details := make(PledgeDetails, len(m))
+	for did, raw := range m {
+		entries, ok := raw.([]interface{})
+		if !ok {
+			d.fail("pledge details of "+did, raw, "array")
+			continue
+		}
+		for _, e := range entries {
+			em, ok := e.(map[string]interface{})
+			if !ok {
+				d.fail("pledge detail of "+did, e, "object")
+				continue
+			}
+			details[did] = append(details[did], PledgeDetail{
+				Token:        d.string(em, "pledged token", pdTokenKeys),
+				TokenType:    d.int(em, "pledged token type", pdTokenTypeKeys),
+				TokenBlockID: d.string(em, "pledged token block id", pdTokenBlockIDKeys),
+			})
+		}
+	}
+	return details
+}
+
+func derefFloat(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
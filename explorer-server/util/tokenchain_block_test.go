@@ -0,0 +1,66 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// exactBlockJSON carries a token value float64 cannot hold and whole numbers elsewhere
+const exactBlockJSON = `{
+	"1": 0,
+	"2": "02",
+	"3": "did-receiver",
+	"5": {"4": "txn-1", "6": {"QmToken1": {"1": 0, "4": "3", "5": "prev-hash"}}},
+	"10": 92233720368.12345678,
+	"98": "hash-1",
+	"epoch": 1700000000
+}`
+
+func TestDecodeNodeJSONKeepsExactAmounts(t *testing.T) {
+	var raw map[string]interface{}
+	if err := DecodeNodeJSON([]byte(exactBlockJSON), &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := DecodeTokenChainBlock(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.TokenValue.String(); got != "92233720368.12345678" {
+		t.Errorf("token value = %s, want 92233720368.12345678", got)
+	}
+	if b.TokenType != 0 || b.Epoch == nil || *b.Epoch != 1700000000 {
+		t.Errorf("token type %d, epoch %v: whole numbers were not decoded", b.TokenType, b.Epoch)
+	}
+	if _, info, _ := b.FirstToken(); info.Height() != 3 {
+		t.Errorf("block height = %d, want 3", info.Height())
+	}
+
+	if err := DecodeNodeJSON([]byte(`{"10": 1} {}`), &raw); err == nil {
+		t.Error("trailing data was accepted")
+	}
+}
+
+// TestBlockHashIgnoresNumberDecoding checks that a block hashes the same whether its
+// numbers were decoded as float64 or as json.Number
+func TestBlockHashIgnoresNumberDecoding(t *testing.T) {
+	for _, value := range []string{"1", "1.5", "0.001"} {
+		block := `{"1": 0, "2": "02", "5": {"4": "txn-1"}, "10": ` + value + `, "98": "h", "epoch": 1700000000}`
+
+		var floats, numbers map[string]interface{}
+		if err := json.Unmarshal([]byte(block), &floats); err != nil {
+			t.Fatal(err)
+		}
+		if err := DecodeNodeJSON([]byte(block), &numbers); err != nil {
+			t.Fatal(err)
+		}
+
+		want, err := ComputeBlockHash(floats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := ComputeBlockHash(numbers); err != nil || got != want {
+			t.Errorf("value %s: hash %s (%v), want %s", value, got, err, want)
+		}
+	}
+}